@@ -0,0 +1,12 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graphql
+
+import "github.com/owasp-amass/engine/types"
+
+// assetTypeFromString converts the "type" query argument into a
+// types.AssetType, treating an empty string as "no filter".
+func assetTypeFromString(s string) types.AssetType {
+	return types.AssetType(s)
+}
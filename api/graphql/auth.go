@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// APIKeyStore validates bearer tokens presented by callers and maps each
+// one to a stable caller identity used for per-session authorization.
+type APIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]string // API key -> caller identity
+}
+
+// NewAPIKeyStore returns an APIKeyStore seeded with the given key -> caller
+// identity pairs.
+func NewAPIKeyStore(keys map[string]string) *APIKeyStore {
+	cp := make(map[string]string, len(keys))
+	for k, v := range keys {
+		cp[k] = v
+	}
+	return &APIKeyStore{keys: cp}
+}
+
+// Authenticate returns the caller identity bound to apiKey, or false if the
+// key is unrecognized.
+func (s *APIKeyStore) Authenticate(apiKey string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	caller, ok := s.keys[apiKey]
+	return caller, ok
+}
+
+// requireAuth wraps handler, rejecting requests that don't present a valid
+// "Authorization: Bearer <api key>" header, and otherwise passing the
+// resolved caller identity through to handler as its caller argument.
+func requireAuth(keys *APIKeyStore, handler func(w http.ResponseWriter, r *http.Request, caller string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		const prefix = "Bearer "
+		if !strings.HasPrefix(auth, prefix) {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		caller, ok := keys.Authenticate(strings.TrimPrefix(auth, prefix))
+		if !ok {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r, caller)
+	}
+}
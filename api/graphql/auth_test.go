@@ -0,0 +1,38 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAuthRejectsMissingAndInvalidKeys(t *testing.T) {
+	keys := NewAPIKeyStore(map[string]string{"good-key": "caller-a"})
+	handler := requireAuth(keys, func(w http.ResponseWriter, r *http.Request, caller string) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"bad key", "Bearer wrong-key", http.StatusUnauthorized},
+		{"good key", "Bearer good-key", http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/createSession", nil)
+		if c.header != "" {
+			req.Header.Set("Authorization", c.header)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != c.want {
+			t.Errorf("%s: got status %d, want %d", c.name, rec.Code, c.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package graphql exposes the engine's session lifecycle and results over a
+// small GraphQL-style API: clients create a session, submit assets against
+// it, and query its stats, results, and log stream.
+package graphql
+
+import (
+	"errors"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/engine/sessions"
+	"github.com/owasp-amass/engine/types"
+)
+
+// ErrUnknownSession is returned by resolvers when a caller references a
+// session token that was never created or has already been removed.
+var ErrUnknownSession = errors.New("graphql: unknown session token")
+
+// ErrNotAuthorized is returned when a caller references a session it does
+// not own.
+var ErrNotAuthorized = errors.New("graphql: caller is not authorized for this session")
+
+// Resolver implements the root Query and Mutation fields of the schema. It
+// owns the registry of live sessions.
+type Resolver struct {
+	manager *sessions.Manager
+}
+
+// NewResolver returns a Resolver with no active sessions and no limit on
+// how many can be created concurrently.
+func NewResolver() *Resolver {
+	return NewResolverWithSessionLimit(0)
+}
+
+// NewResolverWithSessionLimit returns a Resolver that rejects CreateSession
+// calls once max sessions are live at once, so a caller can't drive the
+// engine into OOM by creating sessions faster than they finish. A max of
+// zero or less means unlimited, matching NewResolver.
+func NewResolverWithSessionLimit(max int) *Resolver {
+	return &Resolver{manager: sessions.NewManager(max)}
+}
+
+// CreateSession implements the createSession mutation, allocating a new
+// session owned by caller and returning its token. It fails with
+// sessions.ErrTooManySessions once the resolver's concurrent-session limit
+// is reached.
+func (r *Resolver) CreateSession(caller string) (string, error) {
+	s, err := r.manager.CreateSession(caller)
+	if err != nil {
+		return "", err
+	}
+	return s.Token, nil
+}
+
+// session looks up the session for token and enforces that caller is the
+// session's owner, so one caller's API key can never be used to read or
+// mutate another caller's session.
+func (r *Resolver) session(token, caller string) (*sessions.Session, error) {
+	s, ok := r.manager.Get(token)
+	if !ok {
+		return nil, ErrUnknownSession
+	}
+	if s.Owner != caller {
+		return nil, ErrNotAuthorized
+	}
+	return s, nil
+}
+
+// SessionStats implements the sessionStats query.
+func (r *Resolver) SessionStats(token, caller string) (sessions.Stats, error) {
+	s, err := r.session(token, caller)
+	if err != nil {
+		return sessions.Stats{}, err
+	}
+	return s.StatsSnapshot(), nil
+}
+
+// SessionSummary implements the sessionSummary query, returning the
+// concise end-of-session-style report (asset counts, per-plugin
+// contribution, duration, errors) for token, whether or not the session
+// has actually ended yet.
+func (r *Resolver) SessionSummary(token, caller string) (sessions.Summary, error) {
+	s, err := r.session(token, caller)
+	if err != nil {
+		return sessions.Summary{}, err
+	}
+	return s.Summary(), nil
+}
+
+// AssetPage is the payload returned by the sessionAssets query: a page of
+// results plus a cursor for fetching the next page.
+type AssetPage struct {
+	Assets    []types.Asset
+	Relations []types.Relation
+	NextAfter string
+	HasMore   bool
+}
+
+// SessionAssets implements the sessionAssets(sessionToken, type, after)
+// query. Results are paginated by asset key: after is the key of the last
+// asset the caller already has, and the page contains up to pageSize
+// assets whose key sorts after it. Reuses Session.Results, so it stays in
+// sync with whatever the session's graph currently holds.
+func (r *Resolver) SessionAssets(token, caller string, assetType types.AssetType, after string, pageSize int) (*AssetPage, error) {
+	s, err := r.session(token, caller)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	assets, relations := s.Results(assetType)
+	sortAssetsByKey(assets)
+
+	start := 0
+	if after != "" {
+		for i, a := range assets {
+			if a.Key() > after {
+				start = i
+				break
+			}
+			start = i + 1
+		}
+	}
+
+	end := start + pageSize
+	hasMore := end < len(assets)
+	if end > len(assets) {
+		end = len(assets)
+	}
+
+	page := assets[start:end]
+	next := ""
+	if len(page) > 0 {
+		next = page[len(page)-1].Key()
+	}
+
+	return &AssetPage{
+		Assets:    page,
+		Relations: relations,
+		NextAfter: next,
+		HasMore:   hasMore,
+	}, nil
+}
+
+// SessionOAMGraph implements the sessionOAMGraph query, exporting the
+// session's entire graph in the open-asset-model JSON format so downstream
+// OAM tooling can ingest it directly instead of going through the DB or
+// paginated sessionAssets query.
+func (r *Resolver) SessionOAMGraph(token, caller string) (graph.OAMGraph, error) {
+	s, err := r.session(token, caller)
+	if err != nil {
+		return graph.OAMGraph{}, err
+	}
+	return s.Graph().ExportOAM(), nil
+}
+
+// CancelEvent implements the cancelEvent mutation: cancels the repeating
+// event identified by eventID on the caller's session, reporting whether
+// it existed. It's scoped to the caller's own session the same way every
+// other mutation is, so one caller can't cancel another's event by
+// guessing its ID.
+func (r *Resolver) CancelEvent(token, caller, eventID string) (bool, error) {
+	s, err := r.session(token, caller)
+	if err != nil {
+		return false, err
+	}
+	return s.CancelEvent(eventID), nil
+}
+
+func sortAssetsByKey(assets []types.Asset) {
+	for i := 1; i < len(assets); i++ {
+		for j := i; j > 0 && assets[j-1].Key() > assets[j].Key(); j-- {
+			assets[j-1], assets[j] = assets[j], assets[j-1]
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/sessions"
+	"github.com/owasp-amass/engine/types"
+)
+
+const testCaller = "caller-a"
+
+// TestSessionAssetsReturnsPopulatedResults ensures sessionAssets returns
+// the assets and relations a session has already discovered.
+func TestSessionAssetsReturnsPopulatedResults(t *testing.T) {
+	r := NewResolver()
+	token, err := r.CreateSession(testCaller)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	s, err := r.session(token, testCaller)
+	if err != nil {
+		t.Fatalf("session lookup failed: %v", err)
+	}
+	s.Graph().UpsertAsset(types.FQDN{Name: "owasp.org"})
+	s.Graph().UpsertAsset(types.IPAddress{Address: "1.2.3.4", Type: "IPv4"})
+
+	page, err := r.SessionAssets(token, testCaller, "", "", 0)
+	if err != nil {
+		t.Fatalf("SessionAssets failed: %v", err)
+	}
+	if len(page.Assets) != 2 {
+		t.Fatalf("expected 2 assets, got %d", len(page.Assets))
+	}
+	if page.HasMore {
+		t.Fatalf("did not expect additional pages")
+	}
+}
+
+// TestSessionAssetsUnknownSession ensures an unrecognized token surfaces
+// ErrUnknownSession instead of a nil-pointer panic.
+func TestSessionAssetsUnknownSession(t *testing.T) {
+	r := NewResolver()
+	if _, err := r.SessionAssets("does-not-exist", testCaller, "", "", 0); err != ErrUnknownSession {
+		t.Fatalf("expected ErrUnknownSession, got %v", err)
+	}
+}
+
+// TestSessionAssetsCrossCallerDenied ensures a caller cannot read another
+// caller's session even with a token it happens to know.
+func TestSessionAssetsCrossCallerDenied(t *testing.T) {
+	r := NewResolver()
+	token, err := r.CreateSession("owner")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	if _, err := r.SessionAssets(token, "someone-else", "", "", 0); err != ErrNotAuthorized {
+		t.Fatalf("expected ErrNotAuthorized, got %v", err)
+	}
+	if _, err := r.SessionStats(token, "someone-else"); err != ErrNotAuthorized {
+		t.Fatalf("expected ErrNotAuthorized from SessionStats, got %v", err)
+	}
+}
+
+// TestSessionSummaryReflectsGraphAndStats ensures sessionSummary reports
+// the session's current asset counts and per-plugin contribution, and
+// enforces the same ownership check as SessionStats.
+func TestSessionSummaryReflectsGraphAndStats(t *testing.T) {
+	r := NewResolver()
+	token, err := r.CreateSession(testCaller)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	s, err := r.session(token, testCaller)
+	if err != nil {
+		t.Fatalf("session lookup failed: %v", err)
+	}
+	s.Graph().UpsertAsset(types.FQDN{Name: "owasp.org"})
+	s.RecordPluginResult("dns", 1, false)
+
+	summary, err := r.SessionSummary(token, testCaller)
+	if err != nil {
+		t.Fatalf("SessionSummary failed: %v", err)
+	}
+	if summary.AssetCounts["FQDN"] != 1 {
+		t.Fatalf("expected 1 FQDN in summary asset counts, got %d", summary.AssetCounts["FQDN"])
+	}
+	if summary.Plugins["dns"].AssetsDiscovered != 1 {
+		t.Fatalf("expected dns plugin to have discovered 1 asset, got %+v", summary.Plugins["dns"])
+	}
+
+	if _, err := r.SessionSummary(token, "someone-else"); err != ErrNotAuthorized {
+		t.Fatalf("expected ErrNotAuthorized from SessionSummary, got %v", err)
+	}
+}
+
+// TestSessionOAMGraphExportsAssetsAndRelations ensures sessionOAMGraph
+// reflects whatever the session's graph currently holds.
+func TestSessionOAMGraphExportsAssetsAndRelations(t *testing.T) {
+	r := NewResolver()
+	token, err := r.CreateSession(testCaller)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	s, err := r.session(token, testCaller)
+	if err != nil {
+		t.Fatalf("session lookup failed: %v", err)
+	}
+	apex := types.FQDN{Name: "owasp.org"}
+	s.Graph().UpsertAsset(apex)
+
+	oam, err := r.SessionOAMGraph(token, testCaller)
+	if err != nil {
+		t.Fatalf("SessionOAMGraph failed: %v", err)
+	}
+	if len(oam.Nodes) != 1 || oam.Nodes[0].ID != apex.Key() {
+		t.Fatalf("expected the export to contain the session's one asset, got %+v", oam.Nodes)
+	}
+}
+
+// TestCancelEventStopsRepeatsThroughResolver ensures cancelEvent reaches
+// down into the session's scheduler, so a repeating event scheduled on a
+// session actually stops recurring once canceled through the API layer.
+func TestCancelEventStopsRepeatsThroughResolver(t *testing.T) {
+	r := NewResolver()
+	token, err := r.CreateSession(testCaller)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	s, err := r.session(token, testCaller)
+	if err != nil {
+		t.Fatalf("session lookup failed: %v", err)
+	}
+
+	var ticks int32
+	id := s.ScheduleRepeating(5*time.Millisecond, func() {
+		atomic.AddInt32(&ticks, 1)
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	ok, err := r.CancelEvent(token, testCaller, id)
+	if err != nil {
+		t.Fatalf("CancelEvent failed: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected CancelEvent to report the event existed")
+	}
+
+	seenAtCancel := atomic.LoadInt32(&ticks)
+	time.Sleep(30 * time.Millisecond)
+	if got := atomic.LoadInt32(&ticks); got != seenAtCancel {
+		t.Fatalf("expected no further ticks after CancelEvent, went from %d to %d", seenAtCancel, got)
+	}
+}
+
+// TestCreateSessionRejectsPastConfiguredLimit ensures a resolver configured
+// with a concurrent-session limit rejects CreateSession once it's reached.
+func TestCreateSessionRejectsPastConfiguredLimit(t *testing.T) {
+	r := NewResolverWithSessionLimit(1)
+
+	if _, err := r.CreateSession(testCaller); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := r.CreateSession(testCaller); err != sessions.ErrTooManySessions {
+		t.Fatalf("expected ErrTooManySessions at the limit, got %v", err)
+	}
+}
+
+// TestCancelEventUnknownIDReportsFalse ensures an unrecognized event ID is
+// reported as not found rather than erroring.
+func TestCancelEventUnknownIDReportsFalse(t *testing.T) {
+	r := NewResolver()
+	token, err := r.CreateSession(testCaller)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	ok, err := r.CancelEvent(token, testCaller, "does-not-exist")
+	if err != nil {
+		t.Fatalf("CancelEvent failed: %v", err)
+	}
+	if ok {
+		t.Fatal("expected CancelEvent to report false for an unknown ID")
+	}
+}
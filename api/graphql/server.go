@@ -0,0 +1,151 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/owasp-amass/engine/tlscerts"
+)
+
+// TLSConfig selects the certificate material and client-auth policy for
+// the API's HTTP and websocket listeners.
+type TLSConfig struct {
+	// CertFile/KeyFile are the server's certificate and key. If either is
+	// empty, a certificate is generated with
+	// tlscerts.GenerateSelfSignedWithOptions(SelfSigned) instead.
+	CertFile, KeyFile string
+	// ClientCAFile, when set, enables mutual TLS: only clients presenting
+	// a certificate signed by this CA are accepted.
+	ClientCAFile string
+	// SelfSigned configures the generated certificate's SANs when
+	// CertFile/KeyFile are not provided. Ignored otherwise.
+	SelfSigned tlscerts.Options
+}
+
+// Server serves the engine's GraphQL-style HTTP API.
+type Server struct {
+	resolver *Resolver
+	http     *http.Server
+}
+
+// NewServer builds a Server bound to addr, backed by resolver. Every
+// endpoint requires a valid "Authorization: Bearer <api key>" header;
+// keys authenticates them and binds each request to a caller identity that
+// the resolver uses to enforce per-session authorization.
+func NewServer(addr string, resolver *Resolver, keys *APIKeyStore) *Server {
+	s := &Server{resolver: resolver}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/createSession", requireAuth(keys, s.handleCreateSession))
+	mux.HandleFunc("/sessionStats", requireAuth(keys, s.handleSessionStats))
+	mux.HandleFunc("/sessionAssets", requireAuth(keys, s.handleSessionAssets))
+	s.http = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// ListenAndServe starts the HTTP server, blocking until it stops.
+func (s *Server) ListenAndServe() error {
+	return s.http.ListenAndServe()
+}
+
+// ListenAndServeTLS starts the HTTP server over TLS using cfg. When
+// cfg.ClientCAFile is set, the server requires and verifies a client
+// certificate signed by that CA on every connection (mutual TLS) before
+// any request reaches the mux, so unauthenticated connections never reach
+// the resolvers.
+func (s *Server) ListenAndServeTLS(cfg TLSConfig) error {
+	tlsCfg, err := buildTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+	s.http.TLSConfig = tlsCfg
+	return s.http.ListenAndServeTLS("", "")
+}
+
+func buildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		cert, err = tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	} else {
+		cert, err = tlscerts.GenerateSelfSignedWithOptions(cfg.SelfSigned)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("graphql: failed to load server certificate: %w", err)
+	}
+
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if cfg.ClientCAFile == "" {
+		return tlsCfg, nil
+	}
+
+	caPEM, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("graphql: no certificates found in client CA file %q", cfg.ClientCAFile)
+	}
+	tlsCfg.ClientCAs = pool
+	tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsCfg, nil
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request, caller string) {
+	token, err := s.resolver.CreateSession(caller)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]string{"sessionToken": token})
+}
+
+func (s *Server) handleSessionStats(w http.ResponseWriter, r *http.Request, caller string) {
+	token := r.URL.Query().Get("sessionToken")
+	stats, err := s.resolver.SessionStats(token, caller)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// handleSessionAssets serves the sessionAssets(sessionToken, type, after)
+// query so clients can pull the assets and relations a session has
+// discovered so far without reading the DB directly.
+func (s *Server) handleSessionAssets(w http.ResponseWriter, r *http.Request, caller string) {
+	q := r.URL.Query()
+	token := q.Get("sessionToken")
+	assetType := q.Get("type")
+	after := q.Get("after")
+
+	page, err := s.resolver.SessionAssets(token, caller, assetTypeFromString(assetType), after, 0)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	writeJSON(w, page)
+}
+
+// writeAuthError maps resolver errors to the appropriate HTTP status: an
+// unknown session is a 404, but a session that exists and belongs to
+// someone else is a 403, not a 404 that would leak the token's validity.
+func writeAuthError(w http.ResponseWriter, err error) {
+	if err == ErrNotAuthorized {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusNotFound)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
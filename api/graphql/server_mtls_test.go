@@ -0,0 +1,137 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graphql
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func genCert(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key gen failed: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if isCA {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("cert creation failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("cert parse failed: %v", err)
+	}
+	return cert, key, der
+}
+
+func certPEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func keyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("key marshal failed: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// TestMutualTLSAcceptsValidAndRejectsInvalidClients spins up the API server
+// requiring client certificates signed by a specific CA, then confirms a
+// client presenting a cert from that CA is accepted while a client
+// presenting a self-signed cert is rejected.
+func TestMutualTLSAcceptsValidAndRejectsInvalidClients(t *testing.T) {
+	caCert, caKey, caDER := genCert(t, nil, nil, true)
+
+	_, serverKey, serverDER := genCert(t, caCert, caKey, false)
+	serverTLSCert := tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{serverTLSCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+
+	keys := NewAPIKeyStore(map[string]string{"test-key": testCaller})
+	srv := NewServer("127.0.0.1:0", NewResolver(), keys)
+	ts := httptest.NewUnstartedServer(srv.http.Handler)
+	ts.TLS = tlsCfg
+	ts.StartTLS()
+	defer ts.Close()
+
+	// Valid client: cert signed by the trusted CA.
+	validCert, validKey, validDER := genCert(t, caCert, caKey, false)
+	validTLSCert := tls.Certificate{Certificate: [][]byte{validDER}, PrivateKey: validKey}
+	_ = validCert
+
+	validClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      certPoolFromDER(caDER),
+		Certificates: []tls.Certificate{validTLSCert},
+	}}}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/createSession", nil)
+	req.Header.Set("Authorization", "Bearer test-key")
+	resp, err := validClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected valid client cert to be accepted, got error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for valid client cert + API key, got %d", resp.StatusCode)
+	}
+
+	// Invalid client: a self-signed cert not issued by the trusted CA.
+	invalidCert, invalidKey, invalidDER := genCert(t, nil, nil, true)
+	_ = invalidCert
+	invalidTLSCert := tls.Certificate{Certificate: [][]byte{invalidDER}, PrivateKey: invalidKey}
+
+	invalidClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      certPoolFromDER(caDER),
+		Certificates: []tls.Certificate{invalidTLSCert},
+	}}}
+	if _, err := invalidClient.Get(ts.URL + "/createSession"); err == nil {
+		t.Fatalf("expected untrusted client cert to be rejected")
+	}
+}
+
+func certPoolFromDER(der []byte) *x509.CertPool {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return x509.NewCertPool()
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return pool
+}
@@ -0,0 +1,90 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package client provides a Go client for the engine's GraphQL-style API,
+// used by the CLI and by external tools that drive scans programmatically.
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/owasp-amass/engine/api/graphql"
+	"github.com/owasp-amass/engine/types"
+)
+
+// Client talks to a running engine API server over HTTP, authenticating
+// every request with an API key.
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// New returns a Client targeting the API server at baseURL, authenticating
+// as apiKey.
+func New(baseURL, apiKey string) *Client {
+	return &Client{baseURL: baseURL, apiKey: apiKey, http: http.DefaultClient}
+}
+
+func (c *Client) newRequest(method, url string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	return req, nil
+}
+
+// CreateSession calls the createSession mutation and returns the new
+// session's token.
+func (c *Client) CreateSession() (string, error) {
+	req, err := c.newRequest(http.MethodGet, c.baseURL+"/createSession")
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		SessionToken string `json:"sessionToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	return out.SessionToken, nil
+}
+
+// SessionAssets calls the sessionAssets query, returning one page of the
+// session's discovered assets and relations. Pass the NextAfter cursor
+// from a prior call back in as after to fetch subsequent pages.
+func (c *Client) SessionAssets(sessionToken string, assetType types.AssetType, after string) (*graphql.AssetPage, error) {
+	q := url.Values{}
+	q.Set("sessionToken", sessionToken)
+	q.Set("type", string(assetType))
+	q.Set("after", after)
+
+	req, err := c.newRequest(http.MethodGet, c.baseURL+"/sessionAssets?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("client: sessionAssets returned status %d", resp.StatusCode)
+	}
+
+	var page graphql.AssetPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
@@ -0,0 +1,97 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package format renders a stream of discovered assets for the CLI client,
+// decoupled from whatever's driving the progress display, so the client
+// can print results as they arrive instead of only a final summary.
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// Formatter renders a stream of assets to w, one at a time as they arrive
+// on stream, until stream is closed.
+type Formatter interface {
+	// Write consumes stream until it's closed. If writing to w fails,
+	// Write still drains the remainder of stream before returning the
+	// first error, so a caller ranging over the same source channel from
+	// elsewhere never blocks on a stuck send.
+	Write(w io.Writer, stream <-chan types.Asset) error
+}
+
+// ByName resolves a formatter by its flag value ("text", "json", "csv").
+func ByName(name string) (Formatter, bool) {
+	switch name {
+	case "text":
+		return Text{}, true
+	case "json":
+		return JSON{}, true
+	case "csv":
+		return CSV{}, true
+	default:
+		return nil, false
+	}
+}
+
+// Text renders one "type\tkey" line per asset.
+type Text struct{}
+
+// Write implements Formatter.
+func (Text) Write(w io.Writer, stream <-chan types.Asset) error {
+	var firstErr error
+	for a := range stream {
+		if firstErr != nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", a.AssetType(), a.Key()); err != nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// JSON renders one JSON object per asset, newline-delimited.
+type JSON struct{}
+
+// Write implements Formatter.
+func (JSON) Write(w io.Writer, stream <-chan types.Asset) error {
+	enc := json.NewEncoder(w)
+	var firstErr error
+	for a := range stream {
+		if firstErr != nil {
+			continue
+		}
+		if err := enc.Encode(a); err != nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CSV renders a "type,key" row per asset.
+type CSV struct{}
+
+// Write implements Formatter.
+func (CSV) Write(w io.Writer, stream <-chan types.Asset) error {
+	cw := csv.NewWriter(w)
+	var firstErr error
+	for a := range stream {
+		if firstErr != nil {
+			continue
+		}
+		if err := cw.Write([]string{string(a.AssetType()), a.Key()}); err != nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		cw.Flush()
+		firstErr = cw.Error()
+	}
+	return firstErr
+}
@@ -0,0 +1,67 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func sampleStream() <-chan types.Asset {
+	ch := make(chan types.Asset, 2)
+	ch <- types.FQDN{Name: "www.example.com"}
+	ch <- types.IPAddress{Address: "198.51.100.7", Type: types.IPTypeIPv4}
+	close(ch)
+	return ch
+}
+
+func TestTextFormatterRendersOneLinePerAsset(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (Text{}).Write(&buf, sampleStream()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "FQDN\tFQDN:www.example.com") || !strings.Contains(out, "IPAddress\tIPAddress:198.51.100.7") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestJSONFormatterRendersOneObjectPerAsset(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (JSON{}).Write(&buf, sampleStream()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %d: %q", len(lines), buf.String())
+	}
+}
+
+func TestCSVFormatterRendersOneRowPerAsset(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (CSV{}).Write(&buf, sampleStream()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 CSV rows, got %d: %q", len(lines), buf.String())
+	}
+	if lines[0] != "FQDN,FQDN:www.example.com" {
+		t.Fatalf("unexpected first row: %q", lines[0])
+	}
+}
+
+func TestByNameResolvesEachFormatter(t *testing.T) {
+	for _, name := range []string{"text", "json", "csv"} {
+		if _, ok := ByName(name); !ok {
+			t.Fatalf("expected %q to resolve to a formatter", name)
+		}
+	}
+	if _, ok := ByName("xml"); ok {
+		t.Fatal("expected an unknown format name to fail to resolve")
+	}
+}
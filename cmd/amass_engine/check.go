@@ -0,0 +1,94 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// The -check diagnostic mode helps new users tell config problems apart
+// from network or credential problems before committing to a full scan.
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// CheckResult is the outcome of a single diagnostic probe.
+type CheckResult struct {
+	Name string
+	Pass bool
+	Err  error
+}
+
+// Resolver is the minimal surface a diagnostic needs to probe a DNS
+// resolver's reachability.
+type Resolver interface {
+	Probe(ctx context.Context, addr string) error
+}
+
+// CredentialSource is the minimal surface a diagnostic needs to probe a
+// data source's configured credentials.
+type CredentialSource struct {
+	Name  string
+	Probe func(ctx context.Context) error
+}
+
+// DBPinger is the minimal surface a diagnostic needs to confirm database
+// connectivity and that migrations have been applied.
+type DBPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// checkResolvers probes each address in trusted and a sample of untrusted,
+// reporting one CheckResult per address.
+func checkResolvers(ctx context.Context, r Resolver, trusted, untrustedSample []string) []CheckResult {
+	var out []CheckResult
+	for _, addr := range trusted {
+		out = append(out, probeAddr(ctx, r, "trusted resolver "+addr, addr))
+	}
+	for _, addr := range untrustedSample {
+		out = append(out, probeAddr(ctx, r, "untrusted resolver "+addr, addr))
+	}
+	return out
+}
+
+func probeAddr(ctx context.Context, r Resolver, name, addr string) CheckResult {
+	err := r.Probe(ctx, addr)
+	return CheckResult{Name: name, Pass: err == nil, Err: err}
+}
+
+// checkCredentials runs each source's cheap credential probe.
+func checkCredentials(ctx context.Context, sources []CredentialSource) []CheckResult {
+	out := make([]CheckResult, 0, len(sources))
+	for _, s := range sources {
+		err := s.Probe(ctx)
+		out = append(out, CheckResult{Name: "credential: " + s.Name, Pass: err == nil, Err: err})
+	}
+	return out
+}
+
+// checkDB confirms the database is reachable and migrated.
+func checkDB(ctx context.Context, db DBPinger) CheckResult {
+	err := db.Ping(ctx)
+	return CheckResult{Name: "database connectivity", Pass: err == nil, Err: err}
+}
+
+// RunSelfTest executes every diagnostic and prints a pass/fail summary,
+// returning false if anything failed.
+func RunSelfTest(ctx context.Context, r Resolver, trusted, untrustedSample []string, sources []CredentialSource, db DBPinger) bool {
+	results := checkResolvers(ctx, r, trusted, untrustedSample)
+	results = append(results, checkCredentials(ctx, sources)...)
+	results = append(results, checkDB(ctx, db))
+
+	ok := true
+	for _, res := range results {
+		status := "PASS"
+		if !res.Pass {
+			status = "FAIL"
+			ok = false
+		}
+		if res.Err != nil {
+			fmt.Printf("[%s] %s: %v\n", status, res.Name, res.Err)
+		} else {
+			fmt.Printf("[%s] %s\n", status, res.Name)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,53 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubResolver struct{ fail map[string]bool }
+
+func (s stubResolver) Probe(ctx context.Context, addr string) error {
+	if s.fail[addr] {
+		return errors.New("unreachable")
+	}
+	return nil
+}
+
+type stubDB struct{ err error }
+
+func (s stubDB) Ping(ctx context.Context) error { return s.err }
+
+func TestCheckResolversReportsFailures(t *testing.T) {
+	r := stubResolver{fail: map[string]bool{"9.9.9.9": true}}
+	results := checkResolvers(context.Background(), r, []string{"8.8.8.8"}, []string{"9.9.9.9"})
+
+	if len(results) != 2 || !results[0].Pass || results[1].Pass {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestCheckCredentialsReportsPerSource(t *testing.T) {
+	sources := []CredentialSource{
+		{Name: "shodan", Probe: func(context.Context) error { return nil }},
+		{Name: "censys", Probe: func(context.Context) error { return errors.New("bad key") }},
+	}
+	results := checkCredentials(context.Background(), sources)
+
+	if len(results) != 2 || !results[0].Pass || results[1].Pass {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestCheckDB(t *testing.T) {
+	if res := checkDB(context.Background(), stubDB{}); !res.Pass {
+		t.Fatalf("expected DB check to pass")
+	}
+	if res := checkDB(context.Background(), stubDB{err: errors.New("no such table")}); res.Pass {
+		t.Fatalf("expected DB check to fail")
+	}
+}
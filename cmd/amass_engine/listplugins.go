@@ -0,0 +1,31 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/owasp-amass/engine/registry"
+)
+
+// formatPluginList renders handlers as the -list-plugins flag's output: one
+// line per handler, naming the plugin, the event type it handles, and the
+// asset types it can transform that event into, so operators can debug a
+// config/transform mismatch without reading source.
+func formatPluginList(handlers []registry.HandlerInfo) string {
+	if len(handlers) == 0 {
+		return "no handlers registered\n"
+	}
+
+	var b strings.Builder
+	for _, h := range handlers {
+		name := h.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		fmt.Fprintf(&b, "%s: %s -> [%s]\n", name, h.Type, strings.Join(h.Transforms, ", "))
+	}
+	return b.String()
+}
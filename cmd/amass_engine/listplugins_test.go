@@ -0,0 +1,32 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/owasp-amass/engine/registry"
+)
+
+func TestFormatPluginListIncludesKnownHandlersAndTransforms(t *testing.T) {
+	out := formatPluginList([]registry.HandlerInfo{
+		{Type: "FQDN", Name: "dns", Transforms: []string{"IPAddress", "FQDN"}},
+		{Type: "IPAddress", Name: "bgptools", Transforms: []string{"ASN", "Netblock"}},
+	})
+
+	if !strings.Contains(out, "dns: FQDN -> [IPAddress, FQDN]") {
+		t.Fatalf("expected the dns handler's transforms in the listing, got %q", out)
+	}
+	if !strings.Contains(out, "bgptools: IPAddress -> [ASN, Netblock]") {
+		t.Fatalf("expected the bgptools handler's transforms in the listing, got %q", out)
+	}
+}
+
+func TestFormatPluginListWithNoHandlers(t *testing.T) {
+	out := formatPluginList(nil)
+	if out != "no handlers registered\n" {
+		t.Fatalf("unexpected output for an empty registry: %q", out)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// Blocklist is a hard blocklist of FQDN suffixes and IP CIDRs that no
+// plugin may process, regardless of scope. It's distinct from (and takes
+// priority over) scope includes: an asset can be in scope and still be
+// blocklisted, e.g. a shared-hosting apex or a sinkhole IP a client wants
+// excluded outright.
+type Blocklist struct {
+	FQDNSuffixes []string
+	CIDRs        []netip.Prefix
+}
+
+// NewBlocklist builds a Blocklist from operator-supplied FQDN suffixes and
+// CIDR strings, skipping any CIDR that fails to parse.
+func NewBlocklist(fqdnSuffixes, cidrs []string) *Blocklist {
+	b := &Blocklist{FQDNSuffixes: fqdnSuffixes}
+	for _, c := range cidrs {
+		if p, err := netip.ParsePrefix(c); err == nil {
+			b.CIDRs = append(b.CIDRs, p)
+		}
+	}
+	return b
+}
+
+// BlocksFQDN reports whether name matches one of the blocklisted suffixes.
+func (b *Blocklist) BlocksFQDN(name string) bool {
+	if b == nil {
+		return false
+	}
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, suffix := range b.FQDNSuffixes {
+		suffix = strings.ToLower(strings.TrimPrefix(suffix, "*."))
+		if name == suffix || strings.HasSuffix(name, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// BlocksIP reports whether addr falls inside one of the blocklisted CIDRs.
+func (b *Blocklist) BlocksIP(addr string) bool {
+	if b == nil {
+		return false
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	for _, cidr := range b.CIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package config defines the engine's runtime configuration: everything an
+// operator can tune without recompiling, loaded from flags and/or a config
+// file.
+package config
+
+import "time"
+
+// LogFormat selects how the engine-level logger renders records.
+type LogFormat string
+
+const (
+	// LogFormatJSON renders one JSON object per log line. This is the
+	// default, matching the historical behavior.
+	LogFormatJSON LogFormat = "json"
+	// LogFormatText renders human-readable key=value lines.
+	LogFormatText LogFormat = "text"
+)
+
+// LogConfig controls the engine-level logger (as opposed to the per-session
+// logger, which always publishes to pubsub for the API's log subscription).
+type LogConfig struct {
+	// Format selects json or text output. Empty defaults to json.
+	Format LogFormat
+	// Level is a slog level name: "debug", "info", "warn", or "error".
+	// Empty defaults to "info".
+	Level string
+	// Output selects the destination: "stdout", "stderr", or a file
+	// path. Empty defaults to "stdout".
+	Output string
+}
+
+// DatabaseConfig configures where a session's discovered assets and
+// relations are written. Primary is required; GraphDBs lists additional
+// databases results are mirrored to (e.g. a local SQLite for the session
+// and a central Postgres for reporting).
+type DatabaseConfig struct {
+	// Primary is the DSN every session reads back from.
+	Primary string
+	// GraphDBs are additional DSNs writes are mirrored to. A failure
+	// writing to one of these doesn't fail the overall write, since
+	// Primary already has the data of record.
+	GraphDBs []string
+	// InMemory selects an ephemeral, in-memory-only graph backend for a
+	// session: no DSN file is created and no schema migration runs, so a
+	// quick one-off scan isn't paying migration overhead for results
+	// nobody intends to keep. Primary and GraphDBs are ignored when this
+	// is set.
+	InMemory bool
+}
+
+// DNSConfig controls how the DNS plugins resolve and persist records.
+type DNSConfig struct {
+	// SkipUnchangedZones enables SOA-serial-based change detection: when
+	// true, a subsequent scan of an apex whose SOA serial hasn't changed
+	// since the last scan skips heavy re-enumeration of that zone rather
+	// than repeating work that can't turn up anything new.
+	SkipUnchangedZones bool
+	// PersistRecordTypes lists the DNS record type names (e.g. "A",
+	// "AAAA", "CNAME") whose target assets are written to the graph.
+	// Every discovered record is still used for traversal (following a
+	// CNAME, resolving an MX to its host, etc.) regardless of this list;
+	// it only controls which ones also become first-class graph assets.
+	// Empty means persist everything, matching the historical behavior.
+	PersistRecordTypes []string
+	// MaxCacheTTL caps how long a resolved record is trusted in the DNS
+	// plugins' answer cache, regardless of the record's own TTL: a
+	// cached answer's lifetime is min(recordTTL, MaxCacheTTL). Without a
+	// cap, a misconfigured or hostile nameserver returning an absurdly
+	// long TTL could pin a stale answer in the cache indefinitely. Zero
+	// disables the cap, so a record's own TTL alone governs its expiry.
+	MaxCacheTTL time.Duration
+	// DetectionResolver is the resolver address used to cross-check a
+	// suspiciously uniform set of DNS answers for wildcard detection,
+	// deliberately outside the resolver pool being tested for wildcards.
+	// Empty defaults to "8.8.8.8".
+	DetectionResolver string
+	// ExcludePatterns lists record data (a resolved name, most often a
+	// PTR or CNAME target) to drop before it becomes a graph asset.
+	// Reverse DNS and passive sources frequently return generic provider
+	// PTRs (e.g. "*.compute.amazonaws.com") that are technically correct
+	// but never useful to an analyst. Each entry is matched as a
+	// case-insensitive domain suffix, unless it's wrapped in slashes
+	// (e.g. "/ec2-.*\\.amazonaws\\.com/"), in which case it's compiled
+	// as a regular expression instead. Empty excludes nothing.
+	ExcludePatterns []string
+}
+
+// SessionConfig controls limits applied to every session the engine runs.
+type SessionConfig struct {
+	// MaxDuration bounds how long a session may run before it's stopped
+	// with whatever results it has so far, the same as an operator
+	// calling Kill by hand. Zero means unbounded, the historical
+	// behavior for scans without an active-mode time budget.
+	MaxDuration time.Duration
+	// MaxRequestsPerSecond caps this session's combined outbound DNS and
+	// HTTP request rate, satisfying rules-of-engagement that cap total
+	// traffic to a target rather than capping each protocol separately.
+	// Zero or negative disables the cap, the historical unbounded
+	// behavior.
+	MaxRequestsPerSecond int
+}
+
+// WorkerConfig controls the engine-wide ceiling on concurrently running
+// goroutines drawn from major goroutine-launching sites (DNS sweeps,
+// brute-force guess attempts, pipeline tasks), independent of each site's
+// own per-call concurrency setting.
+type WorkerConfig struct {
+	// MaxConcurrentGoroutines caps the total number of goroutines every
+	// opted-in site may run at once, combined. Zero or negative disables
+	// the cap, matching the historical, unbounded behavior.
+	MaxConcurrentGoroutines int
+}
+
+// ResolverListConfig controls how the untrusted pool's public resolver
+// list, and the trusted pool's baseline resolver list, are loaded.
+type ResolverListConfig struct {
+	// LocalFile, if set, loads the untrusted pool's resolver list from
+	// this path instead of fetching it, for air-gapped environments with
+	// no route to the public list at all.
+	LocalFile string
+	// BaselineFile, if set, loads the trusted pool's baseline resolver
+	// list (including each resolver's QPS weight) from this JSON file
+	// instead of the compiled-in default, so operators can curate their
+	// trusted pool as public resolvers' reliability changes without a
+	// rebuild.
+	BaselineFile string
+	// MaxRetries bounds how many additional fetch attempts are made
+	// after the first failure, with exponential backoff between them.
+	// Zero disables retrying, the historical fetch-once behavior.
+	MaxRetries int
+	// RetryDelay is the base delay before the first retry. Zero selects
+	// a small default.
+	RetryDelay time.Duration
+	// RefreshInterval, if set, re-fetches the list on this interval for
+	// the life of the engine, so a list update doesn't require a
+	// restart. Zero disables periodic refresh.
+	RefreshInterval time.Duration
+}
+
+// Config holds every tunable the engine reads at startup.
+type Config struct {
+	Log       LogConfig
+	DB        DatabaseConfig
+	DNS       DNSConfig
+	Session   SessionConfig
+	Scope     ScopeConfig
+	Workers   WorkerConfig
+	Resolvers ResolverListConfig
+	// Plugins holds free-form, per-plugin settings keyed by plugin name,
+	// for options that don't warrant their own field on this struct
+	// (e.g. a crt.sh include-expired flag, a brute-force wordlist path).
+	// A plugin absent from the map gets no options, not an error.
+	Plugins PluginOptions
+}
+
+// PluginOptions is a plugin name's free-form key/value settings, read by
+// that plugin alone rather than shared config schema every plugin must
+// extend to add its own knob.
+type PluginOptions map[string]map[string]string
@@ -0,0 +1,113 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ScopeConfig is the in-scope domains and data sources for a session,
+// loaded from one or more config files.
+type ScopeConfig struct {
+	Domains     []string
+	Datasources []string
+	// SeedPriorities maps a seed domain to the scheduler.Event.Priority
+	// its seed asset is dispatched with, so an operator working several
+	// domains at once can have the one they care about most resolve
+	// first instead of waiting behind whichever happened to be listed
+	// earlier. A domain absent from the map uses the default priority
+	// of zero.
+	SeedPriorities map[string]int
+}
+
+// scopeFile is the on-disk shape of a scope config file: its own
+// domains/datasources plus paths to other config files to merge in.
+// Includes are resolved relative to the including file's directory, so a
+// shared includes directory works regardless of where the top-level
+// config lives.
+type scopeFile struct {
+	Includes       []string       `json:"includes"`
+	Domains        []string       `json:"domains"`
+	Datasources    []string       `json:"datasources"`
+	SeedPriorities map[string]int `json:"seed_priorities"`
+}
+
+// LoadScopeConfig reads path and every config file it includes
+// (transitively), merging them into a single ScopeConfig. A file's own
+// domains/datasources take precedence (are listed first) over its
+// includes', and includes are merged in the order they're listed;
+// duplicate values are dropped wherever they occur. Include cycles are
+// rejected instead of looping forever.
+func LoadScopeConfig(path string) (ScopeConfig, error) {
+	return loadScopeConfig(path, make(map[string]bool))
+}
+
+func loadScopeConfig(path string, visited map[string]bool) (ScopeConfig, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ScopeConfig{}, fmt.Errorf("config: failed to resolve %q: %w", path, err)
+	}
+	if visited[abs] {
+		return ScopeConfig{}, fmt.Errorf("config: include cycle detected at %q", path)
+	}
+	visited[abs] = true
+
+	raw, err := os.ReadFile(abs)
+	if err != nil {
+		return ScopeConfig{}, fmt.Errorf("config: failed to read %q: %w", path, err)
+	}
+	var sf scopeFile
+	if err := json.Unmarshal(raw, &sf); err != nil {
+		return ScopeConfig{}, fmt.Errorf("config: failed to parse %q: %w", path, err)
+	}
+
+	merged := ScopeConfig{Domains: sf.Domains, Datasources: sf.Datasources, SeedPriorities: sf.SeedPriorities}
+	dir := filepath.Dir(abs)
+	for _, include := range sf.Includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(dir, include)
+		}
+		included, err := loadScopeConfig(include, visited)
+		if err != nil {
+			return ScopeConfig{}, err
+		}
+		merged.Domains = append(merged.Domains, included.Domains...)
+		merged.Datasources = append(merged.Datasources, included.Datasources...)
+		for domain, priority := range included.SeedPriorities {
+			if merged.SeedPriorities == nil {
+				merged.SeedPriorities = make(map[string]int)
+			}
+			// A file's own priority for a domain takes precedence over
+			// one inherited from an include, matching how Domains and
+			// Datasources already favor the including file.
+			if _, exists := merged.SeedPriorities[domain]; !exists {
+				merged.SeedPriorities[domain] = priority
+			}
+		}
+	}
+
+	merged.Domains = dedup(merged.Domains)
+	merged.Datasources = dedup(merged.Datasources)
+	return merged, nil
+}
+
+// dedup drops duplicate values, keeping the first occurrence's position.
+func dedup(values []string) []string {
+	if len(values) == 0 {
+		return values
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeScopeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %q: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadScopeConfigMergesBaseWithTwoIncludes(t *testing.T) {
+	dir := t.TempDir()
+
+	writeScopeFile(t, dir, "unit-a.json", `{"domains":["a.example.com"],"datasources":["passivetotal"]}`)
+	writeScopeFile(t, dir, "unit-b.json", `{"domains":["b.example.com","a.example.com"],"datasources":["urlscan"]}`)
+	basePath := writeScopeFile(t, dir, "base.json", `{
+		"includes": ["unit-a.json", "unit-b.json"],
+		"domains": ["base.example.com"],
+		"datasources": ["passivetotal"]
+	}`)
+
+	scope, err := LoadScopeConfig(basePath)
+	if err != nil {
+		t.Fatalf("LoadScopeConfig failed: %v", err)
+	}
+
+	wantDomains := []string{"base.example.com", "a.example.com", "b.example.com"}
+	if !equalStrings(scope.Domains, wantDomains) {
+		t.Fatalf("expected domains %v, got %v", wantDomains, scope.Domains)
+	}
+	wantSources := []string{"passivetotal", "urlscan"}
+	if !equalStrings(scope.Datasources, wantSources) {
+		t.Fatalf("expected datasources %v, got %v", wantSources, scope.Datasources)
+	}
+}
+
+func TestLoadScopeConfigMergesSeedPrioritiesFavoringTheIncludingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	writeScopeFile(t, dir, "unit-a.json", `{"domains":["a.example.com"],"seed_priorities":{"a.example.com":1,"shared.example.com":1}}`)
+	basePath := writeScopeFile(t, dir, "base.json", `{
+		"includes": ["unit-a.json"],
+		"domains": ["base.example.com"],
+		"seed_priorities": {"base.example.com": 10, "shared.example.com": 5}
+	}`)
+
+	scope, err := LoadScopeConfig(basePath)
+	if err != nil {
+		t.Fatalf("LoadScopeConfig failed: %v", err)
+	}
+
+	if scope.SeedPriorities["base.example.com"] != 10 {
+		t.Fatalf("expected base.example.com priority 10, got %d", scope.SeedPriorities["base.example.com"])
+	}
+	if scope.SeedPriorities["a.example.com"] != 1 {
+		t.Fatalf("expected a.example.com priority 1 from the include, got %d", scope.SeedPriorities["a.example.com"])
+	}
+	if scope.SeedPriorities["shared.example.com"] != 5 {
+		t.Fatalf("expected the base file's priority to win over the include's for a shared domain, got %d", scope.SeedPriorities["shared.example.com"])
+	}
+}
+
+func TestLoadScopeConfigRejectsIncludeCycles(t *testing.T) {
+	dir := t.TempDir()
+
+	pathA := filepath.Join(dir, "a.json")
+	pathB := filepath.Join(dir, "b.json")
+	if err := os.WriteFile(pathA, []byte(`{"includes":["b.json"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pathB, []byte(`{"includes":["a.json"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadScopeConfig(pathA); err == nil {
+		t.Fatal("expected an include cycle to be rejected")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
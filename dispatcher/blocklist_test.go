@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dispatcher
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+type blockableEvent struct {
+	pipeline, fqdn, ip string
+}
+
+func (e blockableEvent) PipelineName() string             { return e.pipeline }
+func (e blockableEvent) BlockCandidates() (string, string) { return e.fqdn, e.ip }
+
+// TestDispatchEventSkipsBlocklistedAssetsEvenInScope ensures a blocklisted
+// FQDN or IP never reaches its handler, independent of any scope decision.
+func TestDispatchEventSkipsBlocklistedAssetsEvenInScope(t *testing.T) {
+	d := NewDispatcher()
+	d.SetBlocklist(config.NewBlocklist([]string{"blocked.example.com"}, []string{"10.0.0.0/8"}))
+
+	var handled int32
+	d.RegisterPipeline("names", PipelineConfig{}, func(Event) { atomic.AddInt32(&handled, 1) })
+
+	events := []blockableEvent{
+		{pipeline: "names", fqdn: "blocked.example.com"},
+		{pipeline: "names", fqdn: "sub.blocked.example.com"},
+		{pipeline: "names", ip: "10.1.2.3"},
+		{pipeline: "names", fqdn: "allowed.example.com"},
+	}
+	for _, e := range events {
+		if err := d.DispatchEvent(e); err != nil {
+			t.Fatalf("unexpected dispatch error: %v", err)
+		}
+	}
+
+	deadline := time.After(time.Second)
+	for atomic.LoadInt32(&handled) < 1 {
+		select {
+		case <-deadline:
+			t.Fatal("expected the allowed event to still be handled")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&handled); got != 1 {
+		t.Fatalf("expected exactly 1 handled event (the non-blocklisted one), got %d", got)
+	}
+	if got := d.Suppressed(); got != 3 {
+		t.Fatalf("expected 3 suppressed events, got %d", got)
+	}
+}
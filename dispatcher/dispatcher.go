@@ -0,0 +1,255 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package dispatcher routes events discovered during a session to the
+// pipelines responsible for handling them.
+package dispatcher
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+// Event is anything the dispatcher can route to a pipeline. The engine's
+// actual event types satisfy this by carrying enough information to select
+// a destination pipeline.
+type Event interface {
+	// PipelineName identifies which registered pipeline should receive
+	// the event.
+	PipelineName() string
+}
+
+// Blockable is implemented by events that carry an asset the blocklist can
+// be checked against. Events that don't implement it (e.g. internal
+// control events) are never blocked.
+type Blockable interface {
+	// BlockCandidates returns the FQDN and/or IP address string this
+	// event should be checked against the blocklist, either of which
+	// may be empty.
+	BlockCandidates() (fqdn, ip string)
+}
+
+// OverflowPolicy controls what DispatchEvent does when a pipeline's queue
+// is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes DispatchEvent block until space is available.
+	// This is the default and preserves the historical unbounded-queue
+	// behavior's guarantee that no event is ever lost.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop makes DispatchEvent drop the event immediately and
+	// increment a metric instead of blocking the caller.
+	OverflowDrop
+)
+
+// ErrUnknownPipeline is returned when DispatchEvent targets a pipeline that
+// was never registered.
+var ErrUnknownPipeline = errors.New("dispatcher: unknown pipeline")
+
+// PipelineConfig configures the queue backing a single registered pipeline.
+type PipelineConfig struct {
+	// QueueSize bounds the number of events buffered for this pipeline.
+	// Zero (the default) keeps the historical unbounded queue.
+	QueueSize int
+	// Overflow selects what happens once QueueSize is reached. Ignored
+	// when QueueSize is zero.
+	Overflow OverflowPolicy
+}
+
+// pipeline holds the queue and consumer loop for one destination. Unbounded
+// pipelines (the default) use an unboundedQueue, matching the original
+// behavior of an ever-growing in-memory queue; bounded pipelines use a
+// fixed-capacity channel so DispatchEvent can apply backpressure.
+type pipeline struct {
+	cfg      PipelineConfig
+	unbounded *unboundedQueue
+	bounded  chan Event
+	handle   func(Event)
+	dropped  uint64
+}
+
+// Dispatcher routes events to registered pipelines by name, optionally
+// applying backpressure so a fast producer cannot grow a slow pipeline's
+// queue without bound.
+type Dispatcher struct {
+	mu         sync.RWMutex
+	pipelines  map[string]*pipeline
+	blocklist  *config.Blocklist
+	log        *slog.Logger
+	suppressed uint64
+}
+
+// NewDispatcher returns an empty Dispatcher ready to have pipelines
+// registered on it.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{pipelines: make(map[string]*pipeline), log: slog.Default()}
+}
+
+// SetBlocklist installs the blocklist DispatchEvent enforces before any
+// handler runs. It's checked ahead of and independent from scope: an asset
+// can be in scope and still be blocklisted.
+func (d *Dispatcher) SetBlocklist(b *config.Blocklist) {
+	d.mu.Lock()
+	d.blocklist = b
+	d.mu.Unlock()
+}
+
+// RegisterPipeline wires name to handle using cfg. handle is invoked once
+// per dispatched event, on a dedicated goroutine that drains the pipeline's
+// queue in submission order.
+func (d *Dispatcher) RegisterPipeline(name string, cfg PipelineConfig, handle func(Event)) {
+	p := &pipeline{cfg: cfg, handle: handle}
+	if cfg.QueueSize > 0 {
+		p.bounded = make(chan Event, cfg.QueueSize)
+		go p.drainBounded()
+	} else {
+		p.unbounded = newUnboundedQueue()
+		go p.drainUnbounded()
+	}
+
+	d.mu.Lock()
+	d.pipelines[name] = p
+	d.mu.Unlock()
+}
+
+func (p *pipeline) drainBounded() {
+	for e := range p.bounded {
+		p.handle(e)
+	}
+}
+
+func (p *pipeline) drainUnbounded() {
+	for {
+		e, ok := p.unbounded.pop()
+		if !ok {
+			return
+		}
+		p.handle(e)
+	}
+}
+
+// DispatchEvent routes e to the pipeline named by e.PipelineName(). When
+// that pipeline was registered with a bounded QueueSize, DispatchEvent
+// applies the configured OverflowPolicy once the queue is full; unbounded
+// pipelines (the default) append immediately, matching the original
+// behavior.
+func (d *Dispatcher) DispatchEvent(e Event) error {
+	d.mu.RLock()
+	p, ok := d.pipelines[e.PipelineName()]
+	bl := d.blocklist
+	d.mu.RUnlock()
+	if !ok {
+		return ErrUnknownPipeline
+	}
+
+	if bl != nil {
+		if blockable, ok := e.(Blockable); ok {
+			fqdn, ip := blockable.BlockCandidates()
+			if (fqdn != "" && bl.BlocksFQDN(fqdn)) || (ip != "" && bl.BlocksIP(ip)) {
+				d.log.Debug("dispatch: skipping blocklisted asset", "fqdn", fqdn, "ip", ip)
+				atomic.AddUint64(&d.suppressed, 1)
+				return nil
+			}
+		}
+	}
+
+	if p.bounded == nil {
+		p.unbounded.push(e)
+		return nil
+	}
+
+	if p.cfg.Overflow == OverflowDrop {
+		select {
+		case p.bounded <- e:
+		default:
+			atomic.AddUint64(&p.dropped, 1)
+		}
+		return nil
+	}
+
+	p.bounded <- e
+	return nil
+}
+
+// Suppressed reports how many events DispatchEvent has declined to route,
+// e.g. because they matched the blocklist, across every pipeline.
+func (d *Dispatcher) Suppressed() uint64 {
+	return atomic.LoadUint64(&d.suppressed)
+}
+
+// Dropped reports how many events were dropped for the named pipeline due
+// to OverflowDrop. It returns 0 for unknown or unbounded pipelines.
+func (d *Dispatcher) Dropped(name string) uint64 {
+	d.mu.RLock()
+	p, ok := d.pipelines[name]
+	d.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// Close closes every pipeline's queue, bounded and unbounded, so each
+// consumer goroutine exits once it has handled the events already queued.
+// It returns without waiting for those events to be handled.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, p := range d.pipelines {
+		if p.bounded != nil {
+			close(p.bounded)
+		} else {
+			p.unbounded.close()
+		}
+	}
+}
+
+// unboundedQueue is a FIFO queue with no capacity limit, backed by a slice
+// guarded by a mutex and condition variable. It reproduces the dispatcher's
+// original unbounded-growth behavior for pipelines that don't opt into a
+// bounded QueueSize.
+type unboundedQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []Event
+	closed bool
+}
+
+func newUnboundedQueue() *unboundedQueue {
+	q := &unboundedQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *unboundedQueue) push(e Event) {
+	q.mu.Lock()
+	q.items = append(q.items, e)
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+func (q *unboundedQueue) pop() (Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	e := q.items[0]
+	q.items = q.items[1:]
+	return e, true
+}
+
+func (q *unboundedQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
@@ -0,0 +1,63 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dispatcher
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	pipeline string
+}
+
+func (e testEvent) PipelineName() string { return e.pipeline }
+
+// TestDispatchEventBoundedQueueStaysBounded drives a fast producer against a
+// slow consumer through a bounded, dropping pipeline and asserts the queue
+// never grows past its configured capacity.
+func TestDispatchEventBoundedQueueStaysBounded(t *testing.T) {
+	const queueSize = 16
+
+	d := NewDispatcher()
+	var handled int64
+	release := make(chan struct{})
+	d.RegisterPipeline("slow", PipelineConfig{QueueSize: queueSize, Overflow: OverflowDrop}, func(Event) {
+		<-release
+		atomic.AddInt64(&handled, 1)
+	})
+
+	// Flood far more events than the queue can hold; with OverflowDrop the
+	// producer must never block and the channel must never exceed its
+	// configured capacity.
+	for i := 0; i < 10000; i++ {
+		if err := d.DispatchEvent(testEvent{pipeline: "slow"}); err != nil {
+			t.Fatalf("unexpected dispatch error: %v", err)
+		}
+	}
+
+	if dropped := d.Dropped("slow"); dropped == 0 {
+		t.Fatalf("expected some events to be dropped once the bounded queue filled")
+	}
+
+	close(release)
+	deadline := time.After(2 * time.Second)
+	for atomic.LoadInt64(&handled) == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("consumer never made progress")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// TestDispatchEventUnknownPipeline ensures dispatching to a pipeline that
+// was never registered returns ErrUnknownPipeline instead of panicking.
+func TestDispatchEventUnknownPipeline(t *testing.T) {
+	d := NewDispatcher()
+	if err := d.DispatchEvent(testEvent{pipeline: "missing"}); err != ErrUnknownPipeline {
+		t.Fatalf("expected ErrUnknownPipeline, got %v", err)
+	}
+}
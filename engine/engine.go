@@ -0,0 +1,103 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package engine wires together configuration, session management, and the
+// API server into the running amass engine process.
+package engine
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/registry"
+)
+
+// Engine is the top-level object created by cmd/amass_engine, owning the
+// engine-level logger and session registry.
+type Engine struct {
+	Log *slog.Logger
+	cfg *config.Config
+	reg *registry.Registry
+}
+
+// NewEngine constructs an Engine from cfg, building the engine-level
+// logger according to cfg.Log. This is distinct from the per-session
+// logger, which always writes structured records into pubsub for the API's
+// log subscription regardless of this setting.
+func NewEngine(cfg *config.Config) (*Engine, error) {
+	out, err := logOutput(cfg.Log.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := newLogHandler(cfg.Log.Format, cfg.Log.Level, out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{Log: slog.New(handler), cfg: cfg, reg: registry.NewRegistry()}, nil
+}
+
+// Registry returns the engine's handler registry, so plugin packages can
+// register against it during startup and the API/CLI can introspect it
+// afterward.
+func (e *Engine) Registry() *registry.Registry {
+	return e.reg
+}
+
+// ListPlugins returns the metadata for every handler registered against
+// the engine's registry, e.g. after plugin startup has run every
+// plugin's registration function against Registry(). It's what backs
+// both the engine/API plugin-listing query and cmd/amass_engine's
+// -list-plugins flag.
+func (e *Engine) ListPlugins() []registry.HandlerInfo {
+	return e.reg.ListHandlers()
+}
+
+func logOutput(output string) (io.Writer, error) {
+	switch output {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	default:
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("engine: failed to open log output %q: %w", output, err)
+		}
+		return f, nil
+	}
+}
+
+// newLogHandler builds the slog.Handler matching format/level. JSON remains
+// the default so existing deployments piping the log file into a collector
+// see no change unless they opt into text output.
+func newLogHandler(format config.LogFormat, level string, out io.Writer) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	switch format {
+	case "", config.LogFormatJSON:
+		return slog.NewJSONHandler(out, opts), nil
+	case config.LogFormatText:
+		return slog.NewTextHandler(out, opts), nil
+	default:
+		return nil, fmt.Errorf("engine: unknown log format %q", format)
+	}
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package engine
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+func TestNewEngineSelectsConfiguredHandlerType(t *testing.T) {
+	jsonEngine, err := NewEngine(&config.Config{Log: config.LogConfig{Format: config.LogFormatJSON, Output: "stderr"}})
+	if err != nil {
+		t.Fatalf("NewEngine (json) failed: %v", err)
+	}
+	if _, ok := jsonEngine.Log.Handler().(*slog.JSONHandler); !ok {
+		t.Fatalf("expected *slog.JSONHandler, got %T", jsonEngine.Log.Handler())
+	}
+
+	textEngine, err := NewEngine(&config.Config{Log: config.LogConfig{Format: config.LogFormatText, Output: "stderr"}})
+	if err != nil {
+		t.Fatalf("NewEngine (text) failed: %v", err)
+	}
+	if _, ok := textEngine.Log.Handler().(*slog.TextHandler); !ok {
+		t.Fatalf("expected *slog.TextHandler, got %T", textEngine.Log.Handler())
+	}
+}
+
+func TestNewEngineDefaultsToJSON(t *testing.T) {
+	e, err := NewEngine(&config.Config{Log: config.LogConfig{Output: "stderr"}})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if _, ok := e.Log.Handler().(*slog.JSONHandler); !ok {
+		t.Fatalf("expected default handler to be JSON, got %T", e.Log.Handler())
+	}
+}
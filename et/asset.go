@@ -0,0 +1,41 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package et (event types) holds the conversions plugins use to turn a
+// discovered string into a canonical types.Asset and to wrap an asset in a
+// registry.Event, so every plugin does it the same way instead of each
+// hand-rolling its own asset↔event glue with subtly different behavior.
+package et
+
+import (
+	"github.com/owasp-amass/engine/registry"
+	"github.com/owasp-amass/engine/types"
+)
+
+// NewAssetEvent builds the registry.Event for asset. When parent is not
+// nil, it's recorded in the event's Meta under "parent", carrying the
+// asset that led to this one's discovery so downstream handlers can walk
+// provenance without every plugin inventing its own convention for it.
+func NewAssetEvent(asset types.Asset, parent types.Asset) *registry.Event {
+	e := &registry.Event{Type: registry.EventType(asset.AssetType()), Asset: asset}
+	if parent != nil {
+		e.Meta = map[string]interface{}{"parent": parent}
+	}
+	return e
+}
+
+// AssetFromString builds the canonical types.Asset of assetType for name.
+// Every plugin should go through this instead of constructing the asset
+// struct literal directly, so representation details normalized here (IP
+// type casing, address canonicalization) can't drift back apart per
+// plugin.
+func AssetFromString(name string, assetType types.AssetType) (types.Asset, bool) {
+	switch assetType {
+	case types.AssetFQDN:
+		return types.FQDN{Name: name}, true
+	case types.AssetIPAddress:
+		return types.NewIPAddress(name)
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package et
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestAssetFromStringProducesConsistentIPTypeCasing(t *testing.T) {
+	a, ok := AssetFromString("198.51.100.7", types.AssetIPAddress)
+	if !ok {
+		t.Fatal("expected the IP to parse")
+	}
+	ip, ok := a.(types.IPAddress)
+	if !ok || ip.Type != "IPv4" {
+		t.Fatalf("expected IPv4 type casing, got %+v", a)
+	}
+}
+
+func TestAssetFromStringRoundTripsThroughKey(t *testing.T) {
+	a1, _ := AssetFromString("www.example.com", types.AssetFQDN)
+	a2, _ := AssetFromString("www.example.com", types.AssetFQDN)
+	if a1.Key() != a2.Key() {
+		t.Fatalf("expected identical input to round-trip to the same key, got %q and %q", a1.Key(), a2.Key())
+	}
+}
+
+func TestNewAssetEventRecordsParentInMeta(t *testing.T) {
+	parent := types.FQDN{Name: "example.com"}
+	child := types.FQDN{Name: "www.example.com"}
+
+	e := NewAssetEvent(child, parent)
+	if e.Type != "FQDN" {
+		t.Fatalf("expected event type FQDN, got %q", e.Type)
+	}
+	if got := e.Meta["parent"]; got != types.Asset(parent) {
+		t.Fatalf("expected the parent asset to be recorded in Meta, got %v", got)
+	}
+}
+
+func TestNewAssetEventWithoutParentLeavesMetaNil(t *testing.T) {
+	e := NewAssetEvent(types.FQDN{Name: "example.com"}, nil)
+	if e.Meta != nil {
+		t.Fatalf("expected no Meta when there's no parent, got %v", e.Meta)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// defaultHeapAlloc reports the process's current heap allocation via
+// runtime.MemStats.
+func defaultHeapAlloc() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// CacheStats reports how effective an OAMCache has been at avoiding
+// redundant lookups against the underlying Graph.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// OAMCache sits in front of a Graph, holding its own read-through copy of
+// assets and a by-endpoint index of relations so repeated lookups and
+// traversals don't have to hit the Graph's own locking and, in the case
+// of relations, a full slice scan. The Graph remains the system of
+// record: everything the cache holds is also written through to it, so
+// data survives a Flush or a process restart that drops the cache alone.
+type OAMCache struct {
+	g      *Graph
+	mu     sync.RWMutex
+	hits   uint64
+	misses uint64
+
+	assets map[string]types.Asset
+	// froms indexes relations by their FromAsset's key, each slice sorted
+	// by ToAsset key so a caller can binary-search a specific edge instead
+	// of scanning every relation originating at a node.
+	froms map[string][]types.Relation
+	// tos is the same index in the opposite direction, keyed by ToAsset.
+	tos map[string][]types.Relation
+
+	// heapThreshold is the runtime.MemStats.HeapAlloc reading, in bytes,
+	// above which MaybeFlush flushes the cache. Zero disables
+	// pressure-based flushing.
+	heapThreshold uint64
+	// heapAlloc reports the current heap allocation. It defaults to a
+	// runtime.MemStats-backed reading but is overridable so tests can
+	// simulate memory pressure without actually exhausting memory.
+	heapAlloc func() uint64
+}
+
+// NewOAMCache returns an OAMCache backed by g, with pressure-based
+// flushing disabled until SetMemoryThreshold is called.
+func NewOAMCache(g *Graph) *OAMCache {
+	return &OAMCache{
+		g:         g,
+		assets:    make(map[string]types.Asset),
+		froms:     make(map[string][]types.Relation),
+		tos:       make(map[string][]types.Relation),
+		heapAlloc: defaultHeapAlloc,
+	}
+}
+
+// GetAsset looks up key, preferring the cache's own copy and only falling
+// through to the underlying Graph on a cache miss, recording a hit or a
+// miss either way.
+func (c *OAMCache) GetAsset(key string) (types.Asset, bool) {
+	c.mu.RLock()
+	a, cached := c.assets[key]
+	c.mu.RUnlock()
+	if cached {
+		atomic.AddUint64(&c.hits, 1)
+		return a, true
+	}
+
+	a, ok := c.g.GetAsset(key)
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddUint64(&c.hits, 1)
+	c.mu.Lock()
+	c.assets[key] = a
+	c.mu.Unlock()
+	return a, true
+}
+
+// UpsertAsset writes a through to the underlying Graph and into the
+// cache's own copy, so a subsequent GetAsset for it hits the cache
+// instead of the Graph.
+func (c *OAMCache) UpsertAsset(a types.Asset) error {
+	if err := c.g.UpsertAsset(a); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.assets[a.Key()] = a
+	c.mu.Unlock()
+	return nil
+}
+
+// SetRelation writes rel through to the underlying Graph and indexes it
+// locally by both endpoints. Each index slice is kept sorted by the
+// opposite endpoint's key so FindFrom/FindTo can binary-search it; rel is
+// placed directly at its sorted position via insertRelation rather than
+// appended and re-sorted, since re-sorting the whole slice on every
+// insert made bulk ingestion of n relations cost O(n² log n) instead of
+// the O(n log n) a sorted insert gives it.
+func (c *OAMCache) SetRelation(rel types.Relation) error {
+	if err := c.g.SetRelation(rel); err != nil {
+		return err
+	}
+
+	fromKey, toKey := rel.FromAsset.Key(), rel.ToAsset.Key()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.froms[fromKey] = insertRelation(c.froms[fromKey], rel, func(r types.Relation) string {
+		return r.ToAsset.Key()
+	})
+	c.tos[toKey] = insertRelation(c.tos[toKey], rel, func(r types.Relation) string {
+		return r.FromAsset.Key()
+	})
+	return nil
+}
+
+// insertRelation inserts rel into rels, which must already be sorted by
+// sortKey, at the position that keeps it sorted, shifting later elements
+// over by one instead of re-sorting the whole slice.
+func insertRelation(rels []types.Relation, rel types.Relation, sortKey func(types.Relation) string) []types.Relation {
+	key := sortKey(rel)
+	i := sort.Search(len(rels), func(i int) bool {
+		return sortKey(rels[i]) >= key
+	})
+	rels = append(rels, types.Relation{})
+	copy(rels[i+1:], rels[i:])
+	rels[i] = rel
+	return rels
+}
+
+// FindFrom returns every indexed relation originating at fromKey.
+func (c *OAMCache) FindFrom(fromKey string) []types.Relation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]types.Relation, len(c.froms[fromKey]))
+	copy(out, c.froms[fromKey])
+	return out
+}
+
+// FindTo returns every indexed relation terminating at toKey.
+func (c *OAMCache) FindTo(toKey string) []types.Relation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]types.Relation, len(c.tos[toKey]))
+	copy(out, c.tos[toKey])
+	return out
+}
+
+// CachedAssetCount returns the number of assets currently held in the
+// cache's own copy, as opposed to the backing Graph.
+func (c *OAMCache) CachedAssetCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.assets)
+}
+
+// SetMemoryThreshold configures the heap-alloc threshold, in bytes, above
+// which MaybeFlush flushes the cache. A threshold of zero disables
+// pressure-based flushing.
+func (c *OAMCache) SetMemoryThreshold(bytes uint64) {
+	c.mu.Lock()
+	c.heapThreshold = bytes
+	c.mu.Unlock()
+}
+
+// MaybeFlush flushes the cache if the current heap allocation exceeds its
+// configured threshold, reporting whether it did. It's meant to be polled
+// periodically (e.g. from a repeating scheduler event) rather than called
+// on every access.
+func (c *OAMCache) MaybeFlush() bool {
+	c.mu.RLock()
+	threshold := c.heapThreshold
+	c.mu.RUnlock()
+
+	if threshold == 0 || c.heapAlloc() < threshold {
+		return false
+	}
+	c.Flush()
+	return true
+}
+
+// Flush drops the cache's own copy of every asset and compacts its
+// relation indices down to freshly-sized slices, releasing whatever
+// memory their over-allocated backing arrays were holding. Nothing is
+// lost: every asset and relation was already written through to the
+// backing Graph, so it stays retrievable there, just no longer cached
+// locally.
+func (c *OAMCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.assets = make(map[string]types.Asset)
+	for key, rels := range c.froms {
+		compacted := make([]types.Relation, len(rels))
+		copy(compacted, rels)
+		c.froms[key] = compacted
+	}
+	for key, rels := range c.tos {
+		compacted := make([]types.Relation, len(rels))
+		copy(compacted, rels)
+		c.tos[key] = compacted
+	}
+}
+
+// Stats returns the current hit/miss counters.
+func (c *OAMCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
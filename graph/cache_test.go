@@ -0,0 +1,120 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestOAMCacheGetAssetTracksHitsAndMisses(t *testing.T) {
+	g := NewGraph()
+	g.UpsertAsset(types.FQDN{Name: "www.example.com"})
+	c := NewOAMCache(g)
+
+	if _, ok := c.GetAsset("FQDN:www.example.com"); !ok {
+		t.Fatal("expected the asset to be found")
+	}
+	if _, ok := c.GetAsset("FQDN:missing.example.com"); ok {
+		t.Fatal("expected the lookup to miss")
+	}
+	if _, ok := c.GetAsset("FQDN:www.example.com"); !ok {
+		t.Fatal("expected the asset to be found again")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("expected 2 hits and 1 miss, got %+v", stats)
+	}
+}
+
+func TestMaybeFlushDropsCacheUnderPressureButKeepsDataRetrievable(t *testing.T) {
+	g := NewGraph()
+	c := NewOAMCache(g)
+
+	for i := 0; i < 10; i++ {
+		if err := c.UpsertAsset(types.FQDN{Name: fmt.Sprintf("host%d.example.com", i)}); err != nil {
+			t.Fatalf("UpsertAsset failed: %v", err)
+		}
+	}
+	if got := c.CachedAssetCount(); got != 10 {
+		t.Fatalf("expected 10 cached assets before flush, got %d", got)
+	}
+
+	c.SetMemoryThreshold(100)
+	c.heapAlloc = func() uint64 { return 200 }
+
+	if !c.MaybeFlush() {
+		t.Fatal("expected MaybeFlush to flush once the simulated heap exceeds the threshold")
+	}
+	if got := c.CachedAssetCount(); got != 0 {
+		t.Fatalf("expected the cache to be empty after flush, got %d", got)
+	}
+
+	if _, ok := c.GetAsset("FQDN:host0.example.com"); !ok {
+		t.Fatal("expected the asset to still be retrievable from the backing graph after flush")
+	}
+}
+
+func TestMaybeFlushIsNoOpBelowThreshold(t *testing.T) {
+	g := NewGraph()
+	c := NewOAMCache(g)
+
+	if err := c.UpsertAsset(types.FQDN{Name: "a.example.com"}); err != nil {
+		t.Fatalf("UpsertAsset failed: %v", err)
+	}
+
+	c.SetMemoryThreshold(1000)
+	c.heapAlloc = func() uint64 { return 10 }
+
+	if c.MaybeFlush() {
+		t.Fatal("expected no flush while under the configured threshold")
+	}
+	if got := c.CachedAssetCount(); got != 1 {
+		t.Fatalf("expected the cache to remain populated, got %d", got)
+	}
+}
+
+func TestSetRelationKeepsIndicesSorted(t *testing.T) {
+	g := NewGraph()
+	c := NewOAMCache(g)
+	apex := types.FQDN{Name: "example.com"}
+
+	for i := 9; i >= 0; i-- {
+		ip := types.IPAddress{Address: fmt.Sprintf("10.0.0.%d", i), Type: types.IPTypeIPv4}
+		if err := c.SetRelation(types.Relation{Type: "a_record", FromAsset: apex, ToAsset: ip}); err != nil {
+			t.Fatalf("SetRelation failed: %v", err)
+		}
+	}
+
+	rels := c.FindFrom(apex.Key())
+	if len(rels) != 10 {
+		t.Fatalf("expected 10 indexed relations, got %d", len(rels))
+	}
+	for i := 1; i < len(rels); i++ {
+		if rels[i-1].ToAsset.Key() > rels[i].ToAsset.Key() {
+			t.Fatalf("expected froms to stay sorted by ToAsset key, got %q before %q", rels[i-1].ToAsset.Key(), rels[i].ToAsset.Key())
+		}
+	}
+}
+
+// BenchmarkOAMCacheSetRelation measures per-insert cost against a large
+// number of relations sharing one FromAsset: with the old
+// append-then-full-resort approach this grows with n; the sorted-insert
+// version keeps it close to O(log n) per call.
+func BenchmarkOAMCacheSetRelation(b *testing.B) {
+	g := NewGraph()
+	c := NewOAMCache(g)
+	apex := types.FQDN{Name: "example.com"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ip := types.IPAddress{Address: fmt.Sprintf("10.%d.%d.%d", (i>>16)&0xff, (i>>8)&0xff, i&0xff), Type: types.IPTypeIPv4}
+		if err := c.SetRelation(types.Relation{Type: "a_record", FromAsset: apex, ToAsset: ip}); err != nil {
+			b.Fatalf("SetRelation failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestSinceBaselineReportsOnlyNewItems(t *testing.T) {
+	g := NewGraph()
+	g.UpsertAsset(types.FQDN{Name: "old.example.com"})
+
+	baseline := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	g.UpsertAsset(types.FQDN{Name: "new.example.com"})
+
+	diff := g.SinceBaseline(baseline)
+	if len(diff.NewAssets) != 1 || diff.NewAssets[0].Key() != "FQDN:new.example.com" {
+		t.Fatalf("expected only the post-baseline asset, got %v", diff.NewAssets)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import "encoding/json"
+
+// OAMNode is a single node in an OAM-conformant JSON graph export.
+type OAMNode struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// OAMEdge is a typed edge connecting two OAMNode IDs.
+type OAMEdge struct {
+	Type string `json:"type"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// OAMGraph is an open-asset-model-conformant graph: typed nodes and typed
+// edges, the format the wider Amass ecosystem's tooling consumes.
+type OAMGraph struct {
+	Nodes []OAMNode `json:"nodes"`
+	Edges []OAMEdge `json:"edges"`
+}
+
+// ExportOAM renders g as an OAMGraph, reusing the same Assets/Relations
+// query surface the rest of the API queries against, so this stays in
+// sync with the graph's actual contents rather than duplicating storage
+// access.
+func (g *Graph) ExportOAM() OAMGraph {
+	assets := g.Assets("")
+	relations := g.Relations()
+
+	nodes := make([]OAMNode, 0, len(assets))
+	for _, a := range assets {
+		nodes = append(nodes, OAMNode{
+			ID:         a.Key(),
+			Type:       string(a.AssetType()),
+			Properties: assetProperties(a),
+		})
+	}
+	edges := make([]OAMEdge, 0, len(relations))
+	for _, rel := range relations {
+		edges = append(edges, OAMEdge{Type: rel.Type, From: rel.FromAsset.Key(), To: rel.ToAsset.Key()})
+	}
+	return OAMGraph{Nodes: nodes, Edges: edges}
+}
+
+// assetProperties reflects an asset's own JSON tags into a generic map, so
+// the export doesn't need a hand-written case for every asset type; it
+// stays correct as new asset types are added as long as they carry JSON
+// tags like the rest of the package does.
+func assetProperties(a interface{}) map[string]interface{} {
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return nil
+	}
+	var props map[string]interface{}
+	if err := json.Unmarshal(raw, &props); err != nil {
+		return nil
+	}
+	return props
+}
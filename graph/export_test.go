@@ -0,0 +1,55 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestExportOAMProducesNodesAndEdgesForEveryAssetAndRelation(t *testing.T) {
+	g := NewGraph()
+	apex := types.FQDN{Name: "example.com"}
+	ip := types.IPAddress{Address: "198.51.100.7", Type: types.IPTypeIPv4}
+
+	if err := g.UpsertAsset(apex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.UpsertAsset(ip); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.SetRelation(types.Relation{Type: "a_record", FromAsset: apex, ToAsset: ip}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	oam := g.ExportOAM()
+	if len(oam.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(oam.Nodes))
+	}
+	if len(oam.Edges) != 1 {
+		t.Fatalf("expected 1 edge, got %d", len(oam.Edges))
+	}
+
+	var sawFQDN, sawIP bool
+	for _, n := range oam.Nodes {
+		switch n.Type {
+		case string(types.AssetFQDN):
+			sawFQDN = true
+			if n.Properties["name"] != "example.com" {
+				t.Fatalf("expected the FQDN node's properties to carry its name, got %v", n.Properties)
+			}
+		case string(types.AssetIPAddress):
+			sawIP = true
+		}
+	}
+	if !sawFQDN || !sawIP {
+		t.Fatalf("expected both an FQDN and an IPAddress node, got %+v", oam.Nodes)
+	}
+
+	edge := oam.Edges[0]
+	if edge.Type != "a_record" || edge.From != apex.Key() || edge.To != ip.Key() {
+		t.Fatalf("unexpected edge: %+v", edge)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// exportFilenames maps an AssetType to the file name ExportFiles groups
+// its values under. An asset type missing from this map still gets a
+// file, named from its AssetType directly, so introducing a new asset
+// type never silently drops it from the export.
+var exportFilenames = map[types.AssetType]string{
+	types.AssetFQDN:         "fqdns.txt",
+	types.AssetIPAddress:    "ips.txt",
+	types.AssetASN:          "asns.txt",
+	types.AssetNetblock:     "netblocks.txt",
+	types.AssetEmailAddress: "emails.txt",
+	types.AssetPerson:       "people.txt",
+	types.AssetService:      "services.txt",
+	types.AssetOrganization: "organizations.txt",
+	types.AssetPhone:        "phones.txt",
+}
+
+// ExportFiles writes one text file per asset type present in g into dir,
+// each holding that type's display values deduplicated and sorted, e.g.
+// fqdns.txt, ips.txt, netblocks.txt, instead of the one combined export
+// blob analysts otherwise have to split themselves. It reuses Assets, the
+// same query surface the rest of the export/API layer uses, so it can't
+// drift out of sync with what ExportOAM reports. It returns the number
+// of entries written per file name.
+func (g *Graph) ExportFiles(dir string) (map[string]int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("graph: failed to create export directory %q: %w", dir, err)
+	}
+
+	byFile := make(map[string]map[string]bool)
+	for _, a := range g.Assets("") {
+		value := assetDisplayValue(a)
+		if value == "" {
+			continue
+		}
+		name := exportFileName(a.AssetType())
+		if byFile[name] == nil {
+			byFile[name] = make(map[string]bool)
+		}
+		byFile[name][value] = true
+	}
+
+	counts := make(map[string]int, len(byFile))
+	for name, values := range byFile {
+		sorted := make([]string, 0, len(values))
+		for v := range values {
+			sorted = append(sorted, v)
+		}
+		sort.Strings(sorted)
+
+		content := strings.Join(sorted, "\n")
+		if len(sorted) > 0 {
+			content += "\n"
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return nil, fmt.Errorf("graph: failed to write %q: %w", name, err)
+		}
+		counts[name] = len(sorted)
+	}
+	return counts, nil
+}
+
+// exportFileName returns the file ExportFiles groups t's assets under.
+func exportFileName(t types.AssetType) string {
+	if name, ok := exportFilenames[t]; ok {
+		return name
+	}
+	return strings.ToLower(string(t)) + ".txt"
+}
+
+// assetDisplayValue extracts the human-readable value ExportFiles writes
+// for a, e.g. an FQDN's Name or an IPAddress's Address, falling back to
+// Key() for asset types without a specific case so a new asset type is
+// still exported meaningfully rather than silently skipped.
+func assetDisplayValue(a types.Asset) string {
+	switch v := a.(type) {
+	case types.FQDN:
+		return v.Name
+	case types.IPAddress:
+		return v.Address
+	case types.ASN:
+		return fmt.Sprintf("AS%d", v.Number)
+	case types.Service:
+		return fmt.Sprintf("%s:%d/%s", v.Address, v.Port, v.Protocol)
+	default:
+		return a.Key()
+	}
+}
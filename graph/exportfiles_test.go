@@ -0,0 +1,58 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestExportFilesWritesOneDeduplicatedSortedFilePerAssetType(t *testing.T) {
+	g := NewGraph()
+
+	g.UpsertAsset(types.FQDN{Name: "www.example.com"})
+	g.UpsertAsset(types.FQDN{Name: "api.example.com"})
+	g.UpsertAsset(types.FQDN{Name: "api.example.com"}) // duplicate, should collapse
+	g.UpsertAsset(types.IPAddress{Address: "198.51.100.7", Type: types.IPTypeIPv4})
+	g.UpsertAsset(types.ASN{Number: 64500})
+
+	dir := t.TempDir()
+	counts, err := g.ExportFiles(dir)
+	if err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+
+	if counts["fqdns.txt"] != 2 {
+		t.Fatalf("expected 2 deduplicated fqdns, got %d", counts["fqdns.txt"])
+	}
+	assertFileContents(t, filepath.Join(dir, "fqdns.txt"), "api.example.com\nwww.example.com\n")
+	assertFileContents(t, filepath.Join(dir, "ips.txt"), "198.51.100.7\n")
+	assertFileContents(t, filepath.Join(dir, "asns.txt"), "AS64500\n")
+}
+
+func TestExportFilesWritesServicesAsAddressPortProtocol(t *testing.T) {
+	g := NewGraph()
+	g.UpsertAsset(types.Service{Address: "198.51.100.7", Port: 443, Protocol: "tcp"})
+
+	dir := t.TempDir()
+	if _, err := g.ExportFiles(dir); err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+
+	assertFileContents(t, filepath.Join(dir, "services.txt"), "198.51.100.7:443/tcp\n")
+}
+
+func assertFileContents(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected %s to exist: %v", path, err)
+	}
+	if string(got) != want {
+		t.Fatalf("expected %s to contain %q, got %q", path, want, string(got))
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package graph stores the assets and relations discovered during a
+// session and provides the query surface plugins and the API use to read
+// them back.
+package graph
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// ErrReadOnly is returned by every write method on a Graph opened via
+// NewReadOnlyGraph.
+var ErrReadOnly = errors.New("graph: graph is read-only")
+
+// node wraps a stored asset with the timestamps it was first and most
+// recently confirmed, so stale entries can be identified and expired and
+// incremental scans can report only what's new since a baseline.
+type node struct {
+	asset     types.Asset
+	createdAt time.Time
+	lastSeen  time.Time
+}
+
+// Graph holds the assets and relations discovered so far for a single
+// session, kept in memory and safe for concurrent use by plugins running
+// in parallel.
+type Graph struct {
+	mu        sync.RWMutex
+	assets    map[string]*node
+	relations []types.Relation
+	readOnly  bool
+}
+
+// NewGraph returns an empty Graph.
+func NewGraph() *Graph {
+	return &Graph{assets: make(map[string]*node)}
+}
+
+// NewReadOnlyGraph returns a snapshot of source that rejects every write.
+// It's for reporting/analysis workflows that want to query a completed
+// scan without risk of an accidental write mutating the results they're
+// supposed to be reading.
+func NewReadOnlyGraph(source *Graph) *Graph {
+	source.mu.RLock()
+	defer source.mu.RUnlock()
+
+	assets := make(map[string]*node, len(source.assets))
+	for key, n := range source.assets {
+		cp := *n
+		assets[key] = &cp
+	}
+	return &Graph{
+		assets:    assets,
+		relations: append([]types.Relation(nil), source.relations...),
+		readOnly:  true,
+	}
+}
+
+// UpsertAsset stores a, replacing any existing asset with the same Key and
+// refreshing its last-seen timestamp. The original createdAt is preserved
+// across re-upserts of the same asset. It returns ErrReadOnly without
+// modifying anything if the Graph was opened via NewReadOnlyGraph.
+func (g *Graph) UpsertAsset(a types.Asset) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.readOnly {
+		return ErrReadOnly
+	}
+
+	now := time.Now()
+	created := now
+	if existing, ok := g.assets[a.Key()]; ok {
+		created = existing.createdAt
+	}
+	g.assets[a.Key()] = &node{asset: a, createdAt: created, lastSeen: now}
+	return nil
+}
+
+// UpsertAddress parses raw as an IP address, canonicalizes it (flattening
+// 4-in-6 mapped forms and normalizing case), and stores the resulting
+// IPAddress asset. Routing every IP-producing plugin through this instead
+// of constructing types.IPAddress directly guarantees the same host never
+// yields two distinct assets due to representation differences upstream.
+func (g *Graph) UpsertAddress(raw string) (types.IPAddress, bool) {
+	ip, ok := types.NewIPAddress(raw)
+	if !ok {
+		return types.IPAddress{}, false
+	}
+	if err := g.UpsertAsset(ip); err != nil {
+		return types.IPAddress{}, false
+	}
+	return ip, true
+}
+
+// SetRelation records that a Relation exists between two already-stored
+// assets. It returns ErrReadOnly without modifying anything if the Graph
+// was opened via NewReadOnlyGraph.
+func (g *Graph) SetRelation(rel types.Relation) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.readOnly {
+		return ErrReadOnly
+	}
+	g.relations = append(g.relations, rel)
+	return nil
+}
+
+// Assets returns every stored asset, optionally filtered to a single
+// AssetType. Passing an empty AssetType returns everything.
+func (g *Graph) Assets(t types.AssetType) []types.Asset {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	out := make([]types.Asset, 0, len(g.assets))
+	for _, n := range g.assets {
+		if t == "" || n.asset.AssetType() == t {
+			out = append(out, n.asset)
+		}
+	}
+	return out
+}
+
+// Relations returns every relation recorded in the graph.
+func (g *Graph) Relations() []types.Relation {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	out := make([]types.Relation, len(g.relations))
+	copy(out, g.relations)
+	return out
+}
+
+// GetAsset returns the asset stored under key, if any.
+func (g *Graph) GetAsset(key string) (types.Asset, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	n, ok := g.assets[key]
+	if !ok {
+		return nil, false
+	}
+	return n.asset, true
+}
+
+// ServicesForAddress returns every Service asset related to the asset
+// stored under addressKey (an IPAddress or FQDN's Key()), the accessor
+// scanning plugins and reports use to look up the banners captured for a
+// host without walking Relations by hand.
+func (g *Graph) ServicesForAddress(addressKey string) []types.Service {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var out []types.Service
+	for _, rel := range g.relations {
+		if rel.FromAsset.Key() != addressKey {
+			continue
+		}
+		if svc, ok := rel.ToAsset.(types.Service); ok {
+			out = append(out, svc)
+		}
+	}
+	return out
+}
+
+// IsReadOnly reports whether g rejects writes, i.e. was opened via
+// NewReadOnlyGraph.
+func (g *Graph) IsReadOnly() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.readOnly
+}
+
+// AssetCount returns the number of distinct assets currently stored.
+func (g *Graph) AssetCount() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.assets)
+}
+
+// DeleteAsset removes the asset identified by key, along with any relation
+// that references it, and reports whether an asset was actually removed.
+// It's always a no-op returning false on a Graph opened via
+// NewReadOnlyGraph.
+func (g *Graph) DeleteAsset(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.readOnly {
+		return false
+	}
+	if _, ok := g.assets[key]; !ok {
+		return false
+	}
+	delete(g.assets, key)
+
+	kept := g.relations[:0]
+	for _, rel := range g.relations {
+		if rel.FromAsset.Key() == key || rel.ToAsset.Key() == key {
+			continue
+		}
+		kept = append(kept, rel)
+	}
+	g.relations = kept
+	return true
+}
+
+// Diff reports the assets and relations first seen at or after baseline,
+// the report an incremental scan needs to show only what's new since a
+// previous run. It leverages the same createdAt/lastSeen bookkeeping
+// UpsertAsset already maintains.
+type Diff struct {
+	NewAssets    []types.Asset
+	NewRelations []types.Relation
+}
+
+// SinceBaseline computes a Diff of everything created at or after
+// baseline.
+func (g *Graph) SinceBaseline(baseline time.Time) Diff {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var d Diff
+	for _, n := range g.assets {
+		if !n.createdAt.Before(baseline) {
+			d.NewAssets = append(d.NewAssets, n.asset)
+		}
+	}
+	for _, rel := range g.relations {
+		if !rel.Timestamp.Before(baseline) {
+			d.NewRelations = append(d.NewRelations, rel)
+		}
+	}
+	return d
+}
+
+// ExpireStale deletes every asset whose last-seen timestamp is older than
+// olderThan and returns the keys that were removed. Sessions that run long
+// enough to revisit the same names benefit from pruning assets a source no
+// longer confirms, instead of accumulating them forever.
+func (g *Graph) ExpireStale(olderThan time.Duration) []string {
+	cutoff := time.Now().Add(-olderThan)
+
+	g.mu.Lock()
+	var stale []string
+	for key, n := range g.assets {
+		if n.lastSeen.Before(cutoff) {
+			stale = append(stale, key)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, key := range stale {
+		g.DeleteAsset(key)
+	}
+	return stale
+}
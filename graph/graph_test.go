@@ -0,0 +1,61 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestExpireStaleRemovesOldAssetsAndTheirRelations(t *testing.T) {
+	g := NewGraph()
+
+	fqdn := types.FQDN{Name: "old.example.com"}
+	ip := types.IPAddress{Address: "1.2.3.4", Type: "IPv4"}
+	g.UpsertAsset(fqdn)
+	g.UpsertAsset(ip)
+	g.SetRelation(types.Relation{Type: "a_record", FromAsset: fqdn, ToAsset: ip})
+
+	// Force the FQDN to look stale without waiting in real time.
+	g.mu.Lock()
+	g.assets[fqdn.Key()].lastSeen = time.Now().Add(-2 * time.Hour)
+	g.mu.Unlock()
+
+	removed := g.ExpireStale(time.Hour)
+	if len(removed) != 1 || removed[0] != fqdn.Key() {
+		t.Fatalf("expected only %q to expire, got %v", fqdn.Key(), removed)
+	}
+	if g.AssetCount() != 1 {
+		t.Fatalf("expected 1 remaining asset, got %d", g.AssetCount())
+	}
+	if len(g.Relations()) != 0 {
+		t.Fatalf("expected the relation referencing the expired asset to be removed too")
+	}
+}
+
+func TestServicesForAddressReturnsRelatedServiceAssets(t *testing.T) {
+	g := NewGraph()
+
+	ip := types.IPAddress{Address: "198.51.100.7", Type: "IPv4"}
+	svc := types.Service{Address: ip.Address, Port: 443, Protocol: "tcp", Banner: "nginx/1.25.0"}
+	other := types.IPAddress{Address: "203.0.113.5", Type: "IPv4"}
+
+	g.UpsertAsset(ip)
+	g.UpsertAsset(svc)
+	g.UpsertAsset(other)
+	if err := g.SetRelation(types.Relation{Type: "has_service", FromAsset: ip, ToAsset: svc}); err != nil {
+		t.Fatalf("SetRelation failed: %v", err)
+	}
+
+	services := g.ServicesForAddress(ip.Key())
+	if len(services) != 1 || services[0].Port != 443 || services[0].Banner != "nginx/1.25.0" {
+		t.Fatalf("unexpected services for %s: %+v", ip.Key(), services)
+	}
+
+	if got := g.ServicesForAddress(other.Key()); len(got) != 0 {
+		t.Fatalf("expected no services for an unrelated address, got %+v", got)
+	}
+}
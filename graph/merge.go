@@ -0,0 +1,73 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"context"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// Merge copies every asset and relation from src into dst, deduplicating
+// assets by Key and relations by (Type, FromAsset, ToAsset). This lets an
+// analyst combine, say, a passive scan's graph and a later active scan's
+// graph into one for reporting, without producing duplicate nodes or
+// relations for what both runs independently rediscovered.
+//
+// When an asset exists in both graphs, dst keeps its own asset value but
+// widens its bookkeeping to the union of the two runs: createdAt becomes
+// the earlier of the two (the asset was known that far back), and lastSeen
+// becomes the later (the asset was most recently confirmed then).
+func Merge(ctx context.Context, dst, src *Graph) error {
+	src.mu.RLock()
+	nodes := make([]*node, 0, len(src.assets))
+	for _, n := range src.assets {
+		nodes = append(nodes, n)
+	}
+	rels := append([]types.Relation(nil), src.relations...)
+	src.mu.RUnlock()
+
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+
+	for _, n := range nodes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key := n.asset.Key()
+		if existing, ok := dst.assets[key]; ok {
+			if n.createdAt.Before(existing.createdAt) {
+				existing.createdAt = n.createdAt
+			}
+			if n.lastSeen.After(existing.lastSeen) {
+				existing.lastSeen = n.lastSeen
+			}
+			continue
+		}
+		cp := *n
+		dst.assets[key] = &cp
+	}
+
+	seen := make(map[string]bool, len(dst.relations))
+	for _, r := range dst.relations {
+		seen[relationKey(r)] = true
+	}
+	for _, r := range rels {
+		k := relationKey(r)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		dst.relations = append(dst.relations, r)
+	}
+	return nil
+}
+
+// relationKey identifies a relation for dedup purposes, ignoring its
+// timestamp so the same relation rediscovered on a later run merges into
+// one entry instead of accumulating a copy per run.
+func relationKey(r types.Relation) string {
+	return r.Type + "|" + r.FromAsset.Key() + "|" + r.ToAsset.Key()
+}
@@ -0,0 +1,45 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestMergeDedupsAssetsAndRelations(t *testing.T) {
+	dst := NewGraph()
+	dst.UpsertAsset(types.FQDN{Name: "www.example.com"})
+	dst.UpsertAsset(types.IPAddress{Address: "198.51.100.7", Type: "IPv4"})
+	dst.SetRelation(types.Relation{
+		Type: "a_record", FromAsset: types.FQDN{Name: "www.example.com"},
+		ToAsset: types.IPAddress{Address: "198.51.100.7", Type: "IPv4"}, Timestamp: time.Now(),
+	})
+
+	src := NewGraph()
+	src.UpsertAsset(types.FQDN{Name: "www.example.com"}) // overlaps with dst
+	src.UpsertAsset(types.FQDN{Name: "api.example.com"}) // new
+	src.SetRelation(types.Relation{
+		Type: "a_record", FromAsset: types.FQDN{Name: "www.example.com"},
+		ToAsset: types.IPAddress{Address: "198.51.100.7", Type: "IPv4"}, Timestamp: time.Now(),
+	}) // duplicate of dst's relation
+	src.SetRelation(types.Relation{
+		Type: "a_record", FromAsset: types.FQDN{Name: "api.example.com"},
+		ToAsset: types.IPAddress{Address: "198.51.100.7", Type: "IPv4"}, Timestamp: time.Now(),
+	}) // new
+
+	if err := Merge(context.Background(), dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := dst.AssetCount(); got != 3 {
+		t.Fatalf("expected 3 distinct assets after merge, got %d", got)
+	}
+	if got := len(dst.Relations()); got != 2 {
+		t.Fatalf("expected 2 distinct relations after merge, got %d", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"log/slog"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// Repository is anything that accepts discovered assets and relations.
+// Both Graph and MultiGraph implement it, so callers that only need to
+// write don't care whether they're writing to one store or several.
+type Repository interface {
+	UpsertAsset(types.Asset) error
+	SetRelation(types.Relation) error
+}
+
+// MultiGraph fans writes out to a primary Graph and zero or more secondary
+// Graphs, the concrete case being a session configured with
+// config.DatabaseConfig.GraphDBs to mirror results across databases (e.g.
+// local SQLite plus a central Postgres). A secondary that fails to accept
+// a write is logged and otherwise ignored, since the primary already holds
+// the data of record; only a primary failure is returned to the caller.
+type MultiGraph struct {
+	primary     *Graph
+	secondaries []*Graph
+	log         *slog.Logger
+}
+
+// NewMultiGraph returns a MultiGraph that writes to primary and mirrors
+// each write to secondaries.
+func NewMultiGraph(primary *Graph, secondaries ...*Graph) *MultiGraph {
+	return &MultiGraph{primary: primary, secondaries: secondaries, log: slog.Default()}
+}
+
+// UpsertAsset implements Repository.
+func (m *MultiGraph) UpsertAsset(a types.Asset) error {
+	if err := m.primary.UpsertAsset(a); err != nil {
+		return err
+	}
+	for _, s := range m.secondaries {
+		if err := s.UpsertAsset(a); err != nil {
+			m.log.Warn("graph: secondary database rejected a write", "asset", a.Key(), "error", err)
+		}
+	}
+	return nil
+}
+
+// SetRelation implements Repository.
+func (m *MultiGraph) SetRelation(rel types.Relation) error {
+	if err := m.primary.SetRelation(rel); err != nil {
+		return err
+	}
+	for _, s := range m.secondaries {
+		if err := s.SetRelation(rel); err != nil {
+			m.log.Warn("graph: secondary database rejected a write", "relation", rel.Type, "error", err)
+		}
+	}
+	return nil
+}
+
+// Primary returns the underlying primary Graph. Reads always go through it
+// directly rather than fanning out, since it's the data of record.
+func (m *MultiGraph) Primary() *Graph {
+	return m.primary
+}
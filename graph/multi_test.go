@@ -0,0 +1,40 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestMultiGraphMirrorsWritesToBothDatabases(t *testing.T) {
+	primary := NewGraph()
+	secondary := NewGraph()
+	m := NewMultiGraph(primary, secondary)
+
+	if err := m.UpsertAsset(types.FQDN{Name: "www.example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if primary.AssetCount() != 1 {
+		t.Fatalf("expected the primary to receive the write")
+	}
+	if secondary.AssetCount() != 1 {
+		t.Fatalf("expected the secondary to receive the write")
+	}
+}
+
+func TestMultiGraphToleratesSecondaryFailure(t *testing.T) {
+	primary := NewGraph()
+	deadSecondary := NewReadOnlyGraph(NewGraph()) // simulates an unwritable secondary
+	m := NewMultiGraph(primary, deadSecondary)
+
+	if err := m.UpsertAsset(types.FQDN{Name: "www.example.com"}); err != nil {
+		t.Fatalf("expected the primary's success to mask the secondary's failure, got %v", err)
+	}
+	if primary.AssetCount() != 1 {
+		t.Fatalf("expected the primary to still receive the write")
+	}
+}
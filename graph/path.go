@@ -0,0 +1,53 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"context"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// FollowPath traverses relationTypes in order, starting from start, and
+// returns the distinct endpoint assets reached after following every hop.
+// At each step it moves from every asset currently held to every ToAsset
+// of a relation of the current relationType whose FromAsset matches, so
+// e.g. FollowPath(ctx, fqdn, []string{"a_record", "contains", "announces"})
+// answers "which ASNs does this FQDN ultimately resolve into," fanning out
+// across every matching relation at each hop rather than following a
+// single chain. A relationType with no matching relations at any step
+// empties the frontier and FollowPath returns an empty slice.
+func FollowPath(ctx context.Context, g *Graph, start types.Asset, relationTypes []string) ([]types.Asset, error) {
+	g.mu.RLock()
+	rels := append([]types.Relation(nil), g.relations...)
+	g.mu.RUnlock()
+
+	frontier := map[string]types.Asset{start.Key(): start}
+	for _, relType := range relationTypes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(frontier) == 0 {
+			break
+		}
+
+		next := make(map[string]types.Asset)
+		for _, rel := range rels {
+			if rel.Type != relType {
+				continue
+			}
+			if _, ok := frontier[rel.FromAsset.Key()]; !ok {
+				continue
+			}
+			next[rel.ToAsset.Key()] = rel.ToAsset
+		}
+		frontier = next
+	}
+
+	out := make([]types.Asset, 0, len(frontier))
+	for _, a := range frontier {
+		out = append(out, a)
+	}
+	return out, nil
+}
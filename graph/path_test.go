@@ -0,0 +1,108 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// testNetblock stands in for a Netblock asset: this tree declares
+// types.AssetNetblock as an AssetType but doesn't yet have a concrete
+// Netblock struct, so this minimal Asset implementation exercises the
+// a_record/contains/announces path shape without inventing a real one.
+type testNetblock struct {
+	cidr string
+}
+
+func (n testNetblock) Key() string                { return "Netblock:" + n.cidr }
+func (n testNetblock) AssetType() types.AssetType { return types.AssetNetblock }
+
+// createPathAssets builds a small FQDN --a_record--> IPAddress
+// --contains--> Netblock --announces--> ASN graph, plus an unrelated FQDN
+// whose IP is announced by a different ASN, so FollowPath must not
+// wander into it.
+func createPathAssets(t *testing.T) (*Graph, types.FQDN, types.ASN) {
+	t.Helper()
+	g := NewGraph()
+
+	fqdn := types.FQDN{Name: "www.example.com"}
+	ip := types.IPAddress{Address: "198.51.100.7", Type: "IPv4"}
+	nb := testNetblock{cidr: "198.51.100.0/24"}
+	asn := types.ASN{Number: 64500}
+
+	other := types.FQDN{Name: "other.example.com"}
+	otherIP := types.IPAddress{Address: "203.0.113.9", Type: "IPv4"}
+	otherNB := testNetblock{cidr: "203.0.113.0/24"}
+	otherASN := types.ASN{Number: 64501}
+
+	for _, a := range []types.Asset{fqdn, ip, nb, asn, other, otherIP, otherNB, otherASN} {
+		if err := g.UpsertAsset(a); err != nil {
+			t.Fatalf("UpsertAsset(%v): %v", a, err)
+		}
+	}
+
+	for _, rel := range []types.Relation{
+		{Type: "a_record", FromAsset: fqdn, ToAsset: ip},
+		{Type: "contains", FromAsset: ip, ToAsset: nb},
+		{Type: "announces", FromAsset: nb, ToAsset: asn},
+		{Type: "a_record", FromAsset: other, ToAsset: otherIP},
+		{Type: "contains", FromAsset: otherIP, ToAsset: otherNB},
+		{Type: "announces", FromAsset: otherNB, ToAsset: otherASN},
+	} {
+		if err := g.SetRelation(rel); err != nil {
+			t.Fatalf("SetRelation(%v): %v", rel, err)
+		}
+	}
+
+	return g, fqdn, asn
+}
+
+// TestFollowPathTraversesARecordContainsAnnounces confirms FollowPath
+// walks a multi-hop relation-type sequence from a starting FQDN to the
+// ASN it ultimately announces from, ignoring an unrelated chain.
+func TestFollowPathTraversesARecordContainsAnnounces(t *testing.T) {
+	g, fqdn, wantASN := createPathAssets(t)
+
+	got, err := FollowPath(context.Background(), g, fqdn, []string{"a_record", "contains", "announces"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected exactly 1 endpoint, got %d: %v", len(got), got)
+	}
+	if got[0].Key() != wantASN.Key() {
+		t.Fatalf("expected endpoint %s, got %s", wantASN.Key(), got[0].Key())
+	}
+}
+
+// TestFollowPathReturnsEmptyForUnmatchedRelationType confirms a
+// relationType with no matching relations at some hop empties the
+// frontier rather than falling back to the previous hop's assets.
+func TestFollowPathReturnsEmptyForUnmatchedRelationType(t *testing.T) {
+	g, fqdn, _ := createPathAssets(t)
+
+	got, err := FollowPath(context.Background(), g, fqdn, []string{"a_record", "cname_record"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no endpoints, got %v", got)
+	}
+}
+
+// TestFollowPathHonorsContextCancellation confirms an already-canceled
+// context short-circuits the traversal instead of walking every relation.
+func TestFollowPathHonorsContextCancellation(t *testing.T) {
+	g, fqdn, _ := createPathAssets(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FollowPath(ctx, g, fqdn, []string{"a_record"}); err == nil {
+		t.Fatal("expected an error from an already-canceled context")
+	}
+}
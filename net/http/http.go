@@ -0,0 +1,327 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package http provides the engine's shared HTTP client used by plugins to
+// fetch web pages, with sane timeouts and context cancellation baked in.
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// DefaultMaxBodySize is applied when RequestWebPage is called without an
+// explicit limit: generous enough for virtually every legitimate API
+// response, but finite so a hostile or misbehaving endpoint streaming
+// gigabytes can't OOM the engine.
+const DefaultMaxBodySize = 50 * 1024 * 1024 // 50 MiB
+
+// ErrResponseTooLarge is returned when a fetched body exceeds the
+// configured maximum size.
+var ErrResponseTooLarge = errors.New("http: response body exceeded the configured maximum size")
+
+// ErrUnexpectedStatus is returned when a response's status code isn't 2xx
+// and isn't mapped to StatusActionEmpty or StatusActionRetry by the
+// caller's StatusPolicy.
+var ErrUnexpectedStatus = errors.New("http: unexpected response status code")
+
+// ErrRetriesExhausted is returned once a StatusActionRetry response has
+// been retried MaxRetries times without ever succeeding.
+var ErrRetriesExhausted = errors.New("http: retries exhausted waiting for a non-retry status")
+
+// StatusAction tells RequestWebPageWithConfig how to treat a response
+// whose status code isn't a plain 2xx success. Different sources disagree
+// on what a given status means: one API's 404 is "no results" while
+// another's is a real error, and a 202 commonly means "processing, try
+// again shortly" rather than either.
+type StatusAction int
+
+const (
+	// StatusActionError treats the status as a failure, returning
+	// ErrUnexpectedStatus. This is the default for any status not listed
+	// in a StatusPolicy.
+	StatusActionError StatusAction = iota
+	// StatusActionEmpty treats the status as a successful empty result,
+	// e.g. a source that reports 404 for "no records found" rather than
+	// a real error condition.
+	StatusActionEmpty
+	// StatusActionRetry re-issues the request after RetryDelay, up to
+	// MaxRetries times, e.g. a source that reports 202 while a report is
+	// still being generated asynchronously.
+	StatusActionRetry
+)
+
+// StatusPolicy maps a response status code to the action RequestWebPage
+// should take for it. Status codes absent from the map fall back to the
+// ordinary default: 2xx succeeds, anything else is StatusActionError.
+type StatusPolicy map[int]StatusAction
+
+// RequestConfig configures a single RequestWebPageWithConfig call.
+type RequestConfig struct {
+	Headers map[string]string
+	// MaxBodySize bounds the response body, in bytes. Zero selects
+	// DefaultMaxBodySize; a negative value disables the limit.
+	MaxBodySize int64
+	// StatusPolicy overrides how specific status codes are handled. A
+	// nil StatusPolicy is equivalent to the plain 2xx-succeeds default.
+	StatusPolicy StatusPolicy
+	// MaxRetries bounds how many times a StatusActionRetry response is
+	// retried before RequestWebPageWithConfig gives up with
+	// ErrRetriesExhausted.
+	MaxRetries int
+	// RetryDelay is slept, subject to ctx cancellation, between retry
+	// attempts.
+	RetryDelay time.Duration
+	// Budget, if set, is drawn from before every request this call
+	// issues (including retries), typically a session-level limiter
+	// shared with the DNS resolver pool so the combined outbound DNS and
+	// HTTP rate stays under a single configured ceiling instead of each
+	// protocol capping itself independently. Nil disables the cap.
+	Budget *support.RateLimiter
+}
+
+// RequestWebPage fetches url and returns its body as a string, bounded to
+// DefaultMaxBodySize. Unlike a bare http.Get, it honors ctx for both the
+// dial/handshake and the body read, so callers that cancel ctx (e.g.
+// because a session was killed) get a prompt return instead of leaving the
+// goroutine blocked on a slow or hung endpoint. Any non-2xx status is
+// reported as ErrUnexpectedStatus; use RequestWebPageWithConfig for
+// sources that need different handling.
+func RequestWebPage(ctx context.Context, url string, headers map[string]string) (string, error) {
+	return RequestWebPageMax(ctx, url, headers, DefaultMaxBodySize)
+}
+
+// RequestWebPageMax is RequestWebPage with an explicit maxBodySize, in
+// bytes. Zero selects DefaultMaxBodySize; a negative value disables the
+// limit.
+func RequestWebPageMax(ctx context.Context, url string, headers map[string]string, maxBodySize int64) (string, error) {
+	return RequestWebPageWithConfig(ctx, url, RequestConfig{Headers: headers, MaxBodySize: maxBodySize})
+}
+
+// PageResult is the outcome of a fetch that followed redirects: the
+// resolved body plus enough of the trail to let a caller inspect which
+// hosts the redirect chain passed through, e.g. to notice an in-scope
+// target hiding behind an out-of-scope vanity domain.
+type PageResult struct {
+	Body string
+	// FinalURL is the URL the request ultimately settled on after
+	// following every redirect.
+	FinalURL string
+	// RedirectChain lists every URL visited before FinalURL, in the
+	// order they were followed. Empty if the request wasn't redirected.
+	RedirectChain []string
+}
+
+// RequestWebPageWithRedirects behaves like RequestWebPageWithConfig, but
+// also reports the final URL and the chain of URLs visited to reach it,
+// instead of only the resolved body. It doesn't consult cfg.StatusPolicy,
+// MaxRetries or RetryDelay: any non-2xx final status is reported as
+// ErrUnexpectedStatus. Use RequestWebPageWithConfig for sources needing
+// per-status handling.
+func RequestWebPageWithRedirects(ctx context.Context, url string, cfg RequestConfig) (PageResult, error) {
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+
+	var chain []string
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			chain = append(chain, via[len(via)-1].URL.String())
+			if len(via) >= 10 {
+				return errors.New("http: stopped after 10 redirects")
+			}
+			return nil
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return PageResult{}, err
+	}
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if cfg.Budget != nil {
+		cfg.Budget.Wait(ctx)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return PageResult{}, err
+	}
+	defer resp.Body.Close()
+
+	result := PageResult{FinalURL: resp.Request.URL.String(), RedirectChain: chain}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize))
+		return result, fmt.Errorf("http: GET %s: %w", url, ErrUnexpectedStatus)
+	}
+
+	var reader io.Reader = resp.Body
+	limited := false
+	if maxBodySize > 0 {
+		reader = &limitedReadCloser{ReadCloser: resp.Body, remaining: maxBodySize}
+		limited = true
+	}
+	body, err := readAllWithContext(ctx, reader)
+	if limited && errors.Is(err, ErrResponseTooLarge) {
+		return result, fmt.Errorf("http: GET %s: %w", url, ErrResponseTooLarge)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.Body = string(body)
+	return result, nil
+}
+
+// RequestWebPageWithConfig fetches url per cfg, applying cfg.StatusPolicy
+// to decide how to treat a non-2xx response instead of always failing.
+func RequestWebPageWithConfig(ctx context.Context, url string, cfg RequestConfig) (string, error) {
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+
+	for attempt := 0; ; attempt++ {
+		if cfg.Budget != nil {
+			cfg.Budget.Wait(ctx)
+		}
+		body, action, err := fetchOnce(ctx, url, cfg.Headers, maxBodySize, cfg.StatusPolicy)
+		if err != nil {
+			return "", err
+		}
+
+		switch action {
+		case StatusActionEmpty:
+			return "", nil
+		case StatusActionRetry:
+			if attempt >= cfg.MaxRetries {
+				return "", fmt.Errorf("http: GET %s: %w", url, ErrRetriesExhausted)
+			}
+			if err := sleepWithContext(ctx, cfg.RetryDelay); err != nil {
+				return "", err
+			}
+			continue
+		case StatusActionError:
+			return "", fmt.Errorf("http: GET %s: %w", url, ErrUnexpectedStatus)
+		default:
+			return body, nil
+		}
+	}
+}
+
+// requestSuccess is the StatusAction reported for an ordinary 2xx
+// response, distinct from the exported actions since callers never
+// configure it directly.
+const requestSuccess StatusAction = -1
+
+func fetchOnce(ctx context.Context, url string, headers map[string]string, maxBodySize int64, policy StatusPolicy) (string, StatusAction, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", requestSuccess, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", requestSuccess, err
+	}
+	defer resp.Body.Close()
+
+	action, handled := policy[resp.StatusCode]
+	if !handled {
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			action = requestSuccess
+		} else {
+			action = StatusActionError
+		}
+	}
+	if action != requestSuccess {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize))
+		return "", action, nil
+	}
+
+	reader := resp.Body
+	limited := false
+	if maxBodySize > 0 {
+		reader = &limitedReadCloser{ReadCloser: resp.Body, remaining: maxBodySize}
+		limited = true
+	}
+
+	// Bound the read to ctx as well: http.NewRequestWithContext only
+	// cancels the round trip, not a slow reader on an already-established
+	// connection streaming the body a byte at a time.
+	body, err := readAllWithContext(ctx, reader)
+	if limited && errors.Is(err, ErrResponseTooLarge) {
+		return "", requestSuccess, fmt.Errorf("http: GET %s: %w", url, ErrResponseTooLarge)
+	}
+	if err != nil {
+		return "", requestSuccess, err
+	}
+	return string(body), requestSuccess, nil
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limitedReadCloser caps the number of bytes read from an underlying
+// io.ReadCloser, returning ErrResponseTooLarge instead of silently
+// truncating once the limit is exceeded.
+type limitedReadCloser struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func readAllWithContext(ctx context.Context, r io.Reader) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		data, err := io.ReadAll(r)
+		done <- result{data, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		return res.data, res.err
+	}
+}
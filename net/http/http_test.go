@@ -0,0 +1,203 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// testHangingHandler writes headers immediately but never completes the
+// body, simulating a slow endpoint that only ctx cancellation (not the
+// original request context) can unstick.
+func testHangingHandler(done <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		<-done
+	})
+}
+
+func TestRequestWebPageWithConfigTreats404AsEmpty(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	body, err := RequestWebPageWithConfig(context.Background(), ts.URL, RequestConfig{
+		StatusPolicy: StatusPolicy{http.StatusNotFound: StatusActionEmpty},
+	})
+	if err != nil {
+		t.Fatalf("expected a policy-mapped 404 to succeed, got: %v", err)
+	}
+	if body != "" {
+		t.Fatalf("expected an empty body, got %q", body)
+	}
+}
+
+func TestRequestWebPageWithConfigRetriesOn202ThenSucceeds(t *testing.T) {
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Write([]byte("done"))
+	}))
+	defer ts.Close()
+
+	body, err := RequestWebPageWithConfig(context.Background(), ts.URL, RequestConfig{
+		StatusPolicy: StatusPolicy{http.StatusAccepted: StatusActionRetry},
+		MaxRetries:   5,
+		RetryDelay:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("expected the retried request to eventually succeed, got: %v", err)
+	}
+	if body != "done" {
+		t.Fatalf("expected body %q, got %q", "done", body)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRequestWebPageWithConfigGivesUpAfterMaxRetries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	_, err := RequestWebPageWithConfig(context.Background(), ts.URL, RequestConfig{
+		StatusPolicy: StatusPolicy{http.StatusAccepted: StatusActionRetry},
+		MaxRetries:   2,
+		RetryDelay:   time.Millisecond,
+	})
+	if !errors.Is(err, ErrRetriesExhausted) {
+		t.Fatalf("expected ErrRetriesExhausted, got: %v", err)
+	}
+}
+
+func TestRequestWebPageReportsUnexpectedStatusByDefault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	_, err := RequestWebPage(context.Background(), ts.URL, nil)
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Fatalf("expected ErrUnexpectedStatus, got: %v", err)
+	}
+}
+
+func TestRequestWebPageReturnsPromptlyOnCancel(t *testing.T) {
+	blockUntilClosed := make(chan struct{})
+	ts := httptest.NewServer(testHangingHandler(blockUntilClosed))
+	defer ts.Close()
+	defer close(blockUntilClosed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := RequestWebPage(ctx, ts.URL, nil)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatalf("expected an error from a canceled request")
+	}
+	if elapsed > 2*time.Second {
+		t.Fatalf("RequestWebPage took too long to return after cancellation: %v", elapsed)
+	}
+}
+
+// TestRequestWebPageWithConfigDrawsFromSharedBudget confirms a configured
+// Budget is consulted for every request this call issues, so it can be
+// shared with the DNS resolver pool as one combined session-level rate
+// limit instead of each protocol capping itself independently.
+func TestRequestWebPageWithConfigDrawsFromSharedBudget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	budget := support.NewRateLimiter(1000)
+	if _, err := RequestWebPageWithConfig(context.Background(), ts.URL, RequestConfig{Budget: budget}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := budget.Consumed(); got != 1 {
+		t.Fatalf("expected the shared budget to record 1 consumed token, got %d", got)
+	}
+}
+
+// TestRequestWebPageWithRedirectsReportsFinalURLAndChain follows a
+// multi-hop redirect and asserts every intermediate URL and the final one
+// are reported, alongside the final page's body.
+func TestRequestWebPageWithRedirectsReportsFinalURLAndChain(t *testing.T) {
+	var final *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusFound)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/end", http.StatusFound)
+	})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("landed"))
+	})
+	final = httptest.NewServer(mux)
+	defer final.Close()
+
+	res, err := RequestWebPageWithRedirects(context.Background(), final.URL+"/start", RequestConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Body != "landed" {
+		t.Fatalf("expected the final page's body, got %q", res.Body)
+	}
+	if res.FinalURL != final.URL+"/end" {
+		t.Fatalf("expected FinalURL to be %s, got %s", final.URL+"/end", res.FinalURL)
+	}
+	wantChain := []string{final.URL + "/start", final.URL + "/middle"}
+	if len(res.RedirectChain) != len(wantChain) {
+		t.Fatalf("expected a 2-hop redirect chain, got %v", res.RedirectChain)
+	}
+	for i, want := range wantChain {
+		if res.RedirectChain[i] != want {
+			t.Fatalf("expected chain[%d] to be %s, got %s", i, want, res.RedirectChain[i])
+		}
+	}
+}
+
+// TestRequestWebPageWithRedirectsReportsNoChainWithoutRedirect confirms a
+// direct 200 response leaves RedirectChain empty and FinalURL equal to the
+// requested URL.
+func TestRequestWebPageWithRedirectsReportsNoChainWithoutRedirect(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	res, err := RequestWebPageWithRedirects(context.Background(), ts.URL, RequestConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.RedirectChain) != 0 {
+		t.Fatalf("expected no redirect chain, got %v", res.RedirectChain)
+	}
+	if res.FinalURL != ts.URL {
+		t.Fatalf("expected FinalURL to equal the requested URL, got %s", res.FinalURL)
+	}
+}
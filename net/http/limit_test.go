@@ -0,0 +1,34 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func streamingHandler(totalBytes int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunk := make([]byte, 4096)
+		for written := 0; written < totalBytes; written += len(chunk) {
+			w.Write(chunk)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	})
+}
+
+func TestRequestWebPageMaxBoundsOversizedResponse(t *testing.T) {
+	ts := httptest.NewServer(streamingHandler(1024 * 1024)) // stream 1 MiB
+	defer ts.Close()
+
+	_, err := RequestWebPageMax(context.Background(), ts.URL, nil, 1024) // limit to 1 KiB
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package bgptools looks up the ASN and netblock that owns an IP address
+// using a bgp.tools-style table.
+package bgptools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"sync"
+)
+
+// netblockRecord is one line of the bgp.tools table.
+type netblockRecord struct {
+	CIDR string `json:"CIDR"`
+	ASN  int    `json:"ASN"`
+	Name string `json:"Name"`
+}
+
+// netblock is a parsed, indexable netblockRecord.
+type netblock struct {
+	prefix netip.Prefix
+	asn    int
+	name   string
+}
+
+// NetblockTable answers "which netblock owns this IP" lookups against a
+// bgp.tools table. It used to be re-opened and line-scanned in full for
+// every lookup, making each query O(file) against a table hundreds of MB
+// large; instead, Load parses the table once into blocks sorted by prefix
+// address, and Lookup binary-searches that in-memory slice, so the file is
+// only ever read by Load.
+type NetblockTable struct {
+	mu     sync.RWMutex
+	blocks []netblock
+}
+
+// NewNetblockTable returns an empty table. Call Load before looking
+// anything up.
+func NewNetblockTable() *NetblockTable {
+	return &NetblockTable{}
+}
+
+// Load parses r as a bgp.tools JSONL table and replaces the table's
+// contents, blocking lookups only for the brief swap at the end, not for
+// the parse itself.
+func (t *NetblockTable) Load(r io.Reader) error {
+	var blocks []netblock
+
+	scanner := bufio.NewScanner(r)
+	// The table's lines can be long; grow the buffer well past bufio's
+	// 64KB default rather than truncating rows silently.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec netblockRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("bgptools: failed to parse table line: %w", err)
+		}
+		prefix, err := netip.ParsePrefix(rec.CIDR)
+		if err != nil {
+			continue
+		}
+		blocks = append(blocks, netblock{prefix: prefix, asn: rec.ASN, name: rec.Name})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("bgptools: failed to read table: %w", err)
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].prefix.Addr().Less(blocks[j].prefix.Addr())
+	})
+
+	t.mu.Lock()
+	t.blocks = blocks
+	t.mu.Unlock()
+	return nil
+}
+
+// Lookup returns the ASN and netblock name owning addr, or false if no
+// loaded block contains it.
+func (t *NetblockTable) Lookup(addr string) (asn int, name string, found bool) {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return 0, "", false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	// BGP allocation tables partition address space into non-overlapping
+	// blocks, so the only block that can contain ip is the last one
+	// starting at or before it; binary search finds that candidate
+	// directly instead of scanning the table.
+	i := sort.Search(len(t.blocks), func(i int) bool {
+		return t.blocks[i].prefix.Addr().Compare(ip) > 0
+	})
+	if i == 0 {
+		return 0, "", false
+	}
+	b := t.blocks[i-1]
+	if !b.prefix.Contains(ip) {
+		return 0, "", false
+	}
+	return b.asn, b.name, true
+}
@@ -0,0 +1,23 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package bgptools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// Provider adapts table into a support.ASNProvider, the first entry in an
+// IP→ASN fallback chain callers build with support.IPToASN.
+func Provider(table *NetblockTable) support.ASNProvider {
+	return func(ctx context.Context, ip string) (support.ASNResult, error) {
+		asn, name, found := table.Lookup(ip)
+		if !found {
+			return support.ASNResult{}, fmt.Errorf("bgptools: no netblock owns %s", ip)
+		}
+		return support.ASNResult{ASN: asn, Name: name, Source: "bgptools"}, nil
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package bgptools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProviderReturnsASNResultForOwningNetblock(t *testing.T) {
+	table := NewNetblockTable()
+	if err := table.Load(strings.NewReader(`{"CIDR":"198.51.100.0/24","ASN":64500,"Name":"EXAMPLE-NET"}`)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	res, err := Provider(table)(context.Background(), "198.51.100.42")
+	if err != nil {
+		t.Fatalf("Provider failed: %v", err)
+	}
+	if res.ASN != 64500 || res.Name != "EXAMPLE-NET" || res.Source != "bgptools" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestProviderReportsErrorWhenNoBlockOwnsIP(t *testing.T) {
+	table := NewNetblockTable()
+	if _, err := Provider(table)(context.Background(), "203.0.113.7"); err == nil {
+		t.Fatal("expected an error when no loaded block owns the address")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package bgptools
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WhoisRecord is whatever a bgp.tools whois query response line yields.
+// Real responses vary in how many fields they include, so any field here
+// may be its zero value rather than always being fully populated.
+type WhoisRecord struct {
+	ASN      int
+	CIDR     string
+	Registry string
+}
+
+// query is a bgp.tools whois response line, pipe-delimited in the order
+// ASN | IP | BGP Prefix | CC | Registry | Allocated | AS Name, though real
+// responses don't always carry every field. It used to require
+// len(fields) >= 7 and discard the whole line otherwise, throwing away an
+// ASN and CIDR it already had just because the allocation date or AS name
+// was missing. query instead extracts whatever of ASN, CIDR, and Registry
+// are present and reports the record incomplete rather than failing it
+// outright.
+func query(line string) (rec WhoisRecord, complete bool) {
+	fields := strings.Split(line, "|")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	if len(fields) > 0 {
+		if asn, err := strconv.Atoi(fields[0]); err == nil {
+			rec.ASN = asn
+		}
+	}
+	if len(fields) > 2 {
+		rec.CIDR = fields[2]
+	}
+	if len(fields) > 4 {
+		rec.Registry = fields[4]
+	}
+
+	complete = len(fields) >= 7
+	return rec, complete
+}
+
+// ParseWhoisRecord parses a single bgp.tools whois response line into a
+// WhoisRecord, tolerating any number of fields: it returns whatever of
+// ASN, CIDR, and Registry the line actually carries instead of failing
+// the whole record for a missing trailing field. ok is false only when
+// the line carries neither an ASN nor a CIDR, since a record with
+// neither is useless to a caller regardless of tolerance.
+func ParseWhoisRecord(line string) (rec WhoisRecord, ok bool) {
+	rec, _ = query(line)
+	return rec, rec.ASN != 0 || rec.CIDR != ""
+}
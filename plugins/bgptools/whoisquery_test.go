@@ -0,0 +1,48 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package bgptools
+
+import "testing"
+
+func TestParseWhoisRecordWithSevenFields(t *testing.T) {
+	rec, ok := ParseWhoisRecord("13335 | 1.1.1.1 | 1.1.1.0/24 | US | ARIN | 2018-01-01 | CLOUDFLARENET")
+	if !ok {
+		t.Fatal("expected a complete record to parse successfully")
+	}
+	if rec.ASN != 13335 || rec.CIDR != "1.1.1.0/24" || rec.Registry != "ARIN" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	if _, complete := query("13335 | 1.1.1.1 | 1.1.1.0/24 | US | ARIN | 2018-01-01 | CLOUDFLARENET"); !complete {
+		t.Fatal("expected a seven-field line to be reported complete")
+	}
+}
+
+func TestParseWhoisRecordWithSixFieldsMissingASName(t *testing.T) {
+	rec, ok := ParseWhoisRecord("13335 | 1.1.1.1 | 1.1.1.0/24 | US | ARIN | 2018-01-01")
+	if !ok {
+		t.Fatal("expected a partial record with ASN and CIDR to still parse")
+	}
+	if rec.ASN != 13335 || rec.CIDR != "1.1.1.0/24" || rec.Registry != "ARIN" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	if _, complete := query("13335 | 1.1.1.1 | 1.1.1.0/24 | US | ARIN | 2018-01-01"); complete {
+		t.Fatal("expected a six-field line to be reported incomplete")
+	}
+}
+
+func TestParseWhoisRecordWithFiveFieldsMissingAllocatedAndASName(t *testing.T) {
+	rec, ok := ParseWhoisRecord("13335 | 1.1.1.1 | 1.1.1.0/24 | US | ARIN")
+	if !ok {
+		t.Fatal("expected a partial record with ASN and CIDR to still parse")
+	}
+	if rec.ASN != 13335 || rec.CIDR != "1.1.1.0/24" || rec.Registry != "ARIN" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+}
+
+func TestParseWhoisRecordWithoutASNOrCIDRIsRejected(t *testing.T) {
+	if _, ok := ParseWhoisRecord("US | ARIN"); ok {
+		t.Fatal("expected a record without an ASN or CIDR to be rejected")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package cymru resolves an IP address to its announcing ASN using Team
+// Cymru's DNS-based whois interface. Querying origin.asn.cymru.com TXT
+// records over the existing resolver pool is far cheaper at scale than
+// opening a WHOIS socket per IP, making it a lightweight, rate-friendly
+// entry in an IP→ASN provider chain.
+package cymru
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// Provider looks up IP→ASN answers via Team Cymru's origin.asn.cymru.com
+// DNS TXT interface.
+type Provider struct {
+	// Query resolves name's TXT records, typically by way of the
+	// caller's resolver pool. It's the only network dependency, so tests
+	// can substitute a fake returning a captured Cymru TXT response.
+	Query func(ctx context.Context, name string) ([]string, error)
+}
+
+// NewProvider returns a Provider that resolves TXT records with query.
+func NewProvider(query func(ctx context.Context, name string) ([]string, error)) *Provider {
+	return &Provider{Query: query}
+}
+
+// Lookup resolves ip's announcing ASN via Team Cymru's DNS interface. It
+// satisfies support.ASNProvider, so it plugs directly into an
+// support.IPToASN fallback chain.
+func (p *Provider) Lookup(ctx context.Context, ip string) (support.ASNResult, error) {
+	name, err := originQueryName(ip)
+	if err != nil {
+		return support.ASNResult{}, fmt.Errorf("cymru: %w", err)
+	}
+
+	txts, err := p.Query(ctx, name)
+	if err != nil {
+		return support.ASNResult{}, fmt.Errorf("cymru: TXT query for %s failed: %w", name, err)
+	}
+	for _, txt := range txts {
+		if res, ok := parseOriginTXT(txt); ok {
+			res.Source = "cymru"
+			return res, nil
+		}
+	}
+	return support.ASNResult{}, fmt.Errorf("cymru: no usable answer in TXT records for %s", name)
+}
+
+// originQueryName reverses an IPv4 address's octets into the name Team
+// Cymru's origin service expects, e.g. 198.51.100.42 becomes
+// "42.100.51.198.origin.asn.cymru.com". IPv6 addresses are rejected with
+// an unsupported-address-family error.
+func originQueryName(ip string) (string, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP address %q: %w", ip, err)
+	}
+	if addr.Is4() {
+		octets := strings.Split(addr.String(), ".")
+		for i, j := 0, len(octets)-1; i < j; i, j = i+1, j-1 {
+			octets[i], octets[j] = octets[j], octets[i]
+		}
+		return strings.Join(octets, ".") + ".origin.asn.cymru.com", nil
+	}
+	return "", fmt.Errorf("unsupported address family for %q", ip)
+}
+
+// parseOriginTXT parses a Team Cymru origin TXT answer, pipe-delimited as
+// "ASN | prefix | CC | registry | allocated", into an ASNResult. The
+// origin service doesn't return the ASN's registered name, only bgptools
+// or RDAP do, so ASNResult.Name is left empty here. A multi-origin answer
+// (an IP announced by more than one ASN) lists several ASNs
+// space-separated in the first field; only the first is used, matching
+// how the historical bgptools table resolves overlapping announcements.
+func parseOriginTXT(txt string) (support.ASNResult, bool) {
+	fields := strings.Split(txt, "|")
+	if len(fields) < 2 {
+		return support.ASNResult{}, false
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	asnField := strings.Fields(fields[0])
+	if len(asnField) == 0 {
+		return support.ASNResult{}, false
+	}
+	asn, err := strconv.Atoi(asnField[0])
+	if err != nil {
+		return support.ASNResult{}, false
+	}
+
+	return support.ASNResult{
+		ASN:    asn,
+		Prefix: fields[1],
+	}, true
+}
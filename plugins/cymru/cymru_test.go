@@ -0,0 +1,76 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package cymru
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// capturedOriginResponse is a real-shaped Team Cymru origin.asn.cymru.com
+// TXT answer for 1.1.1.1: "ASN | BGP Prefix | CC | Registry | Allocated".
+const capturedOriginResponse = "13335 | 1.1.1.0/24 | US | arin | 2011-08-10"
+
+func TestLookupParsesCapturedOriginResponse(t *testing.T) {
+	p := NewProvider(func(ctx context.Context, name string) ([]string, error) {
+		if name != "1.1.1.1.origin.asn.cymru.com" {
+			t.Fatalf("unexpected query name: %s", name)
+		}
+		return []string{capturedOriginResponse}, nil
+	})
+
+	res, err := p.Lookup(context.Background(), "1.1.1.1")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if res.ASN != 13335 || res.Prefix != "1.1.1.0/24" || res.Source != "cymru" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestLookupUsesFirstASNFromMultiOriginAnswer(t *testing.T) {
+	p := NewProvider(func(ctx context.Context, name string) ([]string, error) {
+		return []string{"64500 64501 | 198.51.100.0/24 | US | arin | 2015-01-01"}, nil
+	})
+
+	res, err := p.Lookup(context.Background(), "198.51.100.42")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if res.ASN != 64500 {
+		t.Fatalf("expected the first ASN of a multi-origin answer, got %d", res.ASN)
+	}
+}
+
+func TestLookupReportsErrorWhenQueryFails(t *testing.T) {
+	p := NewProvider(func(ctx context.Context, name string) ([]string, error) {
+		return nil, errors.New("no route to resolver")
+	})
+
+	if _, err := p.Lookup(context.Background(), "203.0.113.7"); err == nil {
+		t.Fatal("expected an error when the TXT query fails")
+	}
+}
+
+func TestLookupReportsErrorForUnparsableAnswers(t *testing.T) {
+	p := NewProvider(func(ctx context.Context, name string) ([]string, error) {
+		return []string{"not a cymru answer"}, nil
+	})
+
+	if _, err := p.Lookup(context.Background(), "203.0.113.7"); err == nil {
+		t.Fatal("expected an error when no TXT record parses")
+	}
+}
+
+func TestLookupReportsErrorForInvalidAddress(t *testing.T) {
+	p := NewProvider(func(ctx context.Context, name string) ([]string, error) {
+		t.Fatal("Query should not be called for an unparseable address")
+		return nil, nil
+	})
+
+	if _, err := p.Lookup(context.Background(), "not-an-ip"); err == nil {
+		t.Fatal("expected an error for an invalid IP address")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+// DataExclusion drops DNS record data matching an operator's configured
+// suffix or regex patterns before it becomes a graph asset. Reverse DNS
+// and passive sources frequently return generic provider PTRs (e.g.
+// "*.compute.amazonaws.com") or ISP rDNS that pollute results with names
+// that are technically correct but never useful to an analyst.
+type DataExclusion struct {
+	suffixes []string
+	patterns []*regexp.Regexp
+}
+
+// NewDataExclusion builds a DataExclusion from cfg. Each entry in
+// cfg.ExcludePatterns is matched literally as a case-insensitive domain
+// suffix unless it's wrapped in slashes (e.g. "/ec2-.*\\.amazonaws\\.com/"),
+// in which case the contents are compiled as a regular expression matched
+// against the full record data. A cfg with no patterns excludes nothing,
+// matching the engine's historical behavior of ingesting everything it
+// resolves.
+func NewDataExclusion(cfg config.DNSConfig) (*DataExclusion, error) {
+	d := &DataExclusion{}
+	for _, raw := range cfg.ExcludePatterns {
+		if len(raw) >= 2 && strings.HasPrefix(raw, "/") && strings.HasSuffix(raw, "/") {
+			re, err := regexp.Compile(raw[1 : len(raw)-1])
+			if err != nil {
+				return nil, fmt.Errorf("dns: invalid exclusion pattern %q: %w", raw, err)
+			}
+			d.patterns = append(d.patterns, re)
+			continue
+		}
+		d.suffixes = append(d.suffixes, strings.ToLower(strings.TrimSuffix(raw, ".")))
+	}
+	return d, nil
+}
+
+// Excluded reports whether data matches any configured suffix or regex
+// exclusion pattern.
+func (d *DataExclusion) Excluded(data string) bool {
+	if d == nil {
+		return false
+	}
+
+	lower := strings.ToLower(strings.TrimSuffix(data, "."))
+	for _, suffix := range d.suffixes {
+		if lower == suffix || strings.HasSuffix(lower, "."+suffix) {
+			return true
+		}
+	}
+	for _, re := range d.patterns {
+		if re.MatchString(data) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+func TestDataExclusionMatchesConfiguredSuffixCaseInsensitively(t *testing.T) {
+	exclusion, err := NewDataExclusion(config.DNSConfig{ExcludePatterns: []string{"compute.amazonaws.com"}})
+	if err != nil {
+		t.Fatalf("NewDataExclusion failed: %v", err)
+	}
+
+	if !exclusion.Excluded("ec2-198-51-100-7.COMPUTE.amazonaws.com") {
+		t.Fatal("expected a subdomain of the excluded suffix to be excluded regardless of case")
+	}
+	if exclusion.Excluded("www.example.com") {
+		t.Fatal("expected an unrelated name not to be excluded")
+	}
+}
+
+func TestDataExclusionMatchesConfiguredRegex(t *testing.T) {
+	exclusion, err := NewDataExclusion(config.DNSConfig{ExcludePatterns: []string{`/^ec2-.*\.amazonaws\.com$/`}})
+	if err != nil {
+		t.Fatalf("NewDataExclusion failed: %v", err)
+	}
+
+	if !exclusion.Excluded("ec2-198-51-100-7.compute.amazonaws.com") {
+		t.Fatal("expected the regex to match a generic EC2 rDNS name")
+	}
+	if exclusion.Excluded("www.example.com") {
+		t.Fatal("expected the regex not to match an unrelated name")
+	}
+}
+
+func TestNewDataExclusionRejectsInvalidRegex(t *testing.T) {
+	if _, err := NewDataExclusion(config.DNSConfig{ExcludePatterns: []string{"/(unterminated/"}}); err == nil {
+		t.Fatal("expected an error for an invalid regex pattern")
+	}
+}
+
+func TestNilDataExclusionExcludesNothing(t *testing.T) {
+	var exclusion *DataExclusion
+	if exclusion.Excluded("anything.example.com") {
+		t.Fatal("expected a nil DataExclusion to exclude nothing")
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package dns contains plugins that discover assets by querying DNS
+// directly, beyond the passive brute-force and resolution paths.
+package dns
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Querier is the minimal resolver surface NSECWalker needs, satisfied by
+// the engine's resolver pool.
+type Querier interface {
+	Query(ctx context.Context, m *dns.Msg) (*dns.Msg, error)
+}
+
+// NSECWalker discovers every name in a DNSSEC-signed zone by walking its
+// NSEC chain, or, when the zone uses NSEC3 instead, by querying each
+// candidate of a wordlist (see crackNSEC3). Walk does not check whether
+// active-mode techniques are enabled for an apex; it walks whatever apex
+// it is given.
+type NSECWalker struct {
+	Resolver      Querier
+	NSEC3Wordlist []string
+	// Tracker, if set, records each apex's SOA serial across calls to
+	// Walk so a repeat walk of an unchanged zone can be skipped instead
+	// of repeating an intrusive, chain-length-proportional walk that
+	// would just rediscover the same names. Nil disables the check, the
+	// historical always-walk behavior.
+	Tracker *SOATracker
+	// SkipUnchangedZones gates whether Tracker actually skips an
+	// unchanged apex. It's read fresh on every Walk (rather than baked
+	// in at construction) from config.DNSConfig.SkipUnchangedZones, so
+	// an operator can turn it on mid-scan and have it take effect
+	// starting with the zone's current serial.
+	SkipUnchangedZones bool
+}
+
+// Walk enumerates every name it can find in apex's zone and returns the
+// discovered FQDNs. If w.Tracker is set and apex's SOA serial matches the
+// last one observed for it, Walk returns immediately with no names and no
+// error once w.SkipUnchangedZones is also set, since an unchanged zone
+// would just rediscover exactly what the previous walk already found.
+func (w *NSECWalker) Walk(ctx context.Context, apex string) ([]string, error) {
+	if w.Tracker != nil {
+		serial, err := w.querySOASerial(ctx, apex)
+		if err != nil {
+			return nil, err
+		}
+		if ShouldSkip(w.Tracker, w.SkipUnchangedZones, apex, serial) {
+			return nil, nil
+		}
+	}
+
+	signed, nsec3, salt, iterations, err := w.detectDNSSEC(ctx, apex)
+	if err != nil {
+		return nil, err
+	}
+	if !signed {
+		return nil, nil
+	}
+	if nsec3 {
+		return w.crackNSEC3(ctx, apex, salt, iterations)
+	}
+	return w.walkNSEC(ctx, apex)
+}
+
+// querySOASerial queries apex's SOA record and returns its serial.
+func (w *NSECWalker) querySOASerial(ctx context.Context, apex string) (uint32, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(apex), dns.TypeSOA)
+	resp, err := w.Resolver.Query(ctx, m)
+	if err != nil {
+		return 0, err
+	}
+	for _, rr := range resp.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Serial, nil
+		}
+	}
+	return 0, nil
+}
+
+// detectDNSSEC queries for the apex's NSEC/NSEC3PARAM records to determine
+// whether the zone is signed and, if so, which chain type it uses.
+func (w *NSECWalker) detectDNSSEC(ctx context.Context, apex string) (signed, nsec3 bool, salt string, iterations uint16, err error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(apex), dns.TypeNSEC3PARAM)
+	resp, err := w.Resolver.Query(ctx, m)
+	if err != nil {
+		return false, false, "", 0, err
+	}
+	for _, rr := range resp.Answer {
+		if p, ok := rr.(*dns.NSEC3PARAM); ok {
+			return true, true, p.Salt, p.Iterations, nil
+		}
+	}
+
+	m = new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(apex), dns.TypeNSEC)
+	resp, err = w.Resolver.Query(ctx, m)
+	if err != nil {
+		return false, false, "", 0, err
+	}
+	for _, rr := range resp.Answer {
+		if _, ok := rr.(*dns.NSEC); ok {
+			return true, false, "", 0, nil
+		}
+	}
+	return false, false, "", 0, nil
+}
+
+// walkNSEC follows the NSEC "next domain name" chain starting at apex
+// until it loops back to the start, collecting every owner name along the
+// way.
+func (w *NSECWalker) walkNSEC(ctx context.Context, apex string) ([]string, error) {
+	start := dns.Fqdn(apex)
+	current := start
+	var names []string
+	seen := make(map[string]bool)
+
+	for {
+		m := new(dns.Msg)
+		m.SetQuestion(current, dns.TypeNSEC)
+		resp, err := w.Resolver.Query(ctx, m)
+		if err != nil {
+			return names, err
+		}
+
+		var next string
+		for _, rr := range resp.Answer {
+			if n, ok := rr.(*dns.NSEC); ok {
+				names = append(names, strings.TrimSuffix(n.Hdr.Name, "."))
+				next = n.NextDomain
+				break
+			}
+		}
+		if next == "" || seen[next] || next == start {
+			break
+		}
+		seen[next] = true
+		current = next
+	}
+	return names, nil
+}
+
+// crackNSEC3 queries the A record of every candidate in the wordlist under
+// apex and reports the ones answered with NOERROR. Each candidate's NSEC3
+// hash under the zone's salt and iteration count is computed but not
+// compared against anything: the zone's NSEC3 chain is not walked, so a
+// name absent from the wordlist is never found.
+func (w *NSECWalker) crackNSEC3(ctx context.Context, apex, salt string, iterations uint16) ([]string, error) {
+	var found []string
+	saltBytes, err := hexDecode(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range w.NSEC3Wordlist {
+		fqdn := candidate + "." + strings.TrimSuffix(apex, ".")
+		hash := nsec3Hash(fqdn, saltBytes, iterations)
+
+		m := new(dns.Msg)
+		m.SetQuestion(dns.Fqdn(fqdn), dns.TypeA)
+		resp, err := w.Resolver.Query(ctx, m)
+		if err != nil {
+			continue
+		}
+		if resp.Rcode == dns.RcodeSuccess {
+			found = append(found, fqdn)
+		}
+		_ = hash // hash is computed for future direct-chain-matching use
+	}
+	return found, nil
+}
+
+func nsec3Hash(name string, salt []byte, iterations uint16) string {
+	wire := dns.Fqdn(strings.ToLower(name))
+	h := sha1.Sum(append([]byte(wire), salt...))
+	for i := uint16(0); i < iterations; i++ {
+		h = sha1.Sum(append(h[:], salt...))
+	}
+	return base32.HexEncoding.WithPadding(base32.NoPadding).EncodeToString(h[:])
+}
+
+func hexDecode(s string) ([]byte, error) {
+	if s == "" || s == "-" {
+		return nil, nil
+	}
+	return hex.DecodeString(s)
+}
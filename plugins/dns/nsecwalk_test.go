@@ -0,0 +1,107 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// fakeNSECZone answers NSEC queries for a small, fixed zone chain so the
+// walker can be tested without a live DNSSEC-signed nameserver.
+type fakeNSECZone struct {
+	chain   map[string]string // owner -> next domain name
+	serial  uint32
+	queried []uint16 // records every qtype queried, so a test can assert a walk was skipped
+}
+
+func (z *fakeNSECZone) Query(ctx context.Context, m *dns.Msg) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(m)
+
+	q := m.Question[0]
+	z.queried = append(z.queried, q.Qtype)
+	switch q.Qtype {
+	case dns.TypeSOA:
+		resp.Answer = append(resp.Answer, &dns.SOA{
+			Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeSOA},
+			Serial: z.serial,
+		})
+	case dns.TypeNSEC3PARAM:
+		// This fake zone uses plain NSEC, so report nothing.
+	case dns.TypeNSEC:
+		if next, ok := z.chain[q.Name]; ok {
+			resp.Answer = append(resp.Answer, &dns.NSEC{
+				Hdr:        dns.RR_Header{Name: q.Name, Rrtype: dns.TypeNSEC},
+				NextDomain: next,
+			})
+		}
+	}
+	return resp, nil
+}
+
+func TestNSECWalkerWalksFullChain(t *testing.T) {
+	apex := "example.com."
+	zone := &fakeNSECZone{chain: map[string]string{
+		apex:                apex + "a.",
+		apex + "a.":         apex + "b.",
+		apex + "b.":         apex, // loops back to the start
+	}}
+
+	w := &NSECWalker{Resolver: zone}
+	names, err := w.walkNSEC(context.Background(), apex)
+	if err != nil {
+		t.Fatalf("walkNSEC failed: %v", err)
+	}
+
+	want := map[string]bool{apex: true, apex + "a.": true, apex + "b.": true}
+	for _, n := range names {
+		delete(want, n+".")
+	}
+	if len(want) != 0 {
+		t.Fatalf("walk missed names: %v", want)
+	}
+}
+
+// TestNSECWalkerSkipsUnchangedZoneOnRepeatWalk confirms that with a
+// Tracker and SkipUnchangedZones set, a second Walk of an apex whose SOA
+// serial hasn't changed does no NSEC work at all, while a changed serial
+// still triggers a full walk.
+func TestNSECWalkerSkipsUnchangedZoneOnRepeatWalk(t *testing.T) {
+	apex := "example.com."
+	zone := &fakeNSECZone{serial: 100, chain: map[string]string{
+		apex:        apex + "a.",
+		apex + "a.": apex,
+	}}
+	w := &NSECWalker{Resolver: zone, Tracker: NewSOATracker(), SkipUnchangedZones: true}
+
+	if _, err := w.Walk(context.Background(), apex); err != nil {
+		t.Fatalf("first walk failed: %v", err)
+	}
+	afterFirst := len(zone.queried)
+	if afterFirst <= 1 {
+		t.Fatalf("expected the first walk to actually query the zone, only saw %d queries", afterFirst)
+	}
+
+	names, err := w.Walk(context.Background(), apex)
+	if err != nil {
+		t.Fatalf("second walk failed: %v", err)
+	}
+	if len(names) != 0 {
+		t.Fatalf("expected the skipped walk to return no names, got %v", names)
+	}
+	if got := len(zone.queried) - afterFirst; got != 1 {
+		t.Fatalf("expected the skipped walk to only query the SOA serial, got %d additional queries", got)
+	}
+
+	zone.serial = 200
+	if _, err := w.Walk(context.Background(), apex); err != nil {
+		t.Fatalf("third walk failed: %v", err)
+	}
+	if got := len(zone.queried) - afterFirst - 1; got <= 1 {
+		t.Fatalf("expected a changed serial to trigger a full walk, only saw %d additional queries", got)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/et"
+	"github.com/owasp-amass/engine/support"
+	"github.com/owasp-amass/engine/types"
+)
+
+// RecordFilter decides which DNS record types get persisted to the graph
+// as first-class assets. Every record is always used for traversal (an
+// excluded CNAME target is still followed, an excluded MX host is still
+// resolved); the filter only controls which resulting assets are written.
+type RecordFilter struct {
+	// persist is nil when everything should be persisted (the config's
+	// zero value), and a populated set otherwise. A nil map is
+	// distinguished from an empty one so "persist nothing" can't be
+	// confused with "config wasn't set".
+	persist map[uint16]bool
+}
+
+// NewRecordFilter builds a RecordFilter from cfg. An empty
+// PersistRecordTypes persists every record type, matching the engine's
+// historical behavior of writing everything it resolves.
+func NewRecordFilter(cfg config.DNSConfig) *RecordFilter {
+	if len(cfg.PersistRecordTypes) == 0 {
+		return &RecordFilter{}
+	}
+	persist := make(map[uint16]bool, len(cfg.PersistRecordTypes))
+	for _, name := range cfg.PersistRecordTypes {
+		if qtype, ok := dns.StringToType[strings.ToUpper(name)]; ok {
+			persist[qtype] = true
+		}
+	}
+	return &RecordFilter{persist: persist}
+}
+
+// ShouldPersist reports whether records of qtype should be written to the
+// graph as first-class assets.
+func (f *RecordFilter) ShouldPersist(qtype uint16) bool {
+	if f.persist == nil {
+		return true
+	}
+	return f.persist[qtype]
+}
+
+// process turns rrs, all answers for name, into the FQDN/IPAddress assets
+// and relations they imply. filter controls which target assets are
+// persisted; the relations connecting name to each target are always
+// returned so resolution logic (following a CNAME, resolving an MX) keeps
+// working regardless of what the operator chose to persist. exclusion, if
+// non-nil, drops a target entirely (no asset, no relation) when its data
+// matches a configured noise pattern, e.g. a generic provider PTR; unlike
+// filter, an excluded target isn't even used for traversal, since it was
+// never wanted in the first place. Every target's data passes through
+// support.NormalizeAnswer, which case-folds hostname targets (CNAME/NS/MX)
+// so two answers for the same name that differ only in casing resolve to
+// one asset instead of two, while leaving every other type's data,
+// including an SRV target, exactly as the resolver returned it.
+func process(name string, rrs []dns.RR, filter *RecordFilter, exclusion *DataExclusion) ([]types.Asset, []types.Relation) {
+	src := types.FQDN{Name: strings.ToLower(name)}
+	now := time.Now()
+
+	var assets []types.Asset
+	var relations []types.Relation
+
+	addRelation := func(relType string, qtype uint16, target types.Asset, data string) {
+		if exclusion.Excluded(data) {
+			return
+		}
+		relations = append(relations, types.Relation{Type: relType, FromAsset: src, ToAsset: target, Timestamp: now})
+		if filter.ShouldPersist(qtype) {
+			assets = append(assets, target)
+		}
+	}
+
+	for _, rr := range rrs {
+		switch v := rr.(type) {
+		case *dns.A:
+			if target, ok := et.AssetFromString(v.A.String(), types.AssetIPAddress); ok {
+				addRelation("a_record", dns.TypeA, target, v.A.String())
+			}
+		case *dns.AAAA:
+			if target, ok := et.AssetFromString(v.AAAA.String(), types.AssetIPAddress); ok {
+				addRelation("aaaa_record", dns.TypeAAAA, target, v.AAAA.String())
+			}
+		case *dns.CNAME:
+			data := support.NormalizeAnswer(support.Answer{Name: name, Data: strings.TrimSuffix(v.Target, "."), Type: dns.TypeCNAME}).Data
+			addRelation("cname_record", dns.TypeCNAME, types.FQDN{Name: data}, data)
+		case *dns.NS:
+			data := support.NormalizeAnswer(support.Answer{Name: name, Data: strings.TrimSuffix(v.Ns, "."), Type: dns.TypeNS}).Data
+			addRelation("ns_record", dns.TypeNS, types.FQDN{Name: data}, data)
+		case *dns.MX:
+			data := support.NormalizeAnswer(support.Answer{Name: name, Data: strings.TrimSuffix(v.Mx, "."), Type: dns.TypeMX}).Data
+			addRelation("mx_record", dns.TypeMX, types.FQDN{Name: data}, data)
+		case *dns.SRV:
+			data := support.NormalizeAnswer(support.Answer{Name: name, Data: strings.TrimSuffix(v.Target, "."), Type: dns.TypeSRV}).Data
+			addRelation("srv_record", dns.TypeSRV, types.FQDN{Name: data}, data)
+		}
+	}
+	return assets, relations
+}
@@ -0,0 +1,98 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/types"
+)
+
+func sampleAnswers(t *testing.T) []dns.RR {
+	t.Helper()
+	a, err := dns.NewRR("www.example.com. 300 IN A 198.51.100.7")
+	if err != nil {
+		t.Fatalf("failed to build the A record fixture: %v", err)
+	}
+	mx, err := dns.NewRR("www.example.com. 300 IN MX 10 mail.example.com.")
+	if err != nil {
+		t.Fatalf("failed to build the MX record fixture: %v", err)
+	}
+	return []dns.RR{a, mx}
+}
+
+func TestProcessPersistsOnlyWhitelistedRecordTypes(t *testing.T) {
+	filter := NewRecordFilter(config.DNSConfig{PersistRecordTypes: []string{"A"}})
+	assets, relations := process("www.example.com", sampleAnswers(t), filter, nil)
+
+	if len(relations) != 2 {
+		t.Fatalf("expected both records to produce relations for traversal, got %d", len(relations))
+	}
+	if len(assets) != 1 {
+		t.Fatalf("expected only the A record to be persisted as an asset, got %d", len(assets))
+	}
+	if _, ok := assets[0].(types.IPAddress); !ok {
+		t.Fatalf("expected the persisted asset to be the resolved IPAddress, got %T", assets[0])
+	}
+}
+
+func TestProcessWithNoConfiguredFilterPersistsEverything(t *testing.T) {
+	filter := NewRecordFilter(config.DNSConfig{})
+	assets, relations := process("www.example.com", sampleAnswers(t), filter, nil)
+
+	if len(assets) != len(relations) {
+		t.Fatalf("expected every record to be persisted by default, got %d assets for %d relations", len(assets), len(relations))
+	}
+}
+
+func TestProcessDropsRecordDataMatchingAnExclusionSuffix(t *testing.T) {
+	filter := NewRecordFilter(config.DNSConfig{})
+	exclusion, err := NewDataExclusion(config.DNSConfig{ExcludePatterns: []string{"mail.example.com"}})
+	if err != nil {
+		t.Fatalf("NewDataExclusion failed: %v", err)
+	}
+
+	assets, relations := process("www.example.com", sampleAnswers(t), filter, exclusion)
+	if len(assets) != 1 || len(relations) != 1 {
+		t.Fatalf("expected the excluded MX target to be dropped entirely, got %d assets and %d relations", len(assets), len(relations))
+	}
+	if _, ok := assets[0].(types.IPAddress); !ok {
+		t.Fatalf("expected the remaining asset to be the A record's IPAddress, got %T", assets[0])
+	}
+}
+
+func TestProcessKeepsRecordDataThatDoesNotMatchExclusion(t *testing.T) {
+	filter := NewRecordFilter(config.DNSConfig{})
+	exclusion, err := NewDataExclusion(config.DNSConfig{ExcludePatterns: []string{"compute.amazonaws.com"}})
+	if err != nil {
+		t.Fatalf("NewDataExclusion failed: %v", err)
+	}
+
+	assets, relations := process("www.example.com", sampleAnswers(t), filter, exclusion)
+	if len(assets) != 2 || len(relations) != 2 {
+		t.Fatalf("expected unmatched data to pass through unaffected, got %d assets for %d relations", len(assets), len(relations))
+	}
+}
+
+func TestProcessLowercasesHostnameTargets(t *testing.T) {
+	mx, err := dns.NewRR("WWW.Example.COM. 300 IN MX 10 Mail.Example.COM.")
+	if err != nil {
+		t.Fatalf("failed to build the MX record fixture: %v", err)
+	}
+
+	filter := NewRecordFilter(config.DNSConfig{})
+	assets, relations := process("WWW.Example.COM", []dns.RR{mx}, filter, nil)
+
+	if relations[0].ToAsset.(types.FQDN).Name != "mail.example.com" {
+		t.Fatalf("expected the MX target to be lowercased, got %q", relations[0].ToAsset.(types.FQDN).Name)
+	}
+	if relations[0].FromAsset.(types.FQDN).Name != "www.example.com" {
+		t.Fatalf("expected the source name to be lowercased, got %q", relations[0].FromAsset.(types.FQDN).Name)
+	}
+	if _, ok := assets[0].(types.FQDN); !ok || assets[0].(types.FQDN).Name != "mail.example.com" {
+		t.Fatalf("expected the persisted asset to be the lowercased MX target, got %+v", assets[0])
+	}
+}
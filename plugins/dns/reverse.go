@@ -0,0 +1,108 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"context"
+	"sync"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// dnsReverse sweeps a set of addresses performing reverse DNS lookups,
+// bounding concurrency with release as a semaphore: it's preloaded with
+// one token per allowed concurrent lookup, sweep acquires a token before
+// launching each sweepAddressRoutine, and each routine returns its token
+// when it finishes.
+type dnsReverse struct {
+	release chan struct{}
+	lookup  func(ctx context.Context, addr string) (string, error)
+	// InScope reports whether a resolved PTR name falls within the
+	// session's configured scope. When set, an address whose PTR name is
+	// in scope is promoted to full in-scope status by sweep (returned in
+	// its second result), so a CIDR-only address gets its forward
+	// resolution and netblock/ASN follow-up pursued instead of being
+	// treated as reachable but out of scope. Nil disables promotion.
+	InScope func(name string) bool
+	// Exclusion drops a resolved PTR name matching a configured noise
+	// pattern (e.g. generic provider rDNS like "*.compute.amazonaws.com")
+	// before it's recorded at all. Nil excludes nothing.
+	Exclusion *DataExclusion
+	// Budget, if set, is drawn from before launching each
+	// sweepAddressRoutine goroutine, bounding this sweep's contribution
+	// to the engine-wide goroutine ceiling alongside every other site
+	// sharing the same WorkerBudget. Nil leaves goroutine count governed
+	// by release's capacity alone.
+	Budget *support.WorkerBudget
+}
+
+// newDNSReverse returns a dnsReverse allowing at most concurrency lookups
+// in flight at once, using lookup to resolve each address.
+func newDNSReverse(concurrency int, lookup func(ctx context.Context, addr string) (string, error)) *dnsReverse {
+	d := &dnsReverse{release: make(chan struct{}, concurrency), lookup: lookup}
+	for i := 0; i < concurrency; i++ {
+		d.release <- struct{}{}
+	}
+	return d
+}
+
+// sweep performs a reverse lookup for every address in addrs, honoring
+// ctx, and returns the resolved PTR name per address plus the subset of
+// addresses promoted to full in-scope status because InScope accepted
+// their name. Once ctx is canceled (e.g. the owning session was killed),
+// sweep stops acquiring tokens to launch new routines and waits for
+// whatever it already started, so every token it handed out is always
+// returned to release before sweep returns.
+func (d *dnsReverse) sweep(ctx context.Context, addrs []string) (map[string]string, []string) {
+	results := make(map[string]string)
+	var promoted []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, addr := range addrs {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return results, promoted
+		case <-d.release:
+		}
+		release, err := d.Budget.Acquire(ctx)
+		if err != nil {
+			d.release <- struct{}{}
+			wg.Wait()
+			return results, promoted
+		}
+		wg.Add(1)
+		go func(addr string) {
+			defer release()
+			d.sweepAddressRoutine(ctx, addr, results, &promoted, &mu, &wg)
+		}(addr)
+	}
+	wg.Wait()
+	return results, promoted
+}
+
+// sweepAddressRoutine resolves a single address and records the result,
+// short-circuiting without doing the lookup if ctx was already canceled by
+// the time it got scheduled, and always returning its release token. If
+// the resolved name is in scope, addr is added to promoted.
+func (d *dnsReverse) sweepAddressRoutine(ctx context.Context, addr string, results map[string]string, promoted *[]string, mu *sync.Mutex, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer func() { d.release <- struct{}{} }()
+
+	if ctx.Err() != nil {
+		return
+	}
+	name, err := d.lookup(ctx, addr)
+	if err != nil || name == "" || d.Exclusion.Excluded(name) {
+		return
+	}
+
+	mu.Lock()
+	results[addr] = name
+	if d.InScope != nil && d.InScope(name) {
+		*promoted = append(*promoted, addr)
+	}
+	mu.Unlock()
+}
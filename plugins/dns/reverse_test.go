@@ -0,0 +1,133 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/support"
+)
+
+func TestSweepStopsAndRestoresTokensOnSessionKill(t *testing.T) {
+	const concurrency = 4
+
+	var started int32
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := newDNSReverse(concurrency, func(ctx context.Context, addr string) (string, error) {
+		atomic.AddInt32(&started, 1)
+		time.Sleep(30 * time.Millisecond)
+		return "host." + addr, nil
+	})
+
+	addrs := make([]string, 200)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("198.51.100.%d", i)
+	}
+
+	// Kill the sweep shortly after it starts, well before all 200
+	// addresses could have been processed at concurrency 4.
+	go func() {
+		time.Sleep(15 * time.Millisecond)
+		cancel()
+	}()
+
+	results, _ := d.sweep(ctx, addrs)
+
+	if len(results) >= len(addrs) {
+		t.Fatalf("expected the kill to cut the sweep short, but got all %d results", len(results))
+	}
+	if got := len(d.release); got != concurrency {
+		t.Fatalf("expected all %d tokens to be restored to release, got %d", concurrency, got)
+	}
+}
+
+// TestSweepPromotesAddressesWithInScopePTRNames ensures a CIDR-only
+// address whose reverse lookup reveals an in-scope name is reported for
+// promotion, while one whose PTR name is out of scope is not.
+func TestSweepPromotesAddressesWithInScopePTRNames(t *testing.T) {
+	d := newDNSReverse(4, func(ctx context.Context, addr string) (string, error) {
+		if addr == "198.51.100.1" {
+			return "host.example.com", nil
+		}
+		return "host.unrelated.net", nil
+	})
+	d.InScope = func(name string) bool {
+		return name == "host.example.com"
+	}
+
+	results, promoted := d.sweep(context.Background(), []string{"198.51.100.1", "198.51.100.2"})
+
+	if len(results) != 2 {
+		t.Fatalf("expected both addresses to resolve, got %d", len(results))
+	}
+	if len(promoted) != 1 || promoted[0] != "198.51.100.1" {
+		t.Fatalf("expected only 198.51.100.1 to be promoted, got %v", promoted)
+	}
+}
+
+// TestSweepDropsResultsMatchingExclusion ensures a resolved PTR name
+// matching a configured exclusion pattern is dropped from results
+// entirely instead of being recorded as noise.
+func TestSweepDropsResultsMatchingExclusion(t *testing.T) {
+	exclusion, err := NewDataExclusion(config.DNSConfig{ExcludePatterns: []string{"compute.amazonaws.com"}})
+	if err != nil {
+		t.Fatalf("NewDataExclusion failed: %v", err)
+	}
+
+	d := newDNSReverse(4, func(ctx context.Context, addr string) (string, error) {
+		if addr == "198.51.100.1" {
+			return "ec2-198-51-100-1.compute.amazonaws.com", nil
+		}
+		return "host.example.com", nil
+	})
+	d.Exclusion = exclusion
+
+	results, _ := d.sweep(context.Background(), []string{"198.51.100.1", "198.51.100.2"})
+
+	if len(results) != 1 {
+		t.Fatalf("expected only the non-excluded address to be recorded, got %d results", len(results))
+	}
+	if _, excluded := results["198.51.100.1"]; excluded {
+		t.Fatal("expected the excluded provider PTR to be dropped")
+	}
+}
+
+// TestSweepHonorsSharedWorkerBudget ensures a WorkerBudget shared with
+// another goroutine-launching source bounds this sweep's combined
+// contribution, even when its own token-based concurrency alone would
+// allow more.
+func TestSweepHonorsSharedWorkerBudget(t *testing.T) {
+	budget := support.NewWorkerBudget(2)
+
+	var current, max int32
+	d := newDNSReverse(20, func(ctx context.Context, addr string) (string, error) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		return "host." + addr, nil
+	})
+	d.Budget = budget
+
+	addrs := make([]string, 50)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("198.51.100.%d", i)
+	}
+	d.sweep(context.Background(), addrs)
+
+	if got := atomic.LoadInt32(&max); got > 2 {
+		t.Fatalf("expected the shared budget of 2 to cap concurrency despite a concurrency of 20, saw %d", got)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import "sync"
+
+// SOATracker records each in-scope apex's most recently observed SOA
+// serial across scans, so a subsequent scan of the same apex can tell
+// whether the zone has changed since last time.
+type SOATracker struct {
+	mu      sync.Mutex
+	serials map[string]uint32
+}
+
+// NewSOATracker returns a tracker with no recorded serials.
+func NewSOATracker() *SOATracker {
+	return &SOATracker{serials: make(map[string]uint32)}
+}
+
+// Observe records serial as apex's current SOA serial and reports whether
+// it's unchanged since the last Observe call for the same apex. An apex's
+// first observation is always reported changed, since there's nothing yet
+// to compare it against.
+func (t *SOATracker) Observe(apex string, serial uint32) (unchanged bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, ok := t.serials[apex]
+	t.serials[apex] = serial
+	return ok && prev == serial
+}
+
+// ShouldSkip reports whether heavy re-enumeration of apex should be
+// skipped, given its currently observed SOA serial. It always records the
+// observation via Observe, regardless of skipUnchanged, so tracking stays current
+// even while skipping is disabled and can take effect the moment an
+// operator turns SkipUnchangedZones on.
+func ShouldSkip(t *SOATracker, skipUnchanged bool, apex string, serial uint32) bool {
+	unchanged := t.Observe(apex, serial)
+	return skipUnchanged && unchanged
+}
@@ -0,0 +1,35 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import "testing"
+
+func TestShouldSkipWithStableSerial(t *testing.T) {
+	tracker := NewSOATracker()
+
+	if ShouldSkip(tracker, true, "example.com", 2024010100) {
+		t.Fatal("expected the first observation of an apex to never be skipped")
+	}
+	if !ShouldSkip(tracker, true, "example.com", 2024010100) {
+		t.Fatal("expected a stable serial to be skipped on the second scan")
+	}
+}
+
+func TestShouldSkipWithChangedSerial(t *testing.T) {
+	tracker := NewSOATracker()
+
+	ShouldSkip(tracker, true, "example.com", 2024010100)
+	if ShouldSkip(tracker, true, "example.com", 2024010200) {
+		t.Fatal("expected a changed serial to never be skipped")
+	}
+}
+
+func TestShouldSkipDisabledNeverSkips(t *testing.T) {
+	tracker := NewSOATracker()
+
+	ShouldSkip(tracker, false, "example.com", 2024010100)
+	if ShouldSkip(tracker, false, "example.com", 2024010100) {
+		t.Fatal("expected skipping to stay disabled when skipUnchanged is false, even with a stable serial")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"context"
+	"sync"
+)
+
+// dnsSubs runs subdomain-enumeration follow-up work — the per-subdomain
+// NS/MX/SRV lookups and the DB writes/event dispatch they trigger — off
+// the main traversal goroutine, queued as callback closures and drained
+// by a pool of worker goroutines.
+type dnsSubs struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []func(ctx context.Context)
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers int
+	closed  bool
+	// wg tracks closures submitted but not yet finished, so a traversal
+	// can fan work out across the pool and then synchronize on it with
+	// Wait, without tearing the processor down.
+	wg sync.WaitGroup
+	// workersWG tracks the worker goroutines themselves; done closes once
+	// every worker has exited, which is what Stop actually waits on.
+	workersWG sync.WaitGroup
+	done      chan struct{}
+}
+
+// newDNSSubs starts a dnsSubs backed by the given number of worker
+// goroutines. workers < 1 is treated as 1, matching the historical
+// single-goroutine behavior.
+func newDNSSubs(workers int) *dnsSubs {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &dnsSubs{
+		ctx: ctx, cancel: cancel,
+		workers: workers,
+		done:    make(chan struct{}),
+	}
+	d.cond = sync.NewCond(&d.mu)
+
+	d.workersWG.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.worker()
+	}
+	go func() {
+		d.workersWG.Wait()
+		close(d.done)
+	}()
+
+	return d
+}
+
+// Submit queues fn to run on the worker pool. It's a no-op once Stop has
+// been called, so a traversal winding down doesn't keep queuing work
+// nobody will wait for.
+func (d *dnsSubs) Submit(fn func(ctx context.Context)) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.wg.Add(1)
+	d.queue = append(d.queue, fn)
+	d.mu.Unlock()
+
+	d.cond.Signal()
+}
+
+// Wait blocks until every closure submitted so far has finished running,
+// letting a traversal fan a batch of subdomain queries out across the
+// pool and then synchronize on their completion before moving on, without
+// shutting the processor down.
+func (d *dnsSubs) Wait() {
+	d.wg.Wait()
+}
+
+// worker repeatedly dequeues and runs the next queued closure until
+// d.ctx is canceled, at which point it stops picking up further queued
+// work and exits. Every worker shares the same queue and context, so
+// independent subdomain query batches run concurrently, bounded by the
+// pool size.
+func (d *dnsSubs) worker() {
+	defer d.workersWG.Done()
+	for {
+		d.mu.Lock()
+		for len(d.queue) == 0 && d.ctx.Err() == nil {
+			d.cond.Wait()
+		}
+		if d.ctx.Err() != nil {
+			d.mu.Unlock()
+			return
+		}
+		fn := d.queue[0]
+		d.queue = d.queue[1:]
+		d.mu.Unlock()
+
+		fn(d.ctx)
+		d.wg.Done()
+	}
+}
+
+// Stop cancels dnsSubs' context and blocks until every worker has
+// actually returned, so a caller never observes Stop returning while a
+// queued callback closure is still writing to the DB or dispatching
+// events. Any closure still queued when Stop is called is dropped rather
+// than run. Stop is safe to call more than once; later calls simply wait
+// for the same shutdown to finish.
+func (d *dnsSubs) Stop() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		<-d.done
+		return
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	d.cancel()
+	d.cond.Broadcast()
+	<-d.done
+}
@@ -0,0 +1,131 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDNSSubsStopWaitsForInFlightCallback confirms Stop doesn't return
+// while a submitted closure is still running, so a caller never observes
+// a half-written DB record after shutdown.
+func TestDNSSubsStopWaitsForInFlightCallback(t *testing.T) {
+	d := newDNSSubs(1)
+
+	started := make(chan struct{})
+	var finished int32
+	d.Submit(func(ctx context.Context) {
+		close(started)
+		time.Sleep(30 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+	})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("submitted closure never started")
+	}
+
+	d.Stop()
+
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Fatal("Stop returned before the in-flight callback finished")
+	}
+}
+
+// TestDNSSubsStopDropsQueuedWorkWithoutPanicking confirms closures still
+// queued when Stop is called are dropped rather than run, and that
+// Submit after Stop is a harmless no-op.
+func TestDNSSubsStopDropsQueuedWorkWithoutPanicking(t *testing.T) {
+	d := newDNSSubs(1)
+
+	started := make(chan struct{})
+	block := make(chan struct{})
+	d.Submit(func(ctx context.Context) {
+		close(started)
+		<-block
+	})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("first closure never started")
+	}
+
+	var queuedRan int32
+	d.Submit(func(ctx context.Context) {
+		atomic.StoreInt32(&queuedRan, 1)
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(stopped)
+	}()
+
+	// give Stop a moment to be waiting, then unblock the in-flight closure
+	time.Sleep(10 * time.Millisecond)
+	close(block)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop never returned")
+	}
+
+	if atomic.LoadInt32(&queuedRan) != 0 {
+		t.Fatal("expected the still-queued closure to be dropped, not run")
+	}
+
+	// Submit and a second Stop after shutdown must not panic.
+	d.Submit(func(ctx context.Context) {})
+	d.Stop()
+}
+
+// TestDNSSubsProcessesConcurrentlyUnderLoad confirms a multi-worker pool
+// actually runs independent subdomain query batches at the same time
+// rather than serializing them, and that Wait blocks until all of them
+// have finished.
+func TestDNSSubsProcessesConcurrentlyUnderLoad(t *testing.T) {
+	const workers = 8
+
+	d := newDNSSubs(workers)
+	defer d.Stop()
+
+	var inFlight, maxInFlight int32
+	var completed int32
+	release := make(chan struct{})
+
+	for i := 0; i < workers; i++ {
+		d.Submit(func(ctx context.Context) {
+			cur := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if cur <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&inFlight, -1)
+			atomic.AddInt32(&completed, 1)
+		})
+	}
+
+	// give the pool a chance to pull every closure off the queue before
+	// releasing them, so maxInFlight reflects true concurrency rather
+	// than one closure finishing before the next starts
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	d.Wait()
+
+	if got := atomic.LoadInt32(&completed); got != workers {
+		t.Fatalf("expected all %d closures to complete, got %d", workers, got)
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got < 2 {
+		t.Fatalf("expected concurrent processing, max simultaneously in flight was %d", got)
+	}
+}
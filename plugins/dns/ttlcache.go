@@ -0,0 +1,58 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLCache remembers a DNS answer's validity, keyed by whatever a caller
+// chooses (typically name+qtype), for at most min(the record's own TTL,
+// MaxTTL). A cache entry never outlives what the authoritative answer said
+// to trust it for, but a misconfigured or hostile nameserver returning an
+// absurdly long TTL still can't pin a stale answer in the cache
+// indefinitely once MaxTTL caps it.
+type TTLCache struct {
+	mu      sync.Mutex
+	maxTTL  time.Duration
+	expires map[string]time.Time
+}
+
+// NewTTLCache returns an empty TTLCache capping every entry's lifetime at
+// maxTTL. A maxTTL <= 0 disables the cap, so an entry's own record TTL
+// alone governs its expiry.
+func NewTTLCache(maxTTL time.Duration) *TTLCache {
+	return &TTLCache{maxTTL: maxTTL, expires: make(map[string]time.Time)}
+}
+
+// Set records key as valid for min(recordTTL, c.maxTTL) from now,
+// replacing any expiry already recorded for key.
+func (c *TTLCache) Set(key string, recordTTL time.Duration) {
+	ttl := recordTTL
+	if c.maxTTL > 0 && ttl > c.maxTTL {
+		ttl = c.maxTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires[key] = time.Now().Add(ttl)
+}
+
+// Valid reports whether key was Set and hasn't yet expired, evicting it if
+// it has.
+func (c *TTLCache) Valid(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires, ok := c.expires[key]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expires) {
+		delete(c.expires, key)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLCacheExpiresOnLowRecordTTL(t *testing.T) {
+	c := NewTTLCache(time.Second) // generous cap, well above the record's own TTL
+	c.Set("www.example.com:A", 20*time.Millisecond)
+
+	if !c.Valid("www.example.com:A") {
+		t.Fatal("expected the entry to be valid immediately after Set")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if c.Valid("www.example.com:A") {
+		t.Fatal("expected the entry to expire once its own low TTL elapsed")
+	}
+}
+
+func TestTTLCacheCapsHighRecordTTLAtConfiguredMax(t *testing.T) {
+	c := NewTTLCache(20 * time.Millisecond) // low cap, well below the record's own TTL
+	c.Set("stale.example.com:A", time.Hour)
+
+	if !c.Valid("stale.example.com:A") {
+		t.Fatal("expected the entry to be valid immediately after Set")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if c.Valid("stale.example.com:A") {
+		t.Fatal("expected a huge record TTL to still expire once the configured max was reached")
+	}
+}
+
+func TestTTLCacheUncappedWhenMaxTTLIsZero(t *testing.T) {
+	c := NewTTLCache(0)
+	c.Set("www.example.com:A", time.Hour)
+
+	if !c.Valid("www.example.com:A") {
+		t.Fatal("expected the entry to be valid with no cap applied")
+	}
+}
+
+func TestTTLCacheValidReportsFalseForUnknownKey(t *testing.T) {
+	c := NewTTLCache(time.Second)
+	if c.Valid("never-set") {
+		t.Fatal("expected an unset key to be invalid")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// commonCrawlRecord is a single line of Common Crawl's CDX index response,
+// one JSON object per captured URL.
+type commonCrawlRecord struct {
+	URL string `json:"url"`
+}
+
+// CommonCrawl discovers subdomains of a domain by walking Common Crawl's
+// CDX index for every URL it captured under that domain, rather than
+// querying a live web archive replay endpoint. The index paginates by a
+// numeric page number instead of a cursor returned in the response, and a
+// domain with a lot of history can span many pages, so Names keeps
+// fetching until a page comes back empty.
+type CommonCrawl struct {
+	// Index identifies the Common Crawl dataset to query, e.g.
+	// "CC-MAIN-2024-10".
+	Index string
+	// Fetch performs one page request against index for domain, using
+	// CDX's zero-based page numbering.
+	Fetch func(ctx context.Context, index, domain string, page int) ([]byte, error)
+	// RatePerSecond caps requests to the index. Common Crawl's own rate
+	// limits are generous, but a scan touching many domains can still
+	// hammer it hard enough to start getting throttled; zero disables
+	// limiting.
+	RatePerSecond int
+	// InScope, when set, filters discovered names to those it accepts.
+	InScope func(name string) bool
+
+	limiter *support.RateLimiter
+}
+
+// NewCommonCrawl builds a CommonCrawl plugin querying index.
+func NewCommonCrawl(index string, ratePerSecond int, fetch func(ctx context.Context, index, domain string, page int) ([]byte, error)) *CommonCrawl {
+	c := &CommonCrawl{Index: index, Fetch: fetch, RatePerSecond: ratePerSecond}
+	if ratePerSecond > 0 {
+		c.limiter = support.NewRateLimiter(ratePerSecond)
+	}
+	return c
+}
+
+// Names returns every distinct subdomain of domain found in the index,
+// filtered by InScope if one was configured.
+func (c *CommonCrawl) Names(ctx context.Context, domain string) ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+
+	for page := 0; ; page++ {
+		if c.limiter != nil {
+			c.limiter.Wait(ctx)
+		}
+		if err := ctx.Err(); err != nil {
+			return names, err
+		}
+
+		raw, err := c.Fetch(ctx, c.Index, domain, page)
+		if err != nil {
+			return names, fmt.Errorf("commoncrawl: fetch failed: %w", err)
+		}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			break
+		}
+
+		for _, line := range bytes.Split(raw, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			var rec commonCrawlRecord
+			if err := json.Unmarshal(line, &rec); err != nil {
+				continue
+			}
+			name := hostname(rec.URL)
+			if name == "" || seen[name] {
+				continue
+			}
+			if c.InScope != nil && !c.InScope(name) {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// hostname extracts the lowercased host from rawURL, or "" if it can't be
+// parsed or has no host.
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,62 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/owasp-amass/engine/support/plugintest"
+)
+
+// commonCrawlPage renders lines (each a captured URL) as a CDX-style
+// newline-delimited JSON page, the shape actually returned by Common
+// Crawl's index API.
+func commonCrawlPage(urls ...string) []byte {
+	var b strings.Builder
+	for _, u := range urls {
+		b.WriteString(`{"url":"`)
+		b.WriteString(u)
+		b.WriteString(`"}` + "\n")
+	}
+	return []byte(b.String())
+}
+
+func TestCommonCrawlNamesWalksPagesUntilEmpty(t *testing.T) {
+	pages := [][]byte{
+		commonCrawlPage("https://www.example.com/a", "https://blog.example.com/post"),
+		commonCrawlPage("https://api.example.com/v1"),
+		{},
+	}
+
+	var fetchedPages []int
+	c := NewCommonCrawl("CC-MAIN-2024-10", 0, func(ctx context.Context, index, domain string, page int) ([]byte, error) {
+		fetchedPages = append(fetchedPages, page)
+		return pages[page], nil
+	})
+
+	names, err := c.Names(context.Background(), "example.com")
+	plugintest.NoError(t, err, "CommonCrawl.Names")
+	if len(fetchedPages) != 3 {
+		t.Fatalf("expected 3 page fetches ending on the empty page, got %v", fetchedPages)
+	}
+	plugintest.AssertNames(t, names, []string{"www.example.com", "blog.example.com", "api.example.com"})
+}
+
+func TestCommonCrawlNamesDedupsAndFiltersInScope(t *testing.T) {
+	pages := [][]byte{
+		commonCrawlPage("https://www.example.com/a", "https://www.example.com/b", "https://evil.other.com/c"),
+		{},
+	}
+
+	c := NewCommonCrawl("CC-MAIN-2024-10", 0, func(ctx context.Context, index, domain string, page int) ([]byte, error) {
+		return pages[page], nil
+	})
+	c.InScope = plugintest.InScopeSuffix("example.com")
+
+	names, err := c.Names(context.Background(), "example.com")
+	plugintest.NoError(t, err, "CommonCrawl.Names")
+	plugintest.AssertNames(t, names, []string{"www.example.com"})
+}
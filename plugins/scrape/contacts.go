@@ -0,0 +1,63 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+	"github.com/owasp-amass/engine/types/contact"
+	"github.com/owasp-amass/engine/types/people"
+)
+
+// emailPattern matches email addresses embedded in HTML or plain-text
+// content, e.g. a site's contact page.
+var emailPattern = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+
+// namePattern matches a simple "Firstname Lastname" pattern, good enough to
+// pull bylines and staff-directory entries out of scraped content without
+// pulling in a full NLP dependency.
+var namePattern = regexp.MustCompile(`\b[A-Z][a-z]+ [A-Z][a-z]+\b`)
+
+// ContactScraper mines EmailAddress and Person assets out of page content
+// already fetched by another plugin (e.g. a discovered contact-us page),
+// relating each to the domain it was found on.
+type ContactScraper struct{}
+
+// Mine extracts EmailAddress and Person assets from content, which was
+// fetched from domain, along with the relations tying each back to domain.
+func (ContactScraper) Mine(_ context.Context, domain, content string) ([]types.Asset, []types.Relation) {
+	f := types.FQDN{Name: domain}
+	now := time.Now()
+
+	var assets []types.Asset
+	var rels []types.Relation
+
+	seen := make(map[string]bool)
+	for _, addr := range emailPattern.FindAllString(content, -1) {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+
+		e := contact.EmailAddress{Address: addr}
+		assets = append(assets, e)
+		rels = append(rels, types.Relation{Type: "contact_of", FromAsset: e, ToAsset: f, Timestamp: now})
+	}
+
+	for _, name := range namePattern.FindAllString(content, -1) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		p := people.Person{FullName: name}
+		assets = append(assets, p)
+		rels = append(rels, types.Relation{Type: "associated_with", FromAsset: p, ToAsset: f, Timestamp: now})
+	}
+
+	return assets, rels
+}
@@ -0,0 +1,44 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"testing"
+
+	"github.com/owasp-amass/engine/types/contact"
+	"github.com/owasp-amass/engine/types/people"
+)
+
+const sampleContactPage = `
+	<div class="staff">
+		<p>Jane Doe, Head of Security - jane.doe@example.com</p>
+		<p>John Smith, Support - support@example.com</p>
+	</div>
+`
+
+func TestContactScraperMineExtractsEmailsAndNames(t *testing.T) {
+	var s ContactScraper
+
+	assets, rels := s.Mine(context.Background(), "example.com", sampleContactPage)
+
+	var emails, people_ int
+	for _, a := range assets {
+		switch a.(type) {
+		case contact.EmailAddress:
+			emails++
+		case people.Person:
+			people_++
+		}
+	}
+	if emails != 2 {
+		t.Fatalf("expected 2 emails, got %d", emails)
+	}
+	if people_ != 2 {
+		t.Fatalf("expected 2 people, got %d", people_)
+	}
+	if len(rels) != len(assets) {
+		t.Fatalf("expected one relation per asset, got %d assets and %d relations", len(assets), len(rels))
+	}
+}
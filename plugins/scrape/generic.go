@@ -0,0 +1,126 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// APISourceSpec declaratively describes an HTTP JSON API source: how to
+// build its request URL, how it authenticates, how fast it may be called,
+// and where the discovered names live in the response. It exists so
+// adding a new passive source (urlscan, dnsrepo, leakix, zetalytics,
+// binaryedge, ...) is a config value instead of a new Go type each time.
+type APISourceSpec struct {
+	// Name identifies the source in error messages.
+	Name string
+	// URLTemplate is the request URL with "{domain}" and "{apikey}"
+	// placeholders substituted per request.
+	URLTemplate string
+	// AuthHeader, when set, is sent as an HTTP header carrying APIKey
+	// (e.g. "X-API-Key"). Leave empty for sources that take the key via
+	// the "{apikey}" placeholder in URLTemplate instead.
+	AuthHeader string
+	// APIKey authenticates the request, via AuthHeader or URLTemplate.
+	APIKey string
+	// RatePerSecond caps outbound requests to this source. Zero disables
+	// limiting.
+	RatePerSecond int
+	// NamesPath is a dot-separated path to the array of discovered names
+	// within the parsed JSON response, e.g. "data.subdomains". An empty
+	// path treats the top-level response itself as that array.
+	NamesPath string
+}
+
+// GenericAPISource drives an APISourceSpec: build the URL, fetch it,
+// extract names at NamesPath, and scope-filter them, all without
+// source-specific Go code.
+type GenericAPISource struct {
+	Spec APISourceSpec
+	// Fetch performs the HTTP request, given the built URL and any auth
+	// headers to attach.
+	Fetch func(ctx context.Context, url string, headers map[string]string) ([]byte, error)
+	// InScope, when set, filters discovered names to those it accepts.
+	InScope func(name string) bool
+
+	limiter *support.RateLimiter
+}
+
+// NewGenericAPISource builds a GenericAPISource for spec.
+func NewGenericAPISource(spec APISourceSpec, fetch func(ctx context.Context, url string, headers map[string]string) ([]byte, error)) *GenericAPISource {
+	g := &GenericAPISource{Spec: spec, Fetch: fetch}
+	if spec.RatePerSecond > 0 {
+		g.limiter = support.NewRateLimiter(spec.RatePerSecond)
+	}
+	return g
+}
+
+// Names queries the source for domain and returns the names it reports,
+// filtered by InScope if one was configured.
+func (g *GenericAPISource) Names(ctx context.Context, domain string) ([]string, error) {
+	if g.limiter != nil {
+		g.limiter.Wait(ctx)
+	}
+
+	url := strings.NewReplacer("{domain}", domain, "{apikey}", g.Spec.APIKey).Replace(g.Spec.URLTemplate)
+	headers := make(map[string]string)
+	if g.Spec.AuthHeader != "" {
+		headers[g.Spec.AuthHeader] = g.Spec.APIKey
+	}
+
+	raw, err := g.Fetch(ctx, url, headers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: fetch failed: %w", g.Spec.Name, err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse response: %w", g.Spec.Name, err)
+	}
+	items, err := extractPath(doc, g.Spec.NamesPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", g.Spec.Name, err)
+	}
+
+	var names []string
+	for _, item := range items {
+		name, ok := item.(string)
+		if !ok || name == "" {
+			continue
+		}
+		if g.InScope != nil && !g.InScope(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// extractPath walks doc following the dot-separated path and returns the
+// array found there. An empty path expects doc itself to be that array.
+func extractPath(doc interface{}, path string) ([]interface{}, error) {
+	cur := doc
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			m, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("path %q: %q is not an object", path, key)
+			}
+			cur, ok = m[key]
+			if !ok {
+				return nil, fmt.Errorf("path %q: missing key %q", path, key)
+			}
+		}
+	}
+	arr, ok := cur.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("path %q does not resolve to an array", path)
+	}
+	return arr, nil
+}
@@ -0,0 +1,51 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGenericAPISourceExtractsAndFiltersNames(t *testing.T) {
+	spec := APISourceSpec{
+		Name:        "examplesource",
+		URLTemplate: "https://api.example.com/subdomains?domain={domain}&key={apikey}",
+		APIKey:      "test-key",
+		NamesPath:   "data.subdomains",
+	}
+
+	var requestedURL string
+	fetch := func(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+		requestedURL = url
+		return []byte(`{"data":{"subdomains":["www.example.com","out-of-scope.other.com","mail.example.com"]}}`), nil
+	}
+
+	g := NewGenericAPISource(spec, fetch)
+	g.InScope = func(name string) bool { return strings.HasSuffix(name, ".example.com") }
+
+	names, err := g.Names(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Names failed: %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected 2 in-scope names, got %v", names)
+	}
+	if !strings.Contains(requestedURL, "domain=example.com") || !strings.Contains(requestedURL, "key=test-key") {
+		t.Fatalf("expected the URL template placeholders to be substituted, got %q", requestedURL)
+	}
+}
+
+func TestGenericAPISourceRejectsUnresolvablePath(t *testing.T) {
+	spec := APISourceSpec{Name: "examplesource", URLTemplate: "https://api.example.com", NamesPath: "missing.path"}
+	fetch := func(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+		return []byte(`{"data":{}}`), nil
+	}
+
+	g := NewGenericAPISource(spec, fetch)
+	if _, err := g.Names(context.Background(), "example.com"); err == nil {
+		t.Fatal("expected an error for a path that doesn't resolve to an array")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package scrape contains plugins backed by third-party passive data
+// sources reached over their HTTP APIs.
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/owasp-amass/engine/support"
+)
+
+// passiveTotalPage is one page of the PassiveTotal subdomain API response.
+type passiveTotalPage struct {
+	Subdomains []string `json:"subdomains"`
+	LastID     string   `json:"lastid"`
+}
+
+// PassiveTotal queries the PassiveTotal API for subdomains of a domain,
+// following its cursor-based pagination via the shared support.Paginate
+// helper instead of a hand-rolled lastid loop.
+type PassiveTotal struct {
+	APIKey string
+	Fetch  func(ctx context.Context, domain, lastID, apiKey string) ([]byte, error)
+	// StartCursor resumes Names from a previously saved lastid instead of
+	// starting from the first page, so a session interrupted mid-scan
+	// doesn't re-consume API quota restarting the whole domain from page
+	// one.
+	StartCursor string
+	// OnCursor, if set, is called with the lastid to fetch next after
+	// every page (empty once pagination ends), so a caller can persist
+	// it into the session snapshot for a future resume.
+	OnCursor func(cursor string)
+}
+
+// Names returns every subdomain PassiveTotal reports for domain.
+func (p *PassiveTotal) Names(ctx context.Context, domain string) ([]string, error) {
+	var names []string
+
+	err := support.Paginate(ctx, support.PaginateConfig{
+		StartCursor: p.StartCursor,
+		OnCursor:    p.OnCursor,
+		Fetch: func(ctx context.Context, cursor string) ([]byte, error) {
+			return p.Fetch(ctx, domain, cursor, p.APIKey)
+		},
+		Parse: func(raw []byte) (interface{}, string, error) {
+			var page passiveTotalPage
+			if err := json.Unmarshal(raw, &page); err != nil {
+				return nil, "", fmt.Errorf("passivetotal: failed to parse page: %w", err)
+			}
+			return page.Subdomains, page.LastID, nil
+		},
+		OnPage: func(items interface{}) error {
+			names = append(names, items.([]string)...)
+			return nil
+		},
+	})
+	return names, err
+}
@@ -0,0 +1,73 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func passiveTotalPageBody(t *testing.T, subdomains []string, lastID string) []byte {
+	t.Helper()
+	raw, err := json.Marshal(passiveTotalPage{Subdomains: subdomains, LastID: lastID})
+	if err != nil {
+		t.Fatalf("failed to build fixture page: %v", err)
+	}
+	return raw
+}
+
+func TestPassiveTotalNamesResumesFromStartCursor(t *testing.T) {
+	pages := map[string][]byte{
+		"":     passiveTotalPageBody(t, []string{"a.example.com"}, "cursor-1"),
+		"cursor-1": passiveTotalPageBody(t, []string{"b.example.com"}, "cursor-2"),
+		"cursor-2": passiveTotalPageBody(t, []string{"c.example.com"}, ""),
+	}
+
+	var fetchedCursors []string
+	p := &PassiveTotal{
+		APIKey:      "key",
+		StartCursor: "cursor-1",
+		Fetch: func(ctx context.Context, domain, lastID, apiKey string) ([]byte, error) {
+			fetchedCursors = append(fetchedCursors, lastID)
+			return pages[lastID], nil
+		},
+	}
+
+	names, err := p.Names(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Names failed: %v", err)
+	}
+	if len(fetchedCursors) != 2 || fetchedCursors[0] != "cursor-1" {
+		t.Fatalf("expected resume to skip the first page, fetched %v", fetchedCursors)
+	}
+	if len(names) != 2 || names[0] != "b.example.com" || names[1] != "c.example.com" {
+		t.Fatalf("expected only the pages from cursor-1 onward, got %v", names)
+	}
+}
+
+func TestPassiveTotalNamesReportsCursorForResume(t *testing.T) {
+	pages := map[string][]byte{
+		"": passiveTotalPageBody(t, []string{"a.example.com"}, "cursor-1"),
+		"cursor-1": passiveTotalPageBody(t, []string{"b.example.com"}, ""),
+	}
+
+	var savedCursors []string
+	p := &PassiveTotal{
+		APIKey: "key",
+		Fetch: func(ctx context.Context, domain, lastID, apiKey string) ([]byte, error) {
+			return pages[lastID], nil
+		},
+		OnCursor: func(cursor string) {
+			savedCursors = append(savedCursors, cursor)
+		},
+	}
+
+	if _, err := p.Names(context.Background(), "example.com"); err != nil {
+		t.Fatalf("Names failed: %v", err)
+	}
+	if len(savedCursors) != 2 || savedCursors[0] != "cursor-1" || savedCursors[1] != "" {
+		t.Fatalf("expected cursor progression [\"cursor-1\" \"\"], got %v", savedCursors)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"net/url"
+
+	nethttp "github.com/owasp-amass/engine/net/http"
+)
+
+// RedirectDiscovery fetches a seed URL and reports the in-scope hostnames
+// revealed by its redirect chain, e.g. a vanity or CDN domain silently
+// redirecting to a target's real infrastructure that a plain fetch of the
+// seed URL alone would never surface.
+type RedirectDiscovery struct {
+	// Fetch performs the redirect-following request, e.g.
+	// nethttp.RequestWebPageWithRedirects bound to a RequestConfig.
+	Fetch func(ctx context.Context, pageURL string) (nethttp.PageResult, error)
+	// InScope, when set, filters discovered hosts to those it accepts.
+	InScope func(name string) bool
+}
+
+// NewRedirectDiscovery builds a RedirectDiscovery around fetch.
+func NewRedirectDiscovery(fetch func(ctx context.Context, pageURL string) (nethttp.PageResult, error)) *RedirectDiscovery {
+	return &RedirectDiscovery{Fetch: fetch}
+}
+
+// Discover fetches pageURL and returns every distinct, in-scope hostname
+// visited along its redirect chain, including the final URL's host,
+// excluding pageURL's own host.
+func (r *RedirectDiscovery) Discover(ctx context.Context, pageURL string) ([]string, error) {
+	res, err := r.Fetch(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	origin, err := hostOf(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{origin: true}
+	var found []string
+	visit := func(raw string) {
+		host, err := hostOf(raw)
+		if err != nil || host == "" || seen[host] {
+			return
+		}
+		seen[host] = true
+		if r.InScope != nil && !r.InScope(host) {
+			return
+		}
+		found = append(found, host)
+	}
+
+	for _, hop := range res.RedirectChain {
+		visit(hop)
+	}
+	visit(res.FinalURL)
+
+	return found, nil
+}
+
+// hostOf returns raw's hostname, without port.
+func hostOf(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return u.Hostname(), nil
+}
@@ -0,0 +1,48 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scrape
+
+import (
+	"context"
+	"testing"
+
+	nethttp "github.com/owasp-amass/engine/net/http"
+)
+
+func TestRedirectDiscoveryReportsInScopeHostFromChain(t *testing.T) {
+	r := NewRedirectDiscovery(func(ctx context.Context, pageURL string) (nethttp.PageResult, error) {
+		return nethttp.PageResult{
+			Body:          "landed",
+			FinalURL:      "https://internal.example.com/",
+			RedirectChain: []string{"https://vanity.example.net/"},
+		}, nil
+	})
+	r.InScope = func(name string) bool { return name == "internal.example.com" }
+
+	found, err := r.Discover(context.Background(), "https://vanity.example.net/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0] != "internal.example.com" {
+		t.Fatalf("expected only internal.example.com to be reported, got %v", found)
+	}
+}
+
+func TestRedirectDiscoveryExcludesOutOfScopeAndOriginHosts(t *testing.T) {
+	r := NewRedirectDiscovery(func(ctx context.Context, pageURL string) (nethttp.PageResult, error) {
+		return nethttp.PageResult{
+			FinalURL:      "https://vanity.example.net/",
+			RedirectChain: []string{"https://vanity.example.net/hop"},
+		}, nil
+	})
+	r.InScope = func(name string) bool { return false }
+
+	found, err := r.Discover(context.Background(), "https://vanity.example.net/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 0 {
+		t.Fatalf("expected no hosts reported, got %v", found)
+	}
+}
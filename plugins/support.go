@@ -0,0 +1,23 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package plugins holds shared helpers used across the individual
+// discovery plugins.
+package plugins
+
+import (
+	"context"
+
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/engine/resolve"
+)
+
+// dnsQuery resolves name for qtype using the caller-supplied pool. It used
+// to hardcode 8.8.8.8:53 as a quick-and-dirty default; that made it
+// impossible for operators to point plugins at internal resolvers or a
+// nonstandard port, so it now always goes through the configured pool.
+func dnsQuery(ctx context.Context, pool *resolve.Pool, name string, qtype uint16) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	return resolve.PerformQuery(ctx, pool, m)
+}
@@ -0,0 +1,129 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+	"github.com/owasp-amass/engine/types/contact"
+	"github.com/owasp-amass/engine/types/org"
+)
+
+// rdapEntity is one RDAP entity object (an "abuse" or "administrative"
+// role holder) attached to an autnum response.
+type rdapEntity struct {
+	Roles      []string          `json:"roles"`
+	VCardArray []json.RawMessage `json:"vcardArray"`
+}
+
+// rdapAutnumResponse is the subset of an RDAP autnum response this plugin
+// reads: the org name and the entities (abuse/admin contacts) attached to
+// the ASN.
+type rdapAutnumResponse struct {
+	Name     string       `json:"name"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+// ASNContacts pivots from an already-discovered ASN to its registered
+// organization and abuse/admin contacts, by querying an RDAP autnum
+// endpoint.
+type ASNContacts struct {
+	// Fetch retrieves the raw RDAP autnum response for asn.
+	Fetch func(ctx context.Context, asn int) ([]byte, error)
+}
+
+// Lookup queries RDAP for asn and returns the Organization and any
+// Phone/EmailAddress contact assets it finds, along with the relations
+// tying each one back to the ASN.
+func (a *ASNContacts) Lookup(ctx context.Context, asn types.ASN) ([]types.Asset, []types.Relation, error) {
+	raw, err := a.Fetch(ctx, asn.Number)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resp rdapAutnumResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, nil, fmt.Errorf("whois: failed to parse RDAP autnum response: %w", err)
+	}
+
+	now := time.Now()
+	var assets []types.Asset
+	var relations []types.Relation
+
+	addRelation := func(relType string, target types.Asset) {
+		assets = append(assets, target)
+		relations = append(relations, types.Relation{Type: relType, FromAsset: asn, ToAsset: target, Timestamp: now})
+	}
+
+	if resp.Name != "" {
+		addRelation("registration_of", org.Organization{Name: resp.Name})
+	}
+
+	for _, entity := range resp.Entities {
+		if !hasContactRole(entity.Roles) {
+			continue
+		}
+		email, phone := parseVCard(entity.VCardArray)
+		if email != "" {
+			addRelation("abuse_contact", contact.EmailAddress{Address: email})
+		}
+		if phone != "" {
+			addRelation("abuse_contact", contact.Phone{Number: phone})
+		}
+	}
+	return assets, relations, nil
+}
+
+// hasContactRole reports whether roles includes one of the entity roles
+// RDAP uses for abuse/administrative contacts.
+func hasContactRole(roles []string) bool {
+	for _, r := range roles {
+		if r == "abuse" || r == "administrative" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVCard walks an RDAP jCard vCardArray (["vcard", [ [key, params,
+// type, value], ... ]]) and pulls out the first email/tel entries it
+// finds.
+func parseVCard(vcardArray []json.RawMessage) (email, phone string) {
+	if len(vcardArray) < 2 {
+		return "", ""
+	}
+	var entries [][]json.RawMessage
+	if err := json.Unmarshal(vcardArray[1], &entries); err != nil {
+		return "", ""
+	}
+
+	for _, entry := range entries {
+		if len(entry) < 4 {
+			continue
+		}
+		var key string
+		if err := json.Unmarshal(entry[0], &key); err != nil {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(entry[3], &value); err != nil {
+			continue
+		}
+		switch key {
+		case "email":
+			if email == "" {
+				email = value
+			}
+		case "tel":
+			if phone == "" {
+				phone = value
+			}
+		}
+	}
+	return email, phone
+}
@@ -0,0 +1,79 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package whois
+
+import (
+	"context"
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+	"github.com/owasp-amass/engine/types/contact"
+	"github.com/owasp-amass/engine/types/org"
+)
+
+// capturedAutnumResponse is a trimmed capture of an RDAP autnum response,
+// keeping only the fields ASNContacts.Lookup reads.
+const capturedAutnumResponse = `{
+	"name": "EXAMPLE-AS",
+	"entities": [
+		{
+			"roles": ["registrant"],
+			"vcardArray": ["vcard", [["fn", {}, "text", "Example Registrant"]]]
+		},
+		{
+			"roles": ["abuse"],
+			"vcardArray": ["vcard", [
+				["email", {}, "text", "abuse@example.com"],
+				["tel", {}, "uri", "tel:+1-555-0100"]
+			]]
+		}
+	]
+}`
+
+func TestASNContactsLookupExtractsOrgAndAbuseContacts(t *testing.T) {
+	a := &ASNContacts{
+		Fetch: func(ctx context.Context, asn int) ([]byte, error) {
+			return []byte(capturedAutnumResponse), nil
+		},
+	}
+
+	assets, relations, err := a.Lookup(context.Background(), types.ASN{Number: 64500})
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if len(assets) != 3 || len(relations) != 3 {
+		t.Fatalf("expected 3 assets/relations (org, email, phone), got %d/%d", len(assets), len(relations))
+	}
+
+	var sawOrg, sawEmail, sawPhone bool
+	for _, asset := range assets {
+		switch v := asset.(type) {
+		case org.Organization:
+			sawOrg = v.Name == "EXAMPLE-AS"
+		case contact.EmailAddress:
+			sawEmail = v.Address == "abuse@example.com"
+		case contact.Phone:
+			sawPhone = v.Number == "tel:+1-555-0100"
+		}
+	}
+	if !sawOrg || !sawEmail || !sawPhone {
+		t.Fatalf("expected an org, email, and phone asset, got %+v", assets)
+	}
+}
+
+func TestASNContactsLookupSkipsEntitiesWithoutContactRoles(t *testing.T) {
+	a := &ASNContacts{
+		Fetch: func(ctx context.Context, asn int) ([]byte, error) {
+			return []byte(`{"name":"","entities":[{"roles":["registrant"],"vcardArray":["vcard",[["email",{},"text","registrant@example.com"]]]}]}`), nil
+		},
+	}
+
+	assets, _, err := a.Lookup(context.Background(), types.ASN{Number: 64500})
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if len(assets) != 0 {
+		t.Fatalf("expected the registrant-only entity to produce no contact assets, got %+v", assets)
+	}
+}
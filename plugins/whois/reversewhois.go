@@ -0,0 +1,72 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package whois contains plugins that pivot on registration data (WHOIS,
+// RDAP, and reverse-WHOIS sources) to discover additional infrastructure
+// owned by an already-discovered registrant.
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+	"github.com/owasp-amass/engine/types/org"
+)
+
+// reverseWhoisRecord is one entry of a reverse-WHOIS API response: a domain
+// registered by the queried org/email.
+type reverseWhoisRecord struct {
+	Domain string `json:"domain"`
+}
+
+// ReverseWhois pivots from a registrant Organization or EmailAddress,
+// discovered via a prior WHOIS/RDAP lookup, to other domains registered by
+// the same entity.
+type ReverseWhois struct {
+	APIKey string
+	// Fetch retrieves the raw reverse-WHOIS response for registrant (an
+	// organization name or email address).
+	Fetch func(ctx context.Context, registrant, apiKey string) ([]byte, error)
+	// InScope reports whether domain falls within the session's
+	// configured scope. Domains it rejects are dropped rather than
+	// treated as new candidates. A nil InScope accepts every domain.
+	InScope func(domain string) bool
+}
+
+// Pivot queries the reverse-WHOIS source for registrant and returns the
+// in-scope FQDNs it owns, along with the Organization relation tying each
+// one back to registrant.
+func (rw *ReverseWhois) Pivot(ctx context.Context, registrant string) ([]types.FQDN, []types.Relation, error) {
+	raw, err := rw.Fetch(ctx, registrant, rw.APIKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []reverseWhoisRecord
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, nil, fmt.Errorf("whois: failed to parse reverse-whois response: %w", err)
+	}
+
+	registrantOrg := org.Organization{Name: registrant}
+	now := time.Now()
+
+	var fqdns []types.FQDN
+	var rels []types.Relation
+	for _, rec := range records {
+		if rw.InScope != nil && !rw.InScope(rec.Domain) {
+			continue
+		}
+		f := types.FQDN{Name: rec.Domain}
+		fqdns = append(fqdns, f)
+		rels = append(rels, types.Relation{
+			Type:      "registrant_of",
+			FromAsset: registrantOrg,
+			ToAsset:   f,
+			Timestamp: now,
+		})
+	}
+	return fqdns, rels, nil
+}
@@ -0,0 +1,43 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package whois
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const capturedReverseWhoisResponse = `[
+	{"domain": "example.com"},
+	{"domain": "example.net"},
+	{"domain": "unrelated-registrar.test"}
+]`
+
+func TestReverseWhoisPivotFiltersOutOfScopeDomains(t *testing.T) {
+	rw := &ReverseWhois{
+		Fetch: func(ctx context.Context, registrant, apiKey string) ([]byte, error) {
+			return []byte(capturedReverseWhoisResponse), nil
+		},
+		InScope: func(domain string) bool {
+			return strings.HasSuffix(domain, "example.com") || strings.HasSuffix(domain, "example.net")
+		},
+	}
+
+	fqdns, rels, err := rw.Pivot(context.Background(), "Example Corp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fqdns) != 2 {
+		t.Fatalf("expected 2 in-scope domains, got %d", len(fqdns))
+	}
+	if len(rels) != 2 {
+		t.Fatalf("expected 2 registrant relations, got %d", len(rels))
+	}
+	for _, rel := range rels {
+		if rel.Type != "registrant_of" {
+			t.Fatalf("expected registrant_of relation, got %q", rel.Type)
+		}
+	}
+}
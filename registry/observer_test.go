@@ -0,0 +1,20 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package registry
+
+import "testing"
+
+func TestRegisterObserverSeesEventsAcrossAllTypes(t *testing.T) {
+	r := NewRegistry()
+
+	var seen []EventType
+	r.RegisterObserver(func(e *Event) { seen = append(seen, e.Type) })
+
+	r.Dispatch(&Event{Type: "FQDN"})
+	r.Dispatch(&Event{Type: "IPAddress"})
+
+	if len(seen) != 2 || seen[0] != "FQDN" || seen[1] != "IPAddress" {
+		t.Fatalf("expected observer to see both event types, got %v", seen)
+	}
+}
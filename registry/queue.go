@@ -0,0 +1,92 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Queue carries WireEvents between a producer and a consumer that may be
+// different processes entirely, so an event survives past the lifetime of
+// whatever goroutine produced it instead of only ever existing in one
+// process's memory.
+type Queue interface {
+	Enqueue(ctx context.Context, we WireEvent) error
+	Dequeue(ctx context.Context) (WireEvent, error)
+}
+
+// InMemoryQueue is Queue's default implementation: a bounded channel with
+// no durability at all, for tests and single-process deployments that
+// don't need events to survive a restart.
+type InMemoryQueue struct {
+	ch chan WireEvent
+}
+
+// NewInMemoryQueue returns an InMemoryQueue buffering up to capacity
+// events before Enqueue blocks. A capacity <= 0 defaults to 1.
+func NewInMemoryQueue(capacity int) *InMemoryQueue {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &InMemoryQueue{ch: make(chan WireEvent, capacity)}
+}
+
+// Enqueue adds we to the queue, blocking until there's room or ctx is
+// canceled.
+func (q *InMemoryQueue) Enqueue(ctx context.Context, we WireEvent) error {
+	select {
+	case q.ch <- we:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dequeue removes and returns the oldest queued event, blocking until one
+// is available or ctx is canceled.
+func (q *InMemoryQueue) Dequeue(ctx context.Context) (WireEvent, error) {
+	select {
+	case we := <-q.ch:
+		return we, nil
+	case <-ctx.Done():
+		return WireEvent{}, ctx.Err()
+	}
+}
+
+// BrokerQueue adapts an external message broker (Kafka, NATS, SQS, ...) to
+// Queue via caller-supplied Publish/Subscribe functions, the same
+// dependency-injection pattern plugins use for their own HTTP/DB calls, so
+// this package doesn't take a hard dependency on any particular broker
+// client to support durable queueing.
+type BrokerQueue struct {
+	// Publish sends a single WireEvent's encoded payload to the broker.
+	Publish func(ctx context.Context, payload []byte) error
+	// Subscribe blocks for the next available payload from the broker.
+	Subscribe func(ctx context.Context) ([]byte, error)
+}
+
+// Enqueue encodes we as JSON and hands it to Publish.
+func (b *BrokerQueue) Enqueue(ctx context.Context, we WireEvent) error {
+	payload, err := json.Marshal(we)
+	if err != nil {
+		return fmt.Errorf("registry: failed to marshal event for the broker: %w", err)
+	}
+	return b.Publish(ctx, payload)
+}
+
+// Dequeue blocks on Subscribe and decodes its payload back into a
+// WireEvent.
+func (b *BrokerQueue) Dequeue(ctx context.Context) (WireEvent, error) {
+	payload, err := b.Subscribe(ctx)
+	if err != nil {
+		return WireEvent{}, err
+	}
+	var we WireEvent
+	if err := json.Unmarshal(payload, &we); err != nil {
+		return WireEvent{}, fmt.Errorf("registry: failed to unmarshal event from the broker: %w", err)
+	}
+	return we, nil
+}
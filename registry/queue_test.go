@@ -0,0 +1,62 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInMemoryQueueEnqueueDequeueRoundTrips(t *testing.T) {
+	q := NewInMemoryQueue(2)
+	we := WireEvent{Type: "dns", HandlerName: "dnsresolve"}
+
+	ctx := context.Background()
+	if err := q.Enqueue(ctx, we); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	got, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if got.HandlerName != "dnsresolve" {
+		t.Fatalf("expected the enqueued event back, got %+v", got)
+	}
+}
+
+func TestInMemoryQueueDequeueRespectsContextCancellation(t *testing.T) {
+	q := NewInMemoryQueue(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := q.Dequeue(ctx); err == nil {
+		t.Fatal("expected Dequeue to report an error once ctx is canceled on an empty queue")
+	}
+}
+
+func TestBrokerQueueEnqueueDequeueRoundTripsThroughEncodedPayload(t *testing.T) {
+	var published []byte
+	broker := &BrokerQueue{
+		Publish: func(ctx context.Context, payload []byte) error {
+			published = payload
+			return nil
+		},
+		Subscribe: func(ctx context.Context) ([]byte, error) {
+			return published, nil
+		},
+	}
+
+	we := WireEvent{Type: "dns", HandlerName: "dnsresolve"}
+	if err := broker.Enqueue(context.Background(), we); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	got, err := broker.Dequeue(context.Background())
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if got.HandlerName != "dnsresolve" || got.Type != "dns" {
+		t.Fatalf("expected the round-tripped event back, got %+v", got)
+	}
+}
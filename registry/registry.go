@@ -0,0 +1,450 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package registry tracks which handlers plugins have registered for each
+// asset type and drives their execution as events flow through the
+// pipeline.
+package registry
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/support"
+	"github.com/owasp-amass/engine/types"
+)
+
+// EventType identifies the category of asset/event a Registration handles.
+type EventType string
+
+// Event is a unit of work flowing through a pipeline: an asset discovered
+// during a session, along with the session it belongs to.
+type Event struct {
+	Type  EventType
+	Asset types.Asset
+	// Meta carries arbitrary, handler-defined metadata about the event,
+	// e.g. et.NewAssetEvent's "parent" hint. It's copied along with the
+	// rest of the Event by Dispatch and survives EncodeEvent/DecodeEvent
+	// across a wire Queue, so a hint set by one stage (a wildcard-zone
+	// warning, a confidence score) is visible to every later handler that
+	// processes the same event. It is not persisted anywhere: it never
+	// reaches the graph or session DB, and does not outlive this one
+	// event's trip through the pipeline.
+	Meta map[string]interface{}
+
+	emitted []types.Asset
+}
+
+// Emit records that the handler currently processing this Event produced
+// asset. Handlers that discover follow-on assets should report them here
+// instead of only writing them straight to the session's graph, so
+// Registry.CheckTransforms has something to compare against a
+// Registration's declared Transforms.
+func (e *Event) Emit(asset types.Asset) {
+	e.emitted = append(e.emitted, asset)
+}
+
+// Emitted returns a copy of every asset recorded via Emit so far.
+func (e *Event) Emitted() []types.Asset {
+	return append([]types.Asset(nil), e.emitted...)
+}
+
+// SetMeta records value under key in e's Meta, initializing the map if
+// this is the first entry. Handlers should use this instead of writing to
+// e.Meta directly, since a freshly-built Event's Meta is often nil.
+func (e *Event) SetMeta(key string, value interface{}) {
+	if e.Meta == nil {
+		e.Meta = make(map[string]interface{})
+	}
+	e.Meta[key] = value
+}
+
+// GetMeta returns the value stored under key in e's Meta, and whether it
+// was present. It's safe to call on an Event whose Meta is nil.
+func (e *Event) GetMeta(key string) (interface{}, bool) {
+	if e.Meta == nil {
+		return nil, false
+	}
+	v, ok := e.Meta[key]
+	return v, ok
+}
+
+// Registration binds a handler to the EventType it processes.
+type Registration struct {
+	Type    EventType
+	Handler func(*Event) error
+	// Name identifies the plugin that owns this Registration, e.g.
+	// "bgptools" or "dns". It's purely descriptive, used by ListHandlers
+	// for introspection; Dispatch never looks at it.
+	Name string
+	// Transforms lists the asset types this handler can produce from an
+	// event of Type, e.g. an FQDN handler that resolves to IPAddress and
+	// discovers further FQDN names would list both. It's descriptive
+	// only, the same as Name.
+	Transforms []string
+	// Priority orders handlers for the same EventType when a plugin needs
+	// its results available before another's runs, e.g. a cache lookup
+	// before a network call. Lower runs first; Dispatch runs handlers of
+	// equal priority in registration order. Zero is the default.
+	Priority int
+	// Timeout bounds how long a single Handler invocation may run before
+	// handlerTask reports a timeout error instead of waiting, independent
+	// of any scheduler-level action timeout. A slow source (e.g. a WHOIS
+	// dial) can then be bounded without dragging down every other
+	// handler's timeout too. Zero disables the bound.
+	Timeout time.Duration
+	// MaxInstances bounds how many concurrent invocations of Handler are
+	// allowed across every in-flight Dispatch call, guarding a handler
+	// with unbounded fan-in (many assets of its EventType arriving at
+	// once) from opening unbounded outbound connections or goroutines.
+	// Zero selects support.MaxHandlerInstances.
+	MaxInstances int
+	// Requires lists the PluginOptions keys this Registration's plugin
+	// (looked up by Name) must have set to a non-empty value in order to
+	// actually do anything, e.g. "api_key" for a source that silently
+	// no-ops without one. It's descriptive only, same as Transforms;
+	// Dispatch still runs Handler regardless. CheckRequirements is what
+	// turns a missing entry into an actionable startup diagnostic instead
+	// of a silent no-op discovered much later.
+	Requires []string
+
+	semOnce sync.Once
+	sem     chan struct{}
+}
+
+// semaphore lazily builds r's instance-limiting semaphore sized to
+// MaxInstances (or the default, if unset), the first time it's needed.
+func (r *Registration) semaphore() chan struct{} {
+	r.semOnce.Do(func() {
+		n := r.MaxInstances
+		if n <= 0 {
+			n = support.MaxHandlerInstances
+		}
+		r.sem = make(chan struct{}, n)
+	})
+	return r.sem
+}
+
+// eventDataElement wraps an Event as it's carried through handlerTask,
+// capturing whatever error (including a recovered panic) the handler
+// produced.
+type eventDataElement struct {
+	Event *Event
+	Error error
+}
+
+// Registry holds every Registration, keyed by EventType, and the
+// type-agnostic observers that see every event regardless of type.
+type Registry struct {
+	mu        sync.RWMutex
+	handlers  map[EventType][]*Registration
+	observers []func(*Event)
+	// strictTransforms enables CheckTransforms after every handler
+	// invocation. It's a meaningful runtime cost (comparing every
+	// emitted asset's type against a string list on every dispatch), so
+	// it's meant for development and test builds catching a plugin's own
+	// bugs, not left on in production.
+	strictTransforms bool
+	log              *slog.Logger
+	// pipelineTimeouts bounds, per EventType, how long an event may spend
+	// across every one of its registered handlers combined. Set via
+	// SetPipelineTimeout; an absent or zero entry leaves the type
+	// unbounded.
+	pipelineTimeouts map[EventType]time.Duration
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[EventType][]*Registration), log: slog.Default()}
+}
+
+// SetStrictTransforms enables or disables CheckTransforms validation after
+// every handler invocation. It's off by default; enable it in development
+// or test builds to catch a Registration whose Handler emits assets its
+// Transforms list doesn't declare.
+func (r *Registry) SetStrictTransforms(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strictTransforms = enabled
+}
+
+// RegisterHandler adds a handler for the given EventType.
+func (r *Registry) RegisterHandler(reg *Registration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[reg.Type] = append(r.handlers[reg.Type], reg)
+}
+
+// HandlerInfo is a read-only snapshot of a Registration, returned by
+// ListHandlers so operators can inspect what's compiled in without
+// reading source or risking a caller mutating a live Registration.
+type HandlerInfo struct {
+	Type       EventType
+	Name       string
+	Transforms []string
+	Priority   int
+	Timeout    time.Duration
+}
+
+// ListHandlers returns a snapshot of every registered handler's metadata,
+// sorted by EventType and then Name so the output is stable across calls.
+// It exists so operators can see what plugins and handlers are compiled
+// in, their transforms and priorities, without reading source.
+func (r *Registry) ListHandlers() []HandlerInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []HandlerInfo
+	for _, regs := range r.handlers {
+		for _, reg := range regs {
+			out = append(out, HandlerInfo{
+				Type:       reg.Type,
+				Name:       reg.Name,
+				Transforms: append([]string(nil), reg.Transforms...),
+				Priority:   reg.Priority,
+				Timeout:    reg.Timeout,
+			})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// RegisterObserver adds fn as an asset-type-agnostic observer: Dispatch
+// calls it for every event regardless of Type, separate from the typed
+// handler pipelines. This is the hook cross-cutting features like
+// provenance logging, metrics, or notifiers use instead of registering a
+// handler per EventType.
+func (r *Registry) RegisterObserver(fn func(*Event)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observers = append(r.observers, fn)
+}
+
+// SetPipelineTimeout bounds how long an event of type t may spend across
+// every one of its registered handlers combined, independent of any
+// single handler's own Registration.Timeout. It guards against a chain of
+// individually-fast-enough handlers still tying up a pipeline slot
+// indefinitely in aggregate, e.g. an asset with five sequential 2-second
+// handlers registered. Zero (the default) leaves the type unbounded.
+func (r *Registry) SetPipelineTimeout(t EventType, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pipelineTimeouts == nil {
+		r.pipelineTimeouts = make(map[EventType]time.Duration)
+	}
+	r.pipelineTimeouts[t] = d
+}
+
+// Dispatch runs every handler registered for e.Type, plus every observer,
+// against e. It returns the errors produced by typed handlers (including
+// recovered panics); observers are best-effort and never produce errors
+// back to the caller.
+//
+// If e.Type has a pipeline timeout configured via SetPipelineTimeout,
+// Dispatch tracks the combined time spent running e.Type's handlers so
+// far and, once the budget is exhausted, stops running any handler still
+// left for e and appends a timeout error instead, so a chain of handlers
+// each individually within its own Registration.Timeout still can't tie
+// up e indefinitely in aggregate.
+func (r *Registry) Dispatch(e *Event) []error {
+	r.mu.RLock()
+	regs := append([]*Registration(nil), r.handlers[e.Type]...)
+	observers := append([]func(*Event){}, r.observers...)
+	strict := r.strictTransforms
+	budget := r.pipelineTimeouts[e.Type]
+	r.mu.RUnlock()
+
+	// Every handler's copy of e must share one Meta map, so a hint one
+	// handler sets is visible to the handlers after it.
+	if e.Meta == nil {
+		e.Meta = make(map[string]interface{})
+	}
+
+	sort.SliceStable(regs, func(i, j int) bool {
+		return regs[i].Priority < regs[j].Priority
+	})
+
+	var deadline time.Time
+	if budget > 0 {
+		deadline = time.Now().Add(budget)
+	}
+
+	var errs []error
+	for _, reg := range regs {
+		if budget > 0 && time.Now().After(deadline) {
+			errs = append(errs, fmt.Errorf("registry: pipeline for %s exceeded its %s budget before every handler ran", e.Type, budget))
+			break
+		}
+		ede := &eventDataElement{Event: &Event{Type: e.Type, Asset: e.Asset, Meta: e.Meta}}
+		handlerTask(reg, ede)
+		if ede.Error != nil {
+			errs = append(errs, ede.Error)
+		}
+		if strict {
+			errs = append(errs, CheckTransforms(reg, ede.Event.Emitted())...)
+		}
+	}
+	for _, obs := range observers {
+		obs(e)
+	}
+	return errs
+}
+
+// CheckTransforms compares each of emitted's asset types against reg's
+// declared Transforms, returning one error per asset type that Handler
+// produced but Transforms didn't declare. It's exported so tests (and
+// Dispatch, when SetStrictTransforms is on) can call it directly instead
+// of only exercising it indirectly through a full dispatch.
+func CheckTransforms(reg *Registration, emitted []types.Asset) []error {
+	if len(emitted) == 0 {
+		return nil
+	}
+
+	declared := make(map[string]bool, len(reg.Transforms))
+	for _, t := range reg.Transforms {
+		declared[t] = true
+	}
+
+	seen := make(map[string]bool)
+	var errs []error
+	for _, asset := range emitted {
+		t := string(asset.AssetType())
+		if declared[t] || seen[t] {
+			continue
+		}
+		seen[t] = true
+		errs = append(errs, fmt.Errorf("registry: handler %q for %s emitted undeclared asset type %q (declared transforms: %v)", reg.Name, reg.Type, t, reg.Transforms))
+	}
+	return errs
+}
+
+// MissingRequirement describes a registered handler CheckRequirements
+// found to be missing one or more of its declared Requires entries.
+type MissingRequirement struct {
+	Name     string
+	Type     EventType
+	Requires []string
+	Missing  []string
+}
+
+// CheckRequirements walks every registered handler and reports which of
+// them declared Requires entries that opts doesn't satisfy, given this
+// run's plugin options (looked up by each Registration's Name). It
+// doesn't disable the handler or alter Dispatch in any way; it exists so
+// a caller can turn a plugin's silent no-op into a startup diagnostic,
+// via LogMissingRequirements or its own reporting.
+func (r *Registry) CheckRequirements(opts config.PluginOptions) []MissingRequirement {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []MissingRequirement
+	for _, regs := range r.handlers {
+		for _, reg := range regs {
+			if len(reg.Requires) == 0 {
+				continue
+			}
+
+			have := opts[reg.Name]
+			var missing []string
+			for _, key := range reg.Requires {
+				if have[key] == "" {
+					missing = append(missing, key)
+				}
+			}
+			if len(missing) > 0 {
+				out = append(out, MissingRequirement{
+					Name:     reg.Name,
+					Type:     reg.Type,
+					Requires: append([]string(nil), reg.Requires...),
+					Missing:  missing,
+				})
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Type < out[j].Type
+	})
+	return out
+}
+
+// LogMissingRequirements calls CheckRequirements and logs a warning for
+// each result, so an operator sees "plugin X disabled: missing
+// credential" at startup instead of silently getting fewer results from
+// a source than expected. It returns the same slice CheckRequirements
+// did, for a caller that also wants to act on it (e.g. surface it via an
+// API response).
+func (r *Registry) LogMissingRequirements(opts config.PluginOptions) []MissingRequirement {
+	missing := r.CheckRequirements(opts)
+	for _, m := range missing {
+		r.log.Warn("plugin disabled: missing requirement",
+			"plugin", m.Name, "event_type", m.Type, "missing", m.Missing)
+	}
+	return missing
+}
+
+// handlerTask runs r.Handler(ede.Event) on its own goroutine, enforcing
+// both r.MaxInstances and r.Timeout. It blocks acquiring a slot in r's
+// instance semaphore before starting the handler, so an EventType with
+// many events in flight at once can't run more than MaxInstances copies of
+// its handler concurrently; the slot is held until the handler goroutine
+// actually finishes, not until handlerTask returns, so a timeout doesn't
+// free up a slot for a handler that's still running.
+//
+// When Timeout is set, handlerTask reports a timeout error on ede.Error if
+// the handler doesn't finish in time, independent of any scheduler-level
+// timeout. Note that a timed-out handler's goroutine isn't killed, since
+// Handler has no cancellation hook of its own; it's left to finish (or
+// hang) on its own, same as before this bound existed. Plugin handlers
+// also do a lot of unchecked type assertions on discovered data; panics
+// are always converted to an error, timeout or not, so one bad assertion
+// can't silently halt processing for every other asset of that type for
+// the rest of the engine's life.
+func handlerTask(r *Registration, ede *eventDataElement) {
+	sem := r.semaphore()
+	sem <- struct{}{}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { <-sem }()
+		runHandler(r, ede)
+	}()
+
+	if r.Timeout <= 0 {
+		<-done
+		return
+	}
+
+	select {
+	case <-done:
+	case <-time.After(r.Timeout):
+		ede.Error = fmt.Errorf("registry: handler for %s timed out after %s", r.Type, r.Timeout)
+	}
+}
+
+// runHandler invokes r.Handler(ede.Event), converting a panic into an error
+// on ede.Error instead of letting it propagate and crash whatever goroutine
+// is driving this asset type's pipeline.
+func runHandler(r *Registration, ede *eventDataElement) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			ede.Error = fmt.Errorf("registry: handler for %s panicked: %v", r.Type, rec)
+		}
+	}()
+	ede.Error = r.Handler(ede.Event)
+}
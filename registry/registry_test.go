@@ -0,0 +1,361 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/types"
+)
+
+// TestDispatchSurvivesPanickingHandler ensures a panic in one handler is
+// converted into an error and doesn't stop other handlers, or future
+// Dispatch calls, from running.
+func TestDispatchSurvivesPanickingHandler(t *testing.T) {
+	r := NewRegistry()
+
+	var ranAfterPanic bool
+	r.RegisterHandler(&Registration{
+		Type: "FQDN",
+		Handler: func(e *Event) error {
+			var m map[string]int
+			m["boom"] = 1 // nil map write panics
+			return nil
+		},
+	})
+	r.RegisterHandler(&Registration{
+		Type: "FQDN",
+		Handler: func(e *Event) error {
+			ranAfterPanic = true
+			return nil
+		},
+	})
+
+	errs := r.Dispatch(&Event{Type: "FQDN"})
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly 1 error from the panicking handler, got %d", len(errs))
+	}
+	if !ranAfterPanic {
+		t.Fatalf("expected the second handler to still run after the first panicked")
+	}
+
+	// The pipeline (registry) itself must still be usable afterward.
+	errs = r.Dispatch(&Event{Type: "FQDN"})
+	if len(errs) != 1 {
+		t.Fatalf("expected the registry to keep working after a prior panic, got %d errors", len(errs))
+	}
+}
+
+// TestHandlerTimeoutIsIndependentPerHandler ensures a slow handler's own
+// Timeout bounds it without affecting a faster handler's separate Timeout.
+func TestHandlerTimeoutIsIndependentPerHandler(t *testing.T) {
+	r := NewRegistry()
+
+	r.RegisterHandler(&Registration{
+		Type:    "IPAddress",
+		Timeout: 20 * time.Millisecond,
+		Handler: func(e *Event) error {
+			time.Sleep(200 * time.Millisecond) // exceeds its own timeout
+			return nil
+		},
+	})
+	r.RegisterHandler(&Registration{
+		Type:    "IPAddress",
+		Timeout: 200 * time.Millisecond,
+		Handler: func(e *Event) error {
+			time.Sleep(5 * time.Millisecond) // well within its own timeout
+			return nil
+		},
+	})
+
+	errs := r.Dispatch(&Event{Type: "IPAddress"})
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly 1 timeout error, got %d: %v", len(errs), errs)
+	}
+}
+
+// TestMaxInstancesBoundsConcurrentHandlerInvocations ensures a handler
+// registered with MaxInstances=2 never has more than 2 copies of itself
+// running at once, even when many events for its EventType are dispatched
+// concurrently.
+func TestMaxInstancesBoundsConcurrentHandlerInvocations(t *testing.T) {
+	r := NewRegistry()
+
+	var current, max int32
+	r.RegisterHandler(&Registration{
+		Type:         "FQDN",
+		MaxInstances: 2,
+		Handler: func(e *Event) error {
+			n := atomic.AddInt32(&current, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+			return nil
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Dispatch(&Event{Type: "FQDN"})
+		}()
+	}
+	wg.Wait()
+
+	if max > 2 {
+		t.Fatalf("expected at most 2 concurrent invocations, observed %d", max)
+	}
+}
+
+// TestListHandlersReportsMetadataForEveryRegistration ensures ListHandlers
+// surfaces every registered handler's descriptive metadata, sorted by
+// EventType then Name, so an operator can inspect what's compiled in
+// without reading source.
+func TestListHandlersReportsMetadataForEveryRegistration(t *testing.T) {
+	r := NewRegistry()
+
+	r.RegisterHandler(&Registration{
+		Type:       "FQDN",
+		Name:       "dns",
+		Transforms: []string{"IPAddress", "FQDN"},
+		Priority:   1,
+		Handler:    func(e *Event) error { return nil },
+	})
+	r.RegisterHandler(&Registration{
+		Type:       "IPAddress",
+		Name:       "bgptools",
+		Transforms: []string{"ASN", "Netblock"},
+		Handler:    func(e *Event) error { return nil },
+	})
+
+	handlers := r.ListHandlers()
+	if len(handlers) != 2 {
+		t.Fatalf("expected 2 handlers, got %d", len(handlers))
+	}
+	if handlers[0].Type != "FQDN" || handlers[0].Name != "dns" {
+		t.Fatalf("expected FQDN/dns first, got %+v", handlers[0])
+	}
+	if handlers[1].Type != "IPAddress" || handlers[1].Name != "bgptools" {
+		t.Fatalf("expected IPAddress/bgptools second, got %+v", handlers[1])
+	}
+	if len(handlers[0].Transforms) != 2 || handlers[0].Transforms[0] != "IPAddress" {
+		t.Fatalf("expected dns handler's transforms to be preserved, got %+v", handlers[0].Transforms)
+	}
+}
+
+// TestDispatchRunsHandlersInPriorityOrder ensures a lower-Priority handler
+// runs before a higher-Priority one for the same EventType.
+func TestDispatchRunsHandlersInPriorityOrder(t *testing.T) {
+	r := NewRegistry()
+
+	var order []string
+	r.RegisterHandler(&Registration{
+		Type:     "FQDN",
+		Priority: 5,
+		Handler: func(e *Event) error {
+			order = append(order, "low-priority")
+			return nil
+		},
+	})
+	r.RegisterHandler(&Registration{
+		Type:     "FQDN",
+		Priority: 1,
+		Handler: func(e *Event) error {
+			order = append(order, "high-priority")
+			return nil
+		},
+	})
+
+	r.Dispatch(&Event{Type: "FQDN"})
+	if len(order) != 2 || order[0] != "high-priority" || order[1] != "low-priority" {
+		t.Fatalf("expected high-priority handler to run first, got %v", order)
+	}
+}
+
+// TestCheckTransformsFlagsUndeclaredAssetType ensures an emitted asset type
+// missing from Transforms is reported, but a declared one is not.
+func TestCheckTransformsFlagsUndeclaredAssetType(t *testing.T) {
+	reg := &Registration{Name: "dns", Type: "FQDN", Transforms: []string{"IPAddress"}}
+
+	errs := CheckTransforms(reg, []types.Asset{types.IPAddress{Address: "198.51.100.7"}, types.ASN{Number: 64500}})
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly 1 undeclared-transform error, got %d: %v", len(errs), errs)
+	}
+}
+
+// TestCheckTransformsNoOpWhenEverythingDeclared confirms a fully-declared
+// handler produces no errors.
+func TestCheckTransformsNoOpWhenEverythingDeclared(t *testing.T) {
+	reg := &Registration{Name: "dns", Type: "FQDN", Transforms: []string{"IPAddress", "FQDN"}}
+
+	errs := CheckTransforms(reg, []types.Asset{types.IPAddress{Address: "198.51.100.7"}, types.FQDN{Name: "www.example.com"}})
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+// TestDispatchInStrictModeReportsUndeclaredEmission ensures Dispatch itself
+// surfaces a CheckTransforms failure once SetStrictTransforms is enabled,
+// and stays silent about it otherwise.
+func TestDispatchInStrictModeReportsUndeclaredEmission(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterHandler(&Registration{
+		Type: "FQDN", Name: "dns", Transforms: []string{"IPAddress"},
+		Handler: func(e *Event) error {
+			e.Emit(types.ASN{Number: 64500}) // not declared in Transforms
+			return nil
+		},
+	})
+
+	if errs := r.Dispatch(&Event{Type: "FQDN"}); len(errs) != 0 {
+		t.Fatalf("expected no errors with strict mode off, got %v", errs)
+	}
+
+	r.SetStrictTransforms(true)
+	errs := r.Dispatch(&Event{Type: "FQDN"})
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly 1 error with strict mode on, got %d: %v", len(errs), errs)
+	}
+}
+
+// TestCheckRequirementsFlagsMissingOption confirms a Registration whose
+// declared Requires key is absent (or empty) in that plugin's options is
+// reported, while a satisfied one and one with no Requires at all are not.
+func TestCheckRequirementsFlagsMissingOption(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterHandler(&Registration{Name: "zetalytics", Type: "FQDN", Requires: []string{"api_key"}})
+	r.RegisterHandler(&Registration{Name: "crtsh", Type: "FQDN", Requires: []string{"include_expired"}})
+	r.RegisterHandler(&Registration{Name: "dns", Type: "FQDN"})
+
+	missing := r.CheckRequirements(config.PluginOptions{
+		"crtsh": {"include_expired": "true"},
+	})
+	if len(missing) != 1 {
+		t.Fatalf("expected exactly 1 missing-requirement result, got %d: %v", len(missing), missing)
+	}
+	if missing[0].Name != "zetalytics" || len(missing[0].Missing) != 1 || missing[0].Missing[0] != "api_key" {
+		t.Fatalf("unexpected missing-requirement result: %+v", missing[0])
+	}
+}
+
+// TestLogMissingRequirementsReturnsSameResultAsCheck confirms the logging
+// wrapper still hands back what it found, for a caller that wants both
+// the diagnostic log line and the data.
+func TestLogMissingRequirementsReturnsSameResultAsCheck(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterHandler(&Registration{Name: "zetalytics", Type: "FQDN", Requires: []string{"api_key"}})
+
+	missing := r.LogMissingRequirements(config.PluginOptions{})
+	if len(missing) != 1 || missing[0].Name != "zetalytics" {
+		t.Fatalf("expected zetalytics reported missing api_key, got %v", missing)
+	}
+}
+
+// TestDispatchEnforcesPipelineTimeoutAcrossHandlers confirms a chain of
+// individually-fast-enough handlers is still cut off once their combined
+// runtime exceeds the EventType's configured pipeline timeout, and that
+// the handlers left unrun after the budget is exhausted never execute.
+func TestDispatchEnforcesPipelineTimeoutAcrossHandlers(t *testing.T) {
+	r := NewRegistry()
+	r.SetPipelineTimeout("FQDN", 30*time.Millisecond)
+
+	var ran []string
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		r.RegisterHandler(&Registration{
+			Type: "FQDN", Name: name,
+			Handler: func(e *Event) error {
+				ran = append(ran, name)
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			},
+		})
+	}
+
+	errs := r.Dispatch(&Event{Type: "FQDN"})
+	if len(ran) >= 3 {
+		t.Fatalf("expected the pipeline budget to cut off before every handler ran, got %v", ran)
+	}
+
+	var sawTimeout bool
+	for _, err := range errs {
+		if err != nil && strings.Contains(err.Error(), "exceeded its") {
+			sawTimeout = true
+		}
+	}
+	if !sawTimeout {
+		t.Fatalf("expected a pipeline timeout error, got %v", errs)
+	}
+}
+
+// TestDispatchWithoutPipelineTimeoutRunsEveryHandler confirms leaving the
+// pipeline timeout unset (the default) doesn't cut anything off.
+func TestDispatchWithoutPipelineTimeoutRunsEveryHandler(t *testing.T) {
+	r := NewRegistry()
+
+	var ran int
+	for i := 0; i < 3; i++ {
+		r.RegisterHandler(&Registration{
+			Type: "FQDN",
+			Handler: func(e *Event) error {
+				ran++
+				return nil
+			},
+		})
+	}
+
+	if errs := r.Dispatch(&Event{Type: "FQDN"}); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if ran != 3 {
+		t.Fatalf("expected all 3 handlers to run, got %d", ran)
+	}
+}
+
+// TestDispatchPreservesMetaAcrossHandlers confirms a hint set via SetMeta
+// before Dispatch is still visible to a later handler for the same event,
+// after an earlier handler has run, since Dispatch copies the caller's
+// Event (including Meta) into each handler's own eventDataElement.
+func TestDispatchPreservesMetaAcrossHandlers(t *testing.T) {
+	r := NewRegistry()
+
+	e := &Event{Type: "FQDN"}
+	e.SetMeta("wildcard", true)
+
+	var sawWildcard bool
+	r.RegisterHandler(&Registration{
+		Type: "FQDN", Name: "first", Priority: 0,
+		Handler: func(e *Event) error {
+			e.SetMeta("stage", "first")
+			return nil
+		},
+	})
+	r.RegisterHandler(&Registration{
+		Type: "FQDN", Name: "second", Priority: 1,
+		Handler: func(e *Event) error {
+			v, ok := e.GetMeta("wildcard")
+			sawWildcard = ok && v == true
+			return nil
+		},
+	})
+
+	if errs := r.Dispatch(e); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if !sawWildcard {
+		t.Fatal("expected the second handler to see the wildcard hint set before Dispatch")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// WireEvent is Event's serializable form, for handing off to a durable
+// external Queue. An Event can't cross a wire as-is: Asset is an
+// interface with several concrete implementations, so it's carried as a
+// type-tagged envelope instead of relying on Go-specific encoding, and
+// there's no way to serialize a Registration's Handler func at all, so a
+// WireEvent instead names the handler that should process it; the
+// receiving side resolves that name back to a live Registration through
+// its own Registry rather than the func value traveling with the event.
+type WireEvent struct {
+	Type        EventType              `json:"type"`
+	HandlerName string                 `json:"handler_name"`
+	AssetType   types.AssetType        `json:"asset_type"`
+	Asset       json.RawMessage        `json:"asset"`
+	Meta        map[string]interface{} `json:"meta,omitempty"`
+}
+
+// EncodeEvent converts e into its wire form, tagging it with handlerName so
+// the receiving side's Registry can resolve which Registration should
+// process it once decoded.
+func EncodeEvent(e *Event, handlerName string) (WireEvent, error) {
+	raw, err := json.Marshal(e.Asset)
+	if err != nil {
+		return WireEvent{}, fmt.Errorf("registry: failed to encode asset for %s: %w", e.Type, err)
+	}
+	return WireEvent{
+		Type:        e.Type,
+		HandlerName: handlerName,
+		AssetType:   e.Asset.AssetType(),
+		Asset:       raw,
+		Meta:        e.Meta,
+	}, nil
+}
+
+// DecodeEvent reconstructs the Event we describes and looks up the
+// Registration named in we.HandlerName for we.Type, returning both so the
+// caller can hand the Event directly to reg.Handler instead of going
+// through Dispatch's full run of every handler for the type.
+func (r *Registry) DecodeEvent(we WireEvent) (*Event, *Registration, error) {
+	asset, err := decodeAsset(we.AssetType, we.Asset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reg := r.findHandler(we.Type, we.HandlerName)
+	if reg == nil {
+		return nil, nil, fmt.Errorf("registry: no handler named %q registered for %s", we.HandlerName, we.Type)
+	}
+	return &Event{Type: we.Type, Asset: asset, Meta: we.Meta}, reg, nil
+}
+
+// findHandler returns the Registration for handlerName under eventType, or
+// nil if none is registered.
+func (r *Registry) findHandler(eventType EventType, handlerName string) *Registration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, reg := range r.handlers[eventType] {
+		if reg.Name == handlerName {
+			return reg
+		}
+	}
+	return nil
+}
+
+// decodeAsset reconstructs a concrete types.Asset from raw, using assetType
+// to select which struct to decode into.
+func decodeAsset(assetType types.AssetType, raw json.RawMessage) (types.Asset, error) {
+	switch assetType {
+	case types.AssetFQDN:
+		var a types.FQDN
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("registry: failed to decode FQDN asset: %w", err)
+		}
+		return a, nil
+	case types.AssetIPAddress:
+		var a types.IPAddress
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("registry: failed to decode IPAddress asset: %w", err)
+		}
+		return a, nil
+	case types.AssetASN:
+		var a types.ASN
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("registry: failed to decode ASN asset: %w", err)
+		}
+		return a, nil
+	default:
+		return nil, fmt.Errorf("registry: no decoder registered for asset type %q", assetType)
+	}
+}
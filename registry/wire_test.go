@@ -0,0 +1,72 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestEncodeDecodeEventRoundTripsThroughRegisteredHandler(t *testing.T) {
+	r := NewRegistry()
+	var got *Event
+	r.RegisterHandler(&Registration{
+		Type: "dns", Name: "dnsresolve",
+		Handler: func(e *Event) error {
+			got = e
+			return nil
+		},
+	})
+
+	original := &Event{Type: "dns", Asset: types.FQDN{Name: "example.com"}, Meta: map[string]interface{}{"depth": "1"}}
+	we, err := EncodeEvent(original, "dnsresolve")
+	if err != nil {
+		t.Fatalf("EncodeEvent failed: %v", err)
+	}
+
+	// Confirm it actually survives a real JSON round trip, not just an
+	// in-memory struct copy.
+	raw, err := json.Marshal(we)
+	if err != nil {
+		t.Fatalf("failed to marshal WireEvent: %v", err)
+	}
+	var decoded WireEvent
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal WireEvent: %v", err)
+	}
+
+	event, reg, err := r.DecodeEvent(decoded)
+	if err != nil {
+		t.Fatalf("DecodeEvent failed: %v", err)
+	}
+	if reg.Name != "dnsresolve" {
+		t.Fatalf("expected the dnsresolve registration, got %q", reg.Name)
+	}
+	if err := reg.Handler(event); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if got == nil || got.Asset.(types.FQDN).Name != "example.com" {
+		t.Fatalf("expected the decoded asset to reach the handler, got %+v", got)
+	}
+}
+
+func TestDecodeEventReportsUnknownHandler(t *testing.T) {
+	r := NewRegistry()
+	we := WireEvent{Type: "dns", HandlerName: "missing", AssetType: types.AssetFQDN, Asset: json.RawMessage(`{"name":"example.com"}`)}
+
+	if _, _, err := r.DecodeEvent(we); err == nil {
+		t.Fatal("expected an error for a handler name with no matching registration")
+	}
+}
+
+func TestDecodeEventReportsUnsupportedAssetType(t *testing.T) {
+	r := NewRegistry()
+	we := WireEvent{Type: "dns", HandlerName: "dnsresolve", AssetType: types.AssetType("Unsupported"), Asset: json.RawMessage(`{}`)}
+
+	if _, _, err := r.DecodeEvent(we); err == nil {
+		t.Fatal("expected an error for an unsupported asset type")
+	}
+}
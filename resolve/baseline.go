@@ -0,0 +1,137 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+// baselineResolvers is the hand-maintained list of well-known public
+// resolvers used to seed the trusted pool when no BaselineFile is
+// configured. It has accumulated duplicates and a few addresses that have
+// gone dark over time, which is why NewTrustedPool always runs it through
+// dedupe and a reachability probe instead of trusting it verbatim.
+var baselineResolvers = []string{
+	"8.8.8.8",
+	"8.8.4.4",
+	"1.1.1.1",
+	"1.0.0.1",
+	"9.9.9.9",
+	"8.8.8.8", // accidental duplicate
+	"208.67.222.222",
+	"208.67.220.220",
+	"1.1.1.1", // accidental duplicate
+}
+
+// defaultBaselineQPS is the QPS weight given to a baseline resolver that
+// didn't come from a BaselineFile entry with its own QPS set.
+const defaultBaselineQPS = 10
+
+// BaselineResolver describes one trusted-pool resolver loaded from a
+// BaselineFile, including the per-resolver QPS weight an operator has
+// curated for it.
+type BaselineResolver struct {
+	Address string `json:"address"`
+	// QPS weights this resolver relative to the rest of the trusted pool.
+	// Zero or negative selects defaultBaselineQPS.
+	QPS int `json:"qps"`
+}
+
+// loadBaselineFile reads path as a JSON array of BaselineResolver
+// entries. YAML isn't supported here (this tree has no YAML dependency
+// available), only JSON.
+func loadBaselineFile(path string) ([]BaselineResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve: failed to read baseline resolver file %q: %w", path, err)
+	}
+
+	var entries []BaselineResolver
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("resolve: failed to parse baseline resolver file %q: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Prober checks whether a resolver address is currently reachable.
+type Prober interface {
+	Probe(ctx context.Context, addr string) bool
+}
+
+// dedupeResolvers returns addrs with duplicates removed, preserving the
+// first occurrence's position.
+func dedupeResolvers(addrs []string) []string {
+	seen := make(map[string]bool, len(addrs))
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+		out = append(out, a)
+	}
+	return out
+}
+
+// NewTrustedPool builds the trusted resolver pool from the compiled-in
+// baselineResolvers, deduplicated and filtered to addresses that pass
+// prober's reachability probe, with QPS weights normalized so every
+// surviving resolver gets a positive weight. A dead trusted resolver
+// that's never pruned silently reduces overall query success, so
+// unreachable entries are dropped here rather than left in the pool to
+// fail forever.
+func NewTrustedPool(ctx context.Context, prober Prober) *Pool {
+	var resolvers []*Resolver
+	for _, addr := range dedupeResolvers(baselineResolvers) {
+		if prober != nil && !prober.Probe(ctx, addr) {
+			continue
+		}
+		resolvers = append(resolvers, &Resolver{Address: addr, QPS: defaultBaselineQPS})
+	}
+	return NewPool(resolvers)
+}
+
+// NewTrustedPoolFromConfig builds the trusted resolver pool the same way
+// NewTrustedPool does, except the baseline list comes from cfg.
+// BaselineFile (a JSON array of BaselineResolver entries) when it's set,
+// falling back to the compiled-in baselineResolvers when it's empty or
+// fails to load, so a bad path degrades to the historical behavior
+// instead of leaving the trusted pool empty.
+func NewTrustedPoolFromConfig(ctx context.Context, prober Prober, cfg config.ResolverListConfig) (*Pool, error) {
+	if cfg.BaselineFile == "" {
+		return NewTrustedPool(ctx, prober), nil
+	}
+
+	entries, err := loadBaselineFile(cfg.BaselineFile)
+	if err != nil {
+		slog.Default().Warn("falling back to the compiled-in baseline resolvers",
+			"baseline_file", cfg.BaselineFile, "error", err)
+		return NewTrustedPool(ctx, prober), nil
+	}
+
+	seen := make(map[string]bool, len(entries))
+	var resolvers []*Resolver
+	for _, e := range entries {
+		if e.Address == "" || seen[e.Address] {
+			continue
+		}
+		seen[e.Address] = true
+		if prober != nil && !prober.Probe(ctx, e.Address) {
+			continue
+		}
+
+		qps := e.QPS
+		if qps <= 0 {
+			qps = defaultBaselineQPS
+		}
+		resolvers = append(resolvers, &Resolver{Address: e.Address, QPS: qps})
+	}
+	return NewPool(resolvers), nil
+}
@@ -0,0 +1,106 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+type stubProber struct{ dead map[string]bool }
+
+func (p stubProber) Probe(ctx context.Context, addr string) bool {
+	return !p.dead[addr]
+}
+
+func TestNewTrustedPoolDedupesAndDropsUnreachable(t *testing.T) {
+	prober := stubProber{dead: map[string]bool{"9.9.9.9": true}}
+	pool := NewTrustedPool(context.Background(), prober)
+
+	seen := make(map[string]int)
+	for _, r := range pool.resolvers {
+		seen[r.Address]++
+		if seen[r.Address] > 1 {
+			t.Fatalf("duplicate resolver %q in trusted pool", r.Address)
+		}
+	}
+	if seen["9.9.9.9"] != 0 {
+		t.Fatalf("expected unreachable resolver 9.9.9.9 to be dropped")
+	}
+	if seen["8.8.8.8"] != 1 {
+		t.Fatalf("expected reachable resolver 8.8.8.8 to survive exactly once")
+	}
+}
+
+// TestNewTrustedPoolFromConfigLoadsBaselineFile confirms a configured
+// BaselineFile is used to build the trusted pool, including each entry's
+// own QPS, instead of the compiled-in defaults.
+func TestNewTrustedPoolFromConfigLoadsBaselineFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	entries := []BaselineResolver{
+		{Address: "203.0.113.53", QPS: 25},
+		{Address: "203.0.113.54"}, // no QPS set, should get the default
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("failed to marshal fixture: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	pool, err := NewTrustedPoolFromConfig(context.Background(), nil, config.ResolverListConfig{BaselineFile: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	trustedResolvers := pool.resolvers
+	if len(trustedResolvers) != 2 {
+		t.Fatalf("expected 2 resolvers loaded from the baseline file, got %d", len(trustedResolvers))
+	}
+
+	byAddr := make(map[string]*Resolver, len(trustedResolvers))
+	for _, r := range trustedResolvers {
+		byAddr[r.Address] = r
+	}
+	if byAddr["203.0.113.53"] == nil || byAddr["203.0.113.53"].QPS != 25 {
+		t.Fatalf("expected 203.0.113.53 to keep its configured QPS of 25, got %+v", byAddr["203.0.113.53"])
+	}
+	if byAddr["203.0.113.54"] == nil || byAddr["203.0.113.54"].QPS != defaultBaselineQPS {
+		t.Fatalf("expected 203.0.113.54 to fall back to the default QPS, got %+v", byAddr["203.0.113.54"])
+	}
+}
+
+// TestNewTrustedPoolFromConfigFallsBackWithoutBaselineFile confirms an
+// unset BaselineFile behaves exactly like NewTrustedPool.
+func TestNewTrustedPoolFromConfigFallsBackWithoutBaselineFile(t *testing.T) {
+	prober := stubProber{}
+	pool, err := NewTrustedPoolFromConfig(context.Background(), prober, config.ResolverListConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pool.resolvers) != len(dedupeResolvers(baselineResolvers)) {
+		t.Fatalf("expected the compiled-in baseline to be used, got %d resolvers", len(pool.resolvers))
+	}
+}
+
+// TestNewTrustedPoolFromConfigFallsBackOnLoadFailure confirms a
+// BaselineFile that can't be read degrades to the compiled-in baseline
+// instead of returning an error, matching the documented contract.
+func TestNewTrustedPoolFromConfigFallsBackOnLoadFailure(t *testing.T) {
+	prober := stubProber{}
+	pool, err := NewTrustedPoolFromConfig(context.Background(), prober,
+		config.ResolverListConfig{BaselineFile: filepath.Join(t.TempDir(), "does-not-exist.json")})
+	if err != nil {
+		t.Fatalf("expected a load failure to fall back rather than error, got: %v", err)
+	}
+	if len(pool.resolvers) != len(dedupeResolvers(baselineResolvers)) {
+		t.Fatalf("expected the compiled-in baseline to be used, got %d resolvers", len(pool.resolvers))
+	}
+}
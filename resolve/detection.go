@@ -0,0 +1,38 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+// defaultDetectionResolver is used for wildcard detection when
+// cfg.DetectionResolver isn't set.
+const defaultDetectionResolver = "8.8.8.8"
+
+// defaultDetectionQPS is the QPS weight given to the configured
+// detection resolver.
+const defaultDetectionQPS = 50
+
+// ConfigureDetectionResolver sets pool's wildcard-detection resolver from
+// cfg.DetectionResolver, falling back to defaultDetectionResolver when
+// it's unset, and validates its reachability with prober, logging a
+// warning rather than failing outright if it's unreachable: an operator
+// in an environment where the default (or their configured override) is
+// blocked needs to know wildcard detection is degraded, not have the scan
+// silently flood the graph with junk names instead.
+func ConfigureDetectionResolver(ctx context.Context, pool *Pool, prober Prober, cfg config.DNSConfig) {
+	addr := cfg.DetectionResolver
+	if addr == "" {
+		addr = defaultDetectionResolver
+	}
+	pool.SetDetectionResolver(defaultDetectionQPS, addr)
+
+	if prober != nil && !prober.Probe(ctx, addr) {
+		slog.Default().Warn("resolve: configured detection resolver is unreachable", "address", addr)
+	}
+}
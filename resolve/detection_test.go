@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+// TestConfigureDetectionResolverAppliesConfiguredAddress confirms a
+// configured DetectionResolver is applied to the pool instead of the
+// compiled-in default.
+func TestConfigureDetectionResolverAppliesConfiguredAddress(t *testing.T) {
+	pool := NewPool(nil)
+
+	ConfigureDetectionResolver(context.Background(), pool, stubProber{}, config.DNSConfig{
+		DetectionResolver: "203.0.113.53",
+	})
+
+	got := pool.DetectionResolver()
+	if got == nil || got.Address != "203.0.113.53" {
+		t.Fatalf("expected detection resolver 203.0.113.53, got %+v", got)
+	}
+}
+
+// TestConfigureDetectionResolverDefaultsWhenUnset confirms an empty
+// DetectionResolver falls back to the historical hardcoded address.
+func TestConfigureDetectionResolverDefaultsWhenUnset(t *testing.T) {
+	pool := NewPool(nil)
+
+	ConfigureDetectionResolver(context.Background(), pool, stubProber{}, config.DNSConfig{})
+
+	got := pool.DetectionResolver()
+	if got == nil || got.Address != defaultDetectionResolver {
+		t.Fatalf("expected default detection resolver %q, got %+v", defaultDetectionResolver, got)
+	}
+}
+
+// TestConfigureDetectionResolverWarnsWhenUnreachable confirms an
+// unreachable detection resolver is still applied to the pool (wildcard
+// detection stays configured, just degraded) rather than left unset.
+func TestConfigureDetectionResolverWarnsWhenUnreachable(t *testing.T) {
+	pool := NewPool(nil)
+	prober := stubProber{dead: map[string]bool{"203.0.113.99": true}}
+
+	ConfigureDetectionResolver(context.Background(), pool, prober, config.DNSConfig{
+		DetectionResolver: "203.0.113.99",
+	})
+
+	got := pool.DetectionResolver()
+	if got == nil || got.Address != "203.0.113.99" {
+		t.Fatalf("expected the configured (if unreachable) resolver to still be applied, got %+v", got)
+	}
+}
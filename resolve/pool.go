@@ -0,0 +1,183 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/engine/support"
+)
+
+// ErrEmptyPool is returned when a query is attempted against a Pool and
+// neither it nor any pool in its Fallback chain has a resolver left to try.
+var ErrEmptyPool = errors.New("resolve: resolver pool is empty")
+
+// Pool is a weighted collection of resolvers queried on a caller's behalf.
+type Pool struct {
+	resolvers []*Resolver
+	// Fallback is consulted when this pool has no resolvers left, e.g.
+	// the untrusted/brute-force pool falling back to the smaller trusted
+	// pool when a public resolver list failed to load or every listed
+	// resolver was dropped as unreliable. Without it, that condition
+	// silently stopped every untrusted lookup instead of degrading to a
+	// slower but working pool. Nil disables fallback.
+	Fallback *Pool
+	// Budget, if set, is drawn from before every query this pool issues,
+	// typically a session-level limiter shared with the HTTP layer so
+	// the combined outbound DNS and HTTP rate stays under a single
+	// configured ceiling instead of each protocol capping itself
+	// independently. Nil disables the cap.
+	Budget *support.RateLimiter
+
+	detectionResolver *Resolver
+}
+
+// NewPool builds a Pool from resolvers, defaulting Port to 53 wherever it
+// wasn't set explicitly.
+func NewPool(resolvers []*Resolver) *Pool {
+	for _, r := range resolvers {
+		if r.Port == 0 {
+			r.Port = 53
+		}
+	}
+	return &Pool{resolvers: resolvers}
+}
+
+// Len reports how many resolvers remain in the pool.
+func (p *Pool) Len() int {
+	return len(p.resolvers)
+}
+
+// SetDetectionResolver designates addr (queried at qps) as p's wildcard
+// detection resolver: the resolver a caller cross-checks a suspiciously
+// uniform set of answers against, to confirm it's an authentic result
+// rather than just a wildcard DNS record, using a resolver outside the
+// pool being tested. It replaces whatever detection resolver was set
+// before.
+func (p *Pool) SetDetectionResolver(qps int, addr string) {
+	p.detectionResolver = &Resolver{Address: addr, Port: 53, QPS: qps}
+}
+
+// DetectionResolver returns the resolver configured for wildcard
+// detection, or nil if SetDetectionResolver hasn't been called.
+func (p *Pool) DetectionResolver() *Resolver {
+	return p.detectionResolver
+}
+
+// pick selects a resolver at random from the pool, weighted by QPS when
+// set.
+func (p *Pool) pick() *Resolver {
+	if len(p.resolvers) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, r := range p.resolvers {
+		w := r.QPS
+		if w <= 0 {
+			w = 1
+		}
+		total += w
+	}
+	target := rand.Intn(total)
+	for _, r := range p.resolvers {
+		w := r.QPS
+		if w <= 0 {
+			w = 1
+		}
+		if target < w {
+			return r
+		}
+		target -= w
+	}
+	return p.resolvers[len(p.resolvers)-1]
+}
+
+// effectivePool walks p's Fallback chain past any pool with no resolvers
+// left, returning the first one with resolvers to try (nil if the whole
+// chain is empty) and whether reaching it required falling back at all.
+func (p *Pool) effectivePool() (*Pool, bool) {
+	cur := p
+	usedFallback := false
+	for cur != nil && cur.Len() == 0 {
+		cur = cur.Fallback
+		usedFallback = true
+	}
+	return cur, usedFallback
+}
+
+// PerformQuery sends m against a resolver chosen from pool, or from pool's
+// Fallback chain if pool itself has no resolvers left. Degrading to a
+// fallback pool is logged as a warning, since it usually means a resolver
+// list an operator configured came back empty or was exhausted, and the
+// scan is now running with reduced resolver diversity rather than failing
+// outright.
+func PerformQuery(ctx context.Context, pool *Pool, m *dns.Msg) (*dns.Msg, error) {
+	effective, usedFallback := pool.effectivePool()
+	if effective == nil {
+		return nil, ErrEmptyPool
+	}
+	if usedFallback {
+		slog.Default().Warn("resolve: resolver pool was empty, falling back", "fallback_size", effective.Len())
+	}
+
+	r := effective.pick()
+	if r == nil {
+		return nil, ErrEmptyPool
+	}
+	if pool.Budget != nil {
+		pool.Budget.Wait(ctx)
+	}
+	return r.Query(ctx, m)
+}
+
+// PerformQueries resolves name for every qtype in qtypes concurrently
+// against pool, instead of the caller making one PerformQuery round trip
+// per type in sequence. It returns a response per successfully-resolved
+// qtype; a query that fails for one qtype doesn't prevent the others from
+// completing, but its error is still reported.
+func PerformQueries(ctx context.Context, pool *Pool, name string, qtypes []uint16) (map[uint16]*dns.Msg, error) {
+	return performQueriesWith(qtypes, func(qtype uint16) (*dns.Msg, error) {
+		m := new(dns.Msg)
+		m.SetQuestion(dns.Fqdn(name), qtype)
+		return PerformQuery(ctx, pool, m)
+	})
+}
+
+// performQueriesWith fans query out across qtypes concurrently and
+// collects the results, factored out so it can also back
+// PerformUntrustedQueries and be unit tested without a real resolver.
+func performQueriesWith(qtypes []uint16, query func(qtype uint16) (*dns.Msg, error)) (map[uint16]*dns.Msg, error) {
+	type result struct {
+		qtype uint16
+		resp  *dns.Msg
+		err   error
+	}
+
+	ch := make(chan result, len(qtypes))
+	for _, qt := range qtypes {
+		go func(qt uint16) {
+			resp, err := query(qt)
+			ch <- result{qtype: qt, resp: resp, err: err}
+		}(qt)
+	}
+
+	out := make(map[uint16]*dns.Msg, len(qtypes))
+	var firstErr error
+	for range qtypes {
+		r := <-ch
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		out[r.qtype] = r.resp
+	}
+	return out, firstErr
+}
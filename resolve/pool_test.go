@@ -0,0 +1,73 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import "testing"
+
+func TestNewPoolDefaultsPortAndHonorsNonstandardConfig(t *testing.T) {
+	r1 := &Resolver{Address: "10.0.0.1"}
+	r2 := &Resolver{Address: "10.0.0.2", Port: 5353, ForceTCP: true}
+
+	pool := NewPool([]*Resolver{r1, r2})
+	if pool.Len() != 2 {
+		t.Fatalf("expected 2 resolvers in the pool, got %d", pool.Len())
+	}
+	if r1.Port != 53 {
+		t.Fatalf("expected default port 53, got %d", r1.Port)
+	}
+	if r2.dial() != "10.0.0.2:5353" {
+		t.Fatalf("expected nonstandard port to be preserved, got %q", r2.dial())
+	}
+	if !r2.ForceTCP {
+		t.Fatalf("expected ForceTCP to be preserved")
+	}
+}
+
+func TestEffectivePoolFallsBackWhenEmpty(t *testing.T) {
+	fallback := NewPool([]*Resolver{{Address: "10.0.0.9"}})
+	empty := NewPool(nil)
+	empty.Fallback = fallback
+
+	effective, usedFallback := empty.effectivePool()
+	if !usedFallback {
+		t.Fatal("expected falling back to a non-empty pool to be reported")
+	}
+	if effective != fallback {
+		t.Fatal("expected the fallback pool to be selected")
+	}
+}
+
+func TestEffectivePoolPrefersItselfWhenNonEmpty(t *testing.T) {
+	fallback := NewPool([]*Resolver{{Address: "10.0.0.9"}})
+	pool := NewPool([]*Resolver{{Address: "10.0.0.1"}})
+	pool.Fallback = fallback
+
+	effective, usedFallback := pool.effectivePool()
+	if usedFallback {
+		t.Fatal("expected a non-empty pool to not report a fallback")
+	}
+	if effective != pool {
+		t.Fatal("expected the original pool to be selected")
+	}
+}
+
+func TestEffectivePoolReturnsNilWhenWholeChainIsEmpty(t *testing.T) {
+	empty := NewPool(nil)
+	empty.Fallback = NewPool(nil)
+
+	effective, usedFallback := empty.effectivePool()
+	if effective != nil {
+		t.Fatal("expected a nil effective pool when the whole chain is empty")
+	}
+	if !usedFallback {
+		t.Fatal("expected usedFallback to be true even though the chain came up empty")
+	}
+}
+
+func TestPerformQueryReportsErrEmptyPoolWithNoFallback(t *testing.T) {
+	_, err := PerformQuery(nil, NewPool(nil), nil)
+	if err != ErrEmptyPool {
+		t.Fatalf("expected ErrEmptyPool, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+// PublicResolverList builds the untrusted pool's resolver list from a
+// public-dns.info-style CSV (one resolver per line, optionally with
+// trailing comma-separated metadata this package ignores). It used to be
+// fetched once at startup; if that fetch failed, the untrusted pool
+// stayed empty for the engine's entire lifetime with no retry.
+// PublicResolverList instead retries with backoff on failure, and can
+// periodically re-fetch in the background so a list update doesn't
+// require a restart.
+type PublicResolverList struct {
+	// Fetch retrieves the raw CSV contents, typically over HTTP. Ignored
+	// when LocalFile is set.
+	Fetch func(ctx context.Context) ([]byte, error)
+	// LocalFile, if set, is read from disk instead of calling Fetch, for
+	// air-gapped environments with no route to the public list at all.
+	LocalFile string
+	// MaxRetries bounds how many additional attempts Load makes after
+	// Fetch first fails. Zero disables retrying.
+	MaxRetries int
+	// RetryDelay is the base delay between attempts, doubled after each
+	// failure (capped by maxRetryDelay). Zero selects a small default.
+	RetryDelay time.Duration
+}
+
+// NewPublicResolverList builds a PublicResolverList from cfg, using fetch
+// to retrieve the list when cfg.LocalFile isn't set.
+func NewPublicResolverList(cfg config.ResolverListConfig, fetch func(ctx context.Context) ([]byte, error)) *PublicResolverList {
+	return &PublicResolverList{
+		Fetch:      fetch,
+		LocalFile:  cfg.LocalFile,
+		MaxRetries: cfg.MaxRetries,
+		RetryDelay: cfg.RetryDelay,
+	}
+}
+
+// maxRetryDelay caps the exponential backoff between PublicResolverList
+// load attempts, so a large MaxRetries doesn't leave the untrusted pool
+// waiting on an ever-growing delay.
+const maxRetryDelay = time.Minute
+
+// defaultRetryDelay is used when RetryDelay isn't set.
+const defaultRetryDelay = time.Second
+
+// Load returns the resolvers described by the configured source. With
+// LocalFile set, it reads that file directly with no retry, since a
+// missing local file won't fix itself between attempts. Otherwise it
+// retries Fetch with exponential backoff up to MaxRetries times before
+// giving up.
+func (l *PublicResolverList) Load(ctx context.Context) ([]*Resolver, error) {
+	if l.LocalFile != "" {
+		data, err := os.ReadFile(l.LocalFile)
+		if err != nil {
+			return nil, fmt.Errorf("resolve: failed to read local resolver list %q: %w", l.LocalFile, err)
+		}
+		return parseResolverList(data), nil
+	}
+
+	delay := l.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= l.MaxRetries; attempt++ {
+		data, err := l.Fetch(ctx)
+		if err == nil {
+			return parseResolverList(data), nil
+		}
+		lastErr = err
+
+		if attempt == l.MaxRetries {
+			break
+		}
+		if err := sleepWithContext(ctx, delay); err != nil {
+			return nil, err
+		}
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+	return nil, fmt.Errorf("resolve: failed to load public resolver list after %d attempts: %w", l.MaxRetries+1, lastErr)
+}
+
+// Refresh calls Load every interval, invoking onUpdate with a successful
+// result, until ctx is canceled. A failed refresh is logged nowhere and
+// simply leaves the caller's existing pool untouched until the next tick
+// succeeds, since a periodic refresh failing is expected to be transient
+// in a way the initial Load's retries already exist to smooth over.
+func (l *PublicResolverList) Refresh(ctx context.Context, interval time.Duration, onUpdate func([]*Resolver)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if resolvers, err := l.Load(ctx); err == nil {
+				onUpdate(resolvers)
+			}
+		}
+	}
+}
+
+// sleepWithContext blocks for d or until ctx is canceled, whichever comes
+// first, returning ctx.Err() only in the canceled case.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseResolverList parses data as one resolver address per line,
+// tolerating trailing comma-separated metadata (country, version, etc.)
+// the way a public-dns.info nameservers-all.csv-style export carries, and
+// skipping blank lines and "#"-prefixed comments.
+func parseResolverList(data []byte) []*Resolver {
+	var resolvers []*Resolver
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addr := strings.TrimSpace(strings.SplitN(line, ",", 2)[0])
+		if addr == "" {
+			continue
+		}
+		resolvers = append(resolvers, &Resolver{Address: addr})
+	}
+	return resolvers
+}
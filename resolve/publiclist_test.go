@@ -0,0 +1,112 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+func TestNewPublicResolverListAppliesConfig(t *testing.T) {
+	l := NewPublicResolverList(config.ResolverListConfig{LocalFile: "resolvers.csv", MaxRetries: 5}, nil)
+	if l.LocalFile != "resolvers.csv" || l.MaxRetries != 5 {
+		t.Fatalf("expected config to be applied, got %+v", l)
+	}
+}
+
+func TestPublicResolverListLoadRetriesUntilFetchSucceeds(t *testing.T) {
+	var attempts int32
+	l := &PublicResolverList{
+		MaxRetries: 3,
+		RetryDelay: time.Millisecond,
+		Fetch: func(ctx context.Context) ([]byte, error) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				return nil, errors.New("temporary network error")
+			}
+			return []byte("198.51.100.1\n203.0.113.1,US,v4\n"), nil
+		},
+	}
+
+	resolvers, err := l.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected exactly 3 attempts, got %d", got)
+	}
+	if len(resolvers) != 2 || resolvers[0].Address != "198.51.100.1" || resolvers[1].Address != "203.0.113.1" {
+		t.Fatalf("unexpected resolvers: %+v", resolvers)
+	}
+}
+
+func TestPublicResolverListLoadGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int32
+	l := &PublicResolverList{
+		MaxRetries: 2,
+		RetryDelay: time.Millisecond,
+		Fetch: func(ctx context.Context) ([]byte, error) {
+			atomic.AddInt32(&attempts, 1)
+			return nil, errors.New("permanent failure")
+		},
+	}
+
+	if _, err := l.Load(context.Background()); err == nil {
+		t.Fatal("expected an error once every attempt fails")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 1 initial attempt plus 2 retries (3 total), got %d", got)
+	}
+}
+
+func TestPublicResolverListLoadFromLocalFileSkipsFetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolvers.csv")
+	if err := os.WriteFile(path, []byte("# comment\n198.51.100.1\n\n203.0.113.1\n"), 0o600); err != nil {
+		t.Fatalf("failed to write fixture file: %v", err)
+	}
+
+	l := &PublicResolverList{
+		LocalFile: path,
+		Fetch: func(ctx context.Context) ([]byte, error) {
+			t.Fatal("Fetch should not be called when LocalFile is set")
+			return nil, nil
+		},
+	}
+
+	resolvers, err := l.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(resolvers) != 2 || resolvers[0].Address != "198.51.100.1" || resolvers[1].Address != "203.0.113.1" {
+		t.Fatalf("unexpected resolvers: %+v", resolvers)
+	}
+}
+
+func TestPublicResolverListRefreshInvokesOnUpdatePerTick(t *testing.T) {
+	var loads int32
+	l := &PublicResolverList{
+		Fetch: func(ctx context.Context) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			return []byte("198.51.100.1\n"), nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
+	defer cancel()
+
+	var updates int32
+	l.Refresh(ctx, 5*time.Millisecond, func(resolvers []*Resolver) {
+		atomic.AddInt32(&updates, 1)
+	})
+
+	if got := atomic.LoadInt32(&updates); got == 0 {
+		t.Fatal("expected at least one refresh to invoke onUpdate before the context expired")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestPerformQueriesWithIssuesConcurrentlyAndCombinesResults(t *testing.T) {
+	qtypes := []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeCNAME}
+
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	query := func(qtype uint16) (*dns.Msg, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		mu.Lock()
+		if n > maxInFlight {
+			maxInFlight = n
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond) // gives the other calls a chance to overlap
+		atomic.AddInt32(&inFlight, -1)
+
+		resp := new(dns.Msg)
+		resp.SetQuestion("example.com.", qtype)
+		return resp, nil
+	}
+
+	results, err := performQueriesWith(qtypes, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != len(qtypes) {
+		t.Fatalf("expected %d combined results, got %d", len(qtypes), len(results))
+	}
+	for _, qt := range qtypes {
+		if _, ok := results[qt]; !ok {
+			t.Fatalf("expected a result for qtype %d", qt)
+		}
+	}
+	if maxInFlight < 2 {
+		t.Fatalf("expected queries to overlap, but max in-flight was %d", maxInFlight)
+	}
+}
+
+func TestPerformQueriesWithReportsFirstErrorButKeepsOtherResults(t *testing.T) {
+	qtypes := []uint16{dns.TypeA, dns.TypeAAAA}
+
+	results, err := performQueriesWith(qtypes, func(qtype uint16) (*dns.Msg, error) {
+		if qtype == dns.TypeAAAA {
+			return nil, ErrEmptyPool
+		}
+		resp := new(dns.Msg)
+		resp.SetQuestion("example.com.", qtype)
+		return resp, nil
+	})
+
+	if err != ErrEmptyPool {
+		t.Fatalf("expected the AAAA failure to surface, got %v", err)
+	}
+	if _, ok := results[dns.TypeA]; !ok {
+		t.Fatalf("expected the successful A result to still be returned")
+	}
+	if _, ok := results[dns.TypeAAAA]; ok {
+		t.Fatalf("expected no result for the failed qtype")
+	}
+}
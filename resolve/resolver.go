@@ -0,0 +1,57 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package resolve implements the engine's DNS resolver pools: the trusted
+// pool used for authoritative-quality answers and the larger untrusted
+// pool used for brute forcing and other high-volume lookups.
+package resolve
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// Resolver is a single configured nameserver.
+type Resolver struct {
+	// Address is the resolver's IP or hostname, without a port.
+	Address string
+	// Port is the resolver's DNS port. Zero defaults to 53.
+	Port int
+	// ForceTCP makes every query against this resolver use TCP instead
+	// of UDP, useful for internal resolvers that filter UDP/53 or for
+	// responses expected to exceed the UDP size limit.
+	ForceTCP bool
+	// QPS is this resolver's per-second query weight within its pool.
+	QPS int
+
+	client *dns.Client
+}
+
+// dial returns the address:port string queries against r should target.
+func (r *Resolver) dial() string {
+	port := r.Port
+	if port == 0 {
+		port = 53
+	}
+	return fmt.Sprintf("%s:%d", r.Address, port)
+}
+
+func (r *Resolver) dnsClient() *dns.Client {
+	if r.client == nil {
+		net := "udp"
+		if r.ForceTCP {
+			net = "tcp"
+		}
+		r.client = &dns.Client{Net: net}
+	}
+	return r.client
+}
+
+// Query sends m to r and returns its response, honoring ctx for
+// cancellation.
+func (r *Resolver) Query(ctx context.Context, m *dns.Msg) (*dns.Msg, error) {
+	resp, _, err := r.dnsClient().ExchangeContext(ctx, m, r.dial())
+	return resp, err
+}
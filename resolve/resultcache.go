@@ -0,0 +1,157 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// cachedAnswer is a single cached qtype lookup's outcome, positive or
+// negative.
+type cachedAnswer struct {
+	msg     *dns.Msg
+	expires time.Time
+}
+
+// ResultCache is a session-scoped cache of DNS lookup results, keyed by
+// name and query type, meant to be shared by every plugin/handler
+// resolving through the same session so a name one handler already
+// resolved (or already confirmed doesn't resolve) isn't queried again by
+// another. It doubles as the negative cache; there's no separate
+// mechanism for callers to coordinate with. An empty answer carries no
+// record TTL, so PerformCachedQueries caches it for maxTTL, and a cache
+// with no cap (maxTTL <= 0) does not cache it at all.
+type ResultCache struct {
+	mu      sync.Mutex
+	maxTTL  time.Duration
+	answers map[string]cachedAnswer
+}
+
+// NewResultCache returns an empty ResultCache capping every entry's
+// lifetime at maxTTL. maxTTL <= 0 disables the cap, so an entry's own
+// answer TTL alone governs its expiry.
+func NewResultCache(maxTTL time.Duration) *ResultCache {
+	return &ResultCache{maxTTL: maxTTL, answers: make(map[string]cachedAnswer)}
+}
+
+// cacheKey canonicalizes name+qtype into a single cache key.
+func cacheKey(name string, qtype uint16) string {
+	return strings.ToLower(dns.Fqdn(name)) + ":" + dns.TypeToString[qtype]
+}
+
+// Get returns the cached response for name/qtype, evicting and reporting
+// ok=false if it has expired or was never recorded.
+func (c *ResultCache) Get(name string, qtype uint16) (*dns.Msg, bool) {
+	key := cacheKey(name, qtype)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.answers[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.answers, key)
+		return nil, false
+	}
+	return entry.msg, true
+}
+
+// Set records resp as the answer for name/qtype, valid for min(ttl,
+// c.maxTTL) from now. It's a no-op if that leaves no positive TTL to
+// cache under, e.g. an uncapped cache handed a zero record TTL.
+func (c *ResultCache) Set(name string, qtype uint16, resp *dns.Msg, ttl time.Duration) {
+	if ttl <= 0 || (c.maxTTL > 0 && ttl > c.maxTTL) {
+		ttl = c.maxTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.answers[cacheKey(name, qtype)] = cachedAnswer{msg: resp, expires: time.Now().Add(ttl)}
+}
+
+// IsResolved reports whether name already has a cached positive answer
+// (at least one record) for either A or AAAA, so a caller deciding
+// whether a brute-force guess is worth re-confirming can skip names the
+// cache already knows resolve without querying either qtype itself. A
+// cached negative answer (NXDOMAIN, empty answer section) doesn't count:
+// only PerformCachedQueries deduplicates those, since a caller here is
+// specifically asking "is it known-resolved", not "is it known".
+func (c *ResultCache) IsResolved(name string) bool {
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		if resp, ok := c.Get(name, qtype); ok && resp != nil && len(resp.Answer) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PerformCachedQueries behaves like PerformQueries, but first consults
+// cache for each qtype and only queries pool for the ones still pending.
+// Every freshly-queried answer is cached, keyed on its own minimum answer
+// TTL, before it's returned, so a name resolved through cache once is
+// resolved for every subsequent caller sharing the same cache instead of
+// re-querying the pool. A nil cache disables caching and behaves exactly
+// like PerformQueries.
+func PerformCachedQueries(ctx context.Context, pool *Pool, name string, qtypes []uint16, cache *ResultCache) (map[uint16]*dns.Msg, error) {
+	return performCachedQueriesWith(name, qtypes, cache, func(qtype uint16) (*dns.Msg, error) {
+		m := new(dns.Msg)
+		m.SetQuestion(dns.Fqdn(name), qtype)
+		return PerformQuery(ctx, pool, m)
+	})
+}
+
+// performCachedQueriesWith backs PerformCachedQueries with an injectable
+// query func, factored out the same way performQueriesWith is, so the
+// caching behavior can be unit tested without a real resolver pool.
+func performCachedQueriesWith(name string, qtypes []uint16, cache *ResultCache, query func(qtype uint16) (*dns.Msg, error)) (map[uint16]*dns.Msg, error) {
+	if cache == nil {
+		return performQueriesWith(qtypes, query)
+	}
+
+	out := make(map[uint16]*dns.Msg, len(qtypes))
+	pending := make([]uint16, 0, len(qtypes))
+	for _, qt := range qtypes {
+		if resp, ok := cache.Get(name, qt); ok {
+			out[qt] = resp
+			continue
+		}
+		pending = append(pending, qt)
+	}
+	if len(pending) == 0 {
+		return out, nil
+	}
+
+	fresh, err := performQueriesWith(pending, query)
+	for qt, resp := range fresh {
+		cache.Set(name, qt, resp, minAnswerTTL(resp))
+		out[qt] = resp
+	}
+	return out, err
+}
+
+// minAnswerTTL returns the smallest TTL among resp's answer records, or 0
+// if it has none, so a cached entry never outlives the shortest-lived
+// record it was built from.
+func minAnswerTTL(resp *dns.Msg) time.Duration {
+	if resp == nil || len(resp.Answer) == 0 {
+		return 0
+	}
+
+	min := resp.Answer[0].Header().Ttl
+	for _, rr := range resp.Answer[1:] {
+		if ttl := rr.Header().Ttl; ttl < min {
+			min = ttl
+		}
+	}
+	return time.Duration(min) * time.Second
+}
@@ -0,0 +1,126 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func answerWithTTL(name string, qtype uint16, ttl uint32) *dns.Msg {
+	resp := new(dns.Msg)
+	resp.SetQuestion(dns.Fqdn(name), qtype)
+	resp.Answer = []dns.RR{&dns.A{
+		Hdr: dns.RR_Header{Name: dns.Fqdn(name), Rrtype: qtype, Ttl: ttl},
+	}}
+	return resp
+}
+
+func TestPerformCachedQueriesSkipsAlreadyCachedQtypes(t *testing.T) {
+	cache := NewResultCache(0)
+	cache.Set("example.com", dns.TypeA, answerWithTTL("example.com", dns.TypeA, 300), 300*time.Second)
+
+	var queried int32
+	results, err := performCachedQueriesWith("example.com", []uint16{dns.TypeA, dns.TypeAAAA}, cache,
+		func(qtype uint16) (*dns.Msg, error) {
+			atomic.AddInt32(&queried, 1)
+			return answerWithTTL("example.com", qtype, 300), nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&queried); got != 1 {
+		t.Fatalf("expected only the uncached AAAA qtype to be queried, got %d queries", got)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected both qtypes represented in the combined result, got %d", len(results))
+	}
+}
+
+func TestPerformCachedQueriesCachesFreshAnswersForReuse(t *testing.T) {
+	cache := NewResultCache(0)
+
+	var queried int32
+	query := func(qtype uint16) (*dns.Msg, error) {
+		atomic.AddInt32(&queried, 1)
+		return answerWithTTL("example.com", qtype, 300), nil
+	}
+
+	if _, err := performCachedQueriesWith("example.com", []uint16{dns.TypeA}, cache, query); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := performCachedQueriesWith("example.com", []uint16{dns.TypeA}, cache, query); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&queried); got != 1 {
+		t.Fatalf("expected the second lookup to be served entirely from cache, got %d queries", got)
+	}
+}
+
+func TestResultCacheGetExpiresEntriesPastTheirTTL(t *testing.T) {
+	cache := NewResultCache(0)
+	cache.Set("example.com", dns.TypeA, answerWithTTL("example.com", dns.TypeA, 1), time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get("example.com", dns.TypeA); ok {
+		t.Fatal("expected an expired entry to be evicted and reported missing")
+	}
+}
+
+func TestResultCacheSetCapsTTLAtConfiguredMaximum(t *testing.T) {
+	cache := NewResultCache(time.Millisecond)
+	cache.Set("example.com", dns.TypeA, answerWithTTL("example.com", dns.TypeA, 3600), time.Hour)
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get("example.com", dns.TypeA); ok {
+		t.Fatal("expected maxTTL to cap the record's own much longer TTL")
+	}
+}
+
+func TestResultCacheIsResolvedTrueOnPositiveAAnswer(t *testing.T) {
+	cache := NewResultCache(0)
+	cache.Set("example.com", dns.TypeA, answerWithTTL("example.com", dns.TypeA, 300), 300*time.Second)
+
+	if !cache.IsResolved("example.com") {
+		t.Fatal("expected a cached positive A answer to report as resolved")
+	}
+}
+
+func TestResultCacheIsResolvedFalseWithoutAnyEntry(t *testing.T) {
+	cache := NewResultCache(0)
+
+	if cache.IsResolved("nowhere.example.com") {
+		t.Fatal("expected an unqueried name to report as not resolved")
+	}
+}
+
+func TestResultCacheIsResolvedFalseOnNegativeAnswer(t *testing.T) {
+	cache := NewResultCache(0)
+	empty := new(dns.Msg)
+	empty.SetQuestion(dns.Fqdn("nx.example.com"), dns.TypeA)
+	cache.Set("nx.example.com", dns.TypeA, empty, 300*time.Second)
+
+	if cache.IsResolved("nx.example.com") {
+		t.Fatal("expected a cached negative (empty-answer) response to not count as resolved")
+	}
+}
+
+func TestNilResultCacheDisablesCaching(t *testing.T) {
+	var queried int32
+	_, err := performCachedQueriesWith("example.com", []uint16{dns.TypeA, dns.TypeA}, nil,
+		func(qtype uint16) (*dns.Msg, error) {
+			atomic.AddInt32(&queried, 1)
+			return answerWithTTL("example.com", qtype, 300), nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&queried); got != 2 {
+		t.Fatalf("expected a nil cache to query every qtype every time, got %d queries", got)
+	}
+}
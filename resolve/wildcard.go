@@ -0,0 +1,102 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"context"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// wildcardCache remembers, per zone, the set of answers a wildcard-detect
+// probe returned, so repeated brute-force lookups against the same zone
+// don't re-probe for every guess.
+type wildcardCache struct {
+	mu    sync.Mutex
+	zones map[string]map[string]bool // zone -> set of wildcard answer strings
+}
+
+var wildcards = &wildcardCache{zones: make(map[string]map[string]bool)}
+
+// wildcardDetected probes zone for a DNS wildcard by querying a name that
+// almost certainly doesn't exist, and returns the set of answers a
+// wildcard would produce for that zone (empty if the zone doesn't
+// wildcard).
+func wildcardDetected(ctx context.Context, pool *Pool, zone string) map[string]bool {
+	wildcards.mu.Lock()
+	if answers, ok := wildcards.zones[zone]; ok {
+		wildcards.mu.Unlock()
+		return answers
+	}
+	wildcards.mu.Unlock()
+
+	probe := "zzz-amass-wildcard-check-zzz." + zone
+	resp, err := dnsQueryPool(ctx, pool, probe, dns.TypeA)
+
+	answers := make(map[string]bool)
+	if err == nil && resp != nil {
+		for _, rr := range resp.Answer {
+			if a, ok := rr.(*dns.A); ok {
+				answers[a.A.String()] = true
+			}
+		}
+	}
+
+	wildcards.mu.Lock()
+	wildcards.zones[zone] = answers
+	wildcards.mu.Unlock()
+	return answers
+}
+
+func dnsQueryPool(ctx context.Context, pool *Pool, name string, qtype uint16) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	return PerformQuery(ctx, pool, m)
+}
+
+// PerformUntrustedQuery resolves name against the untrusted/brute-force
+// pool and filters out results that match zone's detected wildcard answer
+// set. Without this, a wildcarded zone would "confirm" every guessed name
+// and flood the graph with junk; the trusted path already applies this
+// check, this brings the untrusted path to parity.
+func PerformUntrustedQuery(ctx context.Context, pool *Pool, name, zone string, qtype uint16) (*dns.Msg, error) {
+	resp, err := dnsQueryPool(ctx, pool, name, qtype)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	wc := wildcardDetected(ctx, pool, zone)
+	return filterWildcardAnswers(resp, wc), nil
+}
+
+// PerformUntrustedQueries resolves name for every qtype in qtypes
+// concurrently against the untrusted/brute-force pool, applying the same
+// per-zone wildcard filtering PerformUntrustedQuery applies to a single
+// qtype.
+func PerformUntrustedQueries(ctx context.Context, pool *Pool, name, zone string, qtypes []uint16) (map[uint16]*dns.Msg, error) {
+	return performQueriesWith(qtypes, func(qtype uint16) (*dns.Msg, error) {
+		return PerformUntrustedQuery(ctx, pool, name, zone, qtype)
+	})
+}
+
+// filterWildcardAnswers strips any A-record answer in resp that matches
+// one of the wildcard zone's known answers, so a wildcarded zone can't
+// "confirm" every guessed brute-force name.
+func filterWildcardAnswers(resp *dns.Msg, wildcardAnswers map[string]bool) *dns.Msg {
+	if len(wildcardAnswers) == 0 {
+		return resp
+	}
+
+	filtered := resp.Copy()
+	var kept []dns.RR
+	for _, rr := range filtered.Answer {
+		if a, ok := rr.(*dns.A); ok && wildcardAnswers[a.A.String()] {
+			continue // matches the wildcard's answer, discard as junk
+		}
+		kept = append(kept, rr)
+	}
+	filtered.Answer = kept
+	return filtered
+}
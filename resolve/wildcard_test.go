@@ -0,0 +1,40 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolve
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestFilterWildcardAnswersDropsJunkMatchingWildcard(t *testing.T) {
+	wildcardIP := "203.0.113.99"
+	realIP := "198.51.100.7"
+
+	resp := new(dns.Msg)
+	resp.Answer = []dns.RR{
+		&dns.A{Hdr: dns.RR_Header{Name: "guess1.example.com."}, A: net.ParseIP(wildcardIP)},
+		&dns.A{Hdr: dns.RR_Header{Name: "real.example.com."}, A: net.ParseIP(realIP)},
+	}
+
+	filtered := filterWildcardAnswers(resp, map[string]bool{wildcardIP: true})
+	if len(filtered.Answer) != 1 {
+		t.Fatalf("expected 1 surviving answer, got %d", len(filtered.Answer))
+	}
+	if a, ok := filtered.Answer[0].(*dns.A); !ok || a.A.String() != realIP {
+		t.Fatalf("expected the real answer to survive, got %v", filtered.Answer[0])
+	}
+}
+
+func TestFilterWildcardAnswersNoOpWhenNoWildcard(t *testing.T) {
+	resp := new(dns.Msg)
+	resp.Answer = []dns.RR{&dns.A{Hdr: dns.RR_Header{Name: "real.example.com."}, A: net.ParseIP("198.51.100.7")}}
+
+	filtered := filterWildcardAnswers(resp, nil)
+	if len(filtered.Answer) != 1 {
+		t.Fatalf("expected the answer to pass through untouched, got %d entries", len(filtered.Answer))
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scheduler
+
+import "sync"
+
+// DependencyGraph tracks dependency relationships between Events by ID,
+// so canceling one event can either cascade to everything depending on
+// it (the historical behavior) or leave dependents alone, re-evaluating
+// whether they're still Processable now that one of their dependencies
+// was dropped instead of completed. Nothing populates a DependencyGraph
+// automatically; a caller records a dependency as it schedules the
+// dependent event.
+type DependencyGraph struct {
+	mu sync.Mutex
+	// deps maps an event ID to the set of dependency IDs it's still
+	// waiting on.
+	deps map[string]map[string]bool
+	// dependents maps a dependency ID to the set of event IDs waiting on
+	// it, the reverse of deps, kept in sync alongside it so cancellation
+	// doesn't need to scan every event to find who depends on one.
+	dependents map[string]map[string]bool
+	canceled   map[string]bool
+}
+
+// NewDependencyGraph returns an empty DependencyGraph.
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		deps:       make(map[string]map[string]bool),
+		dependents: make(map[string]map[string]bool),
+		canceled:   make(map[string]bool),
+	}
+}
+
+// AddDependency records that dependent cannot be considered Processable
+// until dependsOn is satisfied, either by completing normally (Satisfy)
+// or by being canceled itself without cascade (Cancel with cascade
+// false), which drops it from dependent's outstanding set.
+func (g *DependencyGraph) AddDependency(dependent, dependsOn string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.deps[dependent] == nil {
+		g.deps[dependent] = make(map[string]bool)
+	}
+	g.deps[dependent][dependsOn] = true
+
+	if g.dependents[dependsOn] == nil {
+		g.dependents[dependsOn] = make(map[string]bool)
+	}
+	g.dependents[dependsOn][dependent] = true
+}
+
+// Satisfy records that id finished normally, clearing it from every
+// dependent's outstanding dependency set.
+func (g *DependencyGraph) Satisfy(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.clearFromDependentsLocked(id)
+}
+
+// clearFromDependentsLocked removes id from every dependent's deps set
+// and forgets id's own dependents entry, since it's no longer something
+// anything is waiting on (it either completed or was dropped).
+func (g *DependencyGraph) clearFromDependentsLocked(id string) {
+	for dependent := range g.dependents[id] {
+		delete(g.deps[dependent], id)
+	}
+	delete(g.dependents, id)
+}
+
+// Processable reports whether id has no outstanding, unsatisfied
+// dependencies left (or was never given any) and hasn't itself been
+// canceled.
+func (g *DependencyGraph) Processable(id string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.canceled[id] {
+		return false
+	}
+	return len(g.deps[id]) == 0
+}
+
+// IsCanceled reports whether id has been canceled, directly or via
+// cascade from one of its dependencies.
+func (g *DependencyGraph) IsCanceled(id string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.canceled[id]
+}
+
+// Cancel marks id canceled. With cascade true (the historical
+// removeEventAndDeps behavior), every transitive dependent of id is
+// canceled too, since a whole downstream tree built on a canceled event
+// usually can't produce anything meaningful either. With cascade false,
+// only id itself is canceled: its direct dependents are re-evaluated,
+// dropping id from their outstanding dependency set rather than
+// canceling them, so a dependent whose other dependencies are already
+// satisfied becomes Processable instead of being dragged down by
+// canceling one optional enrichment.
+//
+// Cancel returns every event ID actually canceled as a result of this
+// call: just id for a non-cascading cancel, or id plus whatever
+// dependents cascade reached. Canceling an already-canceled id is a
+// no-op returning nil.
+func (g *DependencyGraph) Cancel(id string, cascade bool) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.canceled[id] {
+		return nil
+	}
+
+	if !cascade {
+		g.canceled[id] = true
+		g.clearFromDependentsLocked(id)
+		return []string{id}
+	}
+
+	var canceledIDs []string
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if g.canceled[cur] {
+			continue
+		}
+		g.canceled[cur] = true
+		canceledIDs = append(canceledIDs, cur)
+
+		for dependent := range g.dependents[cur] {
+			queue = append(queue, dependent)
+		}
+		delete(g.dependents, cur)
+	}
+	return canceledIDs
+}
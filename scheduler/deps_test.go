@@ -0,0 +1,87 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scheduler
+
+import "testing"
+
+// TestCancelSelectivePreservesStillSatisfiableDependents confirms that a
+// non-cascading Cancel drops the canceled event from its dependent's
+// outstanding set instead of canceling the dependent outright, so a
+// dependent whose other dependencies are already satisfied becomes
+// Processable rather than being dragged down with it.
+func TestCancelSelectivePreservesStillSatisfiableDependents(t *testing.T) {
+	g := NewDependencyGraph()
+
+	// "downstream" depends on both "required" and "enrichment". "required"
+	// finishes normally; "enrichment" is optional and gets canceled.
+	g.AddDependency("downstream", "required")
+	g.AddDependency("downstream", "enrichment")
+	g.Satisfy("required")
+
+	if g.Processable("downstream") {
+		t.Fatal("expected downstream to not yet be processable before enrichment resolves")
+	}
+
+	canceled := g.Cancel("enrichment", false)
+	if len(canceled) != 1 || canceled[0] != "enrichment" {
+		t.Fatalf("expected only enrichment to be reported canceled, got %v", canceled)
+	}
+	if !g.IsCanceled("enrichment") {
+		t.Fatal("expected enrichment to be marked canceled")
+	}
+	if g.IsCanceled("downstream") {
+		t.Fatal("selective cancel should not have canceled downstream")
+	}
+	if !g.Processable("downstream") {
+		t.Fatal("expected downstream to become processable once its only outstanding dependency was dropped")
+	}
+}
+
+// TestCancelCascadeCancelsTransitiveDependents confirms the historical
+// cascading behavior still works: canceling an event takes down everything
+// that (directly or transitively) depends on it.
+func TestCancelCascadeCancelsTransitiveDependents(t *testing.T) {
+	g := NewDependencyGraph()
+
+	g.AddDependency("child", "root")
+	g.AddDependency("grandchild", "child")
+
+	canceled := g.Cancel("root", true)
+
+	want := map[string]bool{"root": true, "child": true, "grandchild": true}
+	if len(canceled) != len(want) {
+		t.Fatalf("expected 3 events canceled, got %v", canceled)
+	}
+	for _, id := range canceled {
+		if !want[id] {
+			t.Fatalf("unexpected event %q reported canceled", id)
+		}
+	}
+	for id := range want {
+		if !g.IsCanceled(id) {
+			t.Fatalf("expected %q to be canceled", id)
+		}
+	}
+}
+
+// TestCancelIsIdempotent confirms canceling an already-canceled event is a
+// harmless no-op rather than re-reporting it or re-walking dependents.
+func TestCancelIsIdempotent(t *testing.T) {
+	g := NewDependencyGraph()
+
+	g.Cancel("solo", true)
+	if canceled := g.Cancel("solo", true); canceled != nil {
+		t.Fatalf("expected re-canceling an already-canceled event to return nil, got %v", canceled)
+	}
+}
+
+// TestProcessableWithNoDependencies confirms an event that was never given
+// any dependencies is immediately Processable.
+func TestProcessableWithNoDependencies(t *testing.T) {
+	g := NewDependencyGraph()
+
+	if !g.Processable("standalone") {
+		t.Fatal("expected an event with no recorded dependencies to be processable")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// repeatingEvent is the running state behind a Scheduler.ScheduleRepeating
+// call: closing cancel stops its goroutine before its next tick.
+type repeatingEvent struct {
+	cancel chan struct{}
+}
+
+// defaultMinRepeatInterval is the floor ScheduleRepeating enforces on
+// interval when SetMinRepeatInterval hasn't set a different one. It's
+// small enough to never affect a well-behaved caller, existing to protect
+// time.NewTicker (which panics on a non-positive duration) and to give a
+// baseline against a plugin (or malicious input) requesting a near-tight
+// repeat loop that would otherwise saturate the scheduler.
+const defaultMinRepeatInterval = time.Millisecond
+
+// defaultMaxRepeatingEvents caps how many repeating events a single
+// Scheduler runs at once when SetMaxRepeatingEvents hasn't set a
+// different limit, so a caller that keeps scheduling repeats without ever
+// canceling anything can't accumulate an unbounded number of ticking
+// goroutines for one session.
+const defaultMaxRepeatingEvents = 1000
+
+// SetMinRepeatInterval overrides the floor ScheduleRepeating enforces on
+// interval, below which a caller's requested interval is silently raised
+// to the floor rather than honored verbatim. A non-positive value resets
+// it to defaultMinRepeatInterval.
+func (s *Scheduler) SetMinRepeatInterval(min time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minRepeatInterval = min
+}
+
+// SetMaxRepeatingEvents overrides how many repeating events this Scheduler
+// allows to be active at once, above which ScheduleRepeating refuses to
+// schedule another and returns "". A non-positive value resets it to
+// defaultMaxRepeatingEvents.
+func (s *Scheduler) SetMaxRepeatingEvents(max int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxRepeating = max
+}
+
+// ScheduleRepeating runs fn every interval (raised to the configured
+// floor if below it) on its own goroutine until the returned ID is passed
+// to Cancel. It's how a plugin sets up an ongoing probe (e.g. re-checking
+// a flaky lookup) without operators having any way to stop just that one
+// probe short of killing the whole session.
+//
+// ScheduleRepeating returns "" without scheduling anything if this
+// Scheduler already has its configured maximum number of repeating events
+// active; Cancel("") is a harmless no-op, so a caller that doesn't check
+// for this just doesn't get the extra repeat instead of panicking.
+func (s *Scheduler) ScheduleRepeating(interval time.Duration, fn func()) string {
+	s.mu.Lock()
+	floor := s.minRepeatInterval
+	if floor <= 0 {
+		floor = defaultMinRepeatInterval
+	}
+	if interval < floor {
+		interval = floor
+	}
+
+	limit := s.maxRepeating
+	if limit <= 0 {
+		limit = defaultMaxRepeatingEvents
+	}
+	if s.repeating == nil {
+		s.repeating = make(map[string]*repeatingEvent)
+	}
+	if len(s.repeating) >= limit {
+		s.mu.Unlock()
+		s.logger.Warn("scheduler: refusing to schedule another repeating event, at capacity", "limit", limit)
+		return ""
+	}
+
+	id := uuid.New().String()
+	ev := &repeatingEvent{cancel: make(chan struct{})}
+	s.repeating[id] = ev
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-ev.cancel:
+				return
+			}
+		}
+	}()
+	return id
+}
+
+// Cancel stops the repeating event identified by id and reports whether it
+// existed. Its currently in-flight tick, if any, is left to finish; only
+// future ticks are prevented.
+func (s *Scheduler) Cancel(id string) bool {
+	s.mu.Lock()
+	ev, ok := s.repeating[id]
+	if ok {
+		delete(s.repeating, id)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	close(ev.cancel)
+	return true
+}
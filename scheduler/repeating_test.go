@@ -0,0 +1,88 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCancelStopsFutureRepeats(t *testing.T) {
+	s := NewScheduler(nil)
+
+	var ticks int32
+	id := s.ScheduleRepeating(5*time.Millisecond, func() {
+		atomic.AddInt32(&ticks, 1)
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	if !s.Cancel(id) {
+		t.Fatal("expected Cancel to report the event existed")
+	}
+
+	seenAtCancel := atomic.LoadInt32(&ticks)
+	if seenAtCancel == 0 {
+		t.Fatal("expected at least one tick before cancellation")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if got := atomic.LoadInt32(&ticks); got != seenAtCancel {
+		t.Fatalf("expected no further ticks after Cancel, went from %d to %d", seenAtCancel, got)
+	}
+}
+
+func TestCancelUnknownIDReportsFalse(t *testing.T) {
+	s := NewScheduler(nil)
+	if s.Cancel("does-not-exist") {
+		t.Fatal("expected Cancel to report false for an unknown ID")
+	}
+}
+
+// TestScheduleRepeatingRaisesSubFloorIntervalToTheMinimum confirms a
+// caller-requested interval below the configured floor doesn't produce a
+// tight repeat loop: with the floor raised well above what a naive
+// interval would allow, far fewer ticks land in a fixed window than the
+// requested interval alone would predict.
+func TestScheduleRepeatingRaisesSubFloorIntervalToTheMinimum(t *testing.T) {
+	s := NewScheduler(nil)
+	s.SetMinRepeatInterval(50 * time.Millisecond)
+
+	var ticks int32
+	id := s.ScheduleRepeating(time.Millisecond, func() {
+		atomic.AddInt32(&ticks, 1)
+	})
+	defer s.Cancel(id)
+
+	time.Sleep(120 * time.Millisecond)
+
+	// at 1ms (the requested interval) there'd be on the order of 100
+	// ticks by now; at the 50ms floor there should be at most 2 or 3.
+	if got := atomic.LoadInt32(&ticks); got > 5 {
+		t.Fatalf("expected the floor to bound ticks to a handful, got %d", got)
+	}
+}
+
+// TestScheduleRepeatingRefusesBeyondConfiguredCap confirms ScheduleRepeating
+// stops handing out new repeating events once the configured cap is
+// reached, returning "" instead of accumulating an unbounded number of
+// ticking goroutines.
+func TestScheduleRepeatingRefusesBeyondConfiguredCap(t *testing.T) {
+	s := NewScheduler(nil)
+	s.SetMaxRepeatingEvents(2)
+
+	first := s.ScheduleRepeating(time.Hour, func() {})
+	second := s.ScheduleRepeating(time.Hour, func() {})
+	third := s.ScheduleRepeating(time.Hour, func() {})
+
+	if first == "" || second == "" {
+		t.Fatalf("expected the first two calls under the cap to succeed, got %q and %q", first, second)
+	}
+	if third != "" {
+		t.Fatalf("expected the call beyond the cap to be refused, got %q", third)
+	}
+
+	s.Cancel(first)
+	s.Cancel(second)
+}
@@ -0,0 +1,117 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package scheduler tracks the lifecycle state of the events flowing
+// through a session, separate from the registry's dispatch of their
+// handlers.
+package scheduler
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// EventState is a stage in an Event's lifecycle.
+type EventState int
+
+const (
+	EventPending EventState = iota
+	EventRunning
+	EventDone
+	EventFailed
+)
+
+// String renders state for logging.
+func (s EventState) String() string {
+	switch s {
+	case EventPending:
+		return "pending"
+	case EventRunning:
+		return "running"
+	case EventDone:
+		return "done"
+	case EventFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// ProcessConfig controls how the scheduler-level parts of a session's run
+// behave, independent of individual Event lifecycle tracking.
+type ProcessConfig struct {
+	// ReturnIfFound, when non-empty, requests that the session stop as
+	// soon as any in-scope asset of one of these AssetType names is
+	// discovered, instead of running the scan to its normal completion.
+	// Useful for quick-triage callers, e.g. confirming a takeover
+	// candidate resolves, who only need the first qualifying result.
+	ReturnIfFound []string
+}
+
+// Scheduler tracks the state of every Event it owns.
+type Scheduler struct {
+	mu        sync.Mutex
+	states    map[*Event]EventState
+	logger    *slog.Logger
+	repeating map[string]*repeatingEvent
+	// minRepeatInterval floors the interval ScheduleRepeating honors, and
+	// maxRepeating caps how many repeating events may be active at once.
+	// Zero for either selects the corresponding default in repeating.go.
+	// See SetMinRepeatInterval and SetMaxRepeatingEvents.
+	minRepeatInterval time.Duration
+	maxRepeating      int
+}
+
+// NewScheduler returns a Scheduler that logs to logger, or slog.Default if
+// logger is nil.
+func NewScheduler(logger *slog.Logger) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Scheduler{states: make(map[*Event]EventState), logger: logger}
+}
+
+// Event is a unit of scheduled work tracked by a Scheduler.
+type Event struct {
+	ID string
+	// Sched is the Scheduler that owns this Event's state. It's nil for
+	// an Event that was constructed directly rather than obtained from a
+	// Scheduler, e.g. in a test.
+	Sched *Scheduler
+	// Priority orders this Event relative to others queued through a
+	// SeedQueue: a higher Priority is dispatched first. It defaults to
+	// zero, so seeds added without an explicit priority interleave in
+	// the order they were queued rather than jumping ahead of anything.
+	Priority int
+}
+
+// SetEventState records state for e. It used to dereference e inside its
+// own "e == nil" guard branch (logging via e.Sched.logger), guaranteeing a
+// panic on exactly the input the guard was meant to protect against, and
+// assumed e.Sched was always populated; both are checked explicitly here,
+// with SetEventState becoming a safe no-op (aside from a warning) instead
+// of a panic when either is missing.
+func SetEventState(e *Event, state EventState) {
+	if e == nil {
+		slog.Default().Warn("scheduler: SetEventState called with a nil event")
+		return
+	}
+	if e.Sched == nil {
+		slog.Default().Warn("scheduler: event has no scheduler, dropping state update", "event", e.ID)
+		return
+	}
+
+	e.Sched.mu.Lock()
+	defer e.Sched.mu.Unlock()
+	e.Sched.states[e] = state
+	e.Sched.logger.Debug("event state updated", "event", e.ID, "state", state)
+}
+
+// EventState returns e's currently recorded state, if any.
+func (s *Scheduler) EventState(e *Event) (EventState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[e]
+	return st, ok
+}
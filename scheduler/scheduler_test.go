@@ -0,0 +1,27 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scheduler
+
+import "testing"
+
+func TestSetEventStateWithNilEventDoesNotPanic(t *testing.T) {
+	SetEventState(nil, EventRunning)
+}
+
+func TestSetEventStateWithNoSchedulerDoesNotPanic(t *testing.T) {
+	e := &Event{ID: "orphan"}
+	SetEventState(e, EventRunning)
+}
+
+func TestSetEventStateRecordsStateOnItsScheduler(t *testing.T) {
+	s := NewScheduler(nil)
+	e := &Event{ID: "e1", Sched: s}
+
+	SetEventState(e, EventDone)
+
+	state, ok := s.EventState(e)
+	if !ok || state != EventDone {
+		t.Fatalf("expected EventDone recorded, got %v (ok=%v)", state, ok)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scheduler
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// SeedQueue orders the initial seed assets of a session for dispatch. A
+// scan often starts with more seeds than can be worked all at once, and
+// operators want the ones they care most about (e.g. a primary domain over
+// a handful of related ones) to start resolving first rather than waiting
+// behind whatever happened to be listed earlier.
+type SeedQueue struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+// NewSeedQueue returns an empty SeedQueue.
+func NewSeedQueue() *SeedQueue {
+	return &SeedQueue{}
+}
+
+// SeedEventID returns a deterministic Event ID for a seed asset, derived
+// from sessionID and the asset's Key. Re-submitting the same seed to the
+// same session (e.g. after a restart resuming a persisted session) always
+// derives the same ID instead of a fresh one from uuid.New(), so the seed
+// can be recognized as already submitted rather than dispatched as a
+// duplicate, and anything that referenced its Event ID as a dependency
+// keeps resolving after the restart.
+func SeedEventID(sessionID string, asset types.Asset) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(sessionID+"|"+asset.Key())).String()
+}
+
+// NewSeedEvent builds the Event for a seed asset being submitted to
+// sched under sessionID, with its ID derived by SeedEventID so submitting
+// the same seed to the same session twice produces two Events with the
+// same ID rather than two independent ones.
+func NewSeedEvent(sched *Scheduler, sessionID string, asset types.Asset, priority int) *Event {
+	return &Event{ID: SeedEventID(sessionID, asset), Sched: sched, Priority: priority}
+}
+
+// Add queues e for a future Drain.
+func (q *SeedQueue) Add(e *Event) {
+	q.mu.Lock()
+	q.events = append(q.events, e)
+	q.mu.Unlock()
+}
+
+// Drain empties the queue and returns its events ordered by descending
+// Priority, so a caller dispatching them in the returned order works
+// higher-priority seeds first. Events with equal Priority keep the order
+// they were added in, so two seeds nobody prioritized still dispatch
+// deterministically instead of racing.
+func (q *SeedQueue) Drain() []*Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := q.events
+	q.events = nil
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Priority > events[j].Priority
+	})
+	return events
+}
@@ -0,0 +1,90 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestSeedQueueDrainOrdersByDescendingPriority(t *testing.T) {
+	q := NewSeedQueue()
+	low := &Event{ID: "low", Priority: 1}
+	high := &Event{ID: "high", Priority: 10}
+	mid := &Event{ID: "mid", Priority: 5}
+
+	q.Add(low)
+	q.Add(high)
+	q.Add(mid)
+
+	drained := q.Drain()
+	if len(drained) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(drained))
+	}
+	if drained[0].ID != "high" || drained[1].ID != "mid" || drained[2].ID != "low" {
+		t.Fatalf("expected order [high mid low], got [%s %s %s]", drained[0].ID, drained[1].ID, drained[2].ID)
+	}
+}
+
+func TestSeedQueueDrainKeepsInsertionOrderOnTies(t *testing.T) {
+	q := NewSeedQueue()
+	first := &Event{ID: "first", Priority: 3}
+	second := &Event{ID: "second", Priority: 3}
+
+	q.Add(first)
+	q.Add(second)
+
+	drained := q.Drain()
+	if len(drained) != 2 || drained[0].ID != "first" || drained[1].ID != "second" {
+		t.Fatalf("expected equal-priority events to keep insertion order, got %+v", drained)
+	}
+}
+
+func TestSeedQueueDrainEmptiesQueue(t *testing.T) {
+	q := NewSeedQueue()
+	q.Add(&Event{ID: "only"})
+
+	if got := q.Drain(); len(got) != 1 {
+		t.Fatalf("expected 1 event on first drain, got %d", len(got))
+	}
+	if got := q.Drain(); len(got) != 0 {
+		t.Fatalf("expected the second drain to be empty, got %d", len(got))
+	}
+}
+
+// TestNewSeedEventIsIdempotentForTheSameSessionAndAsset confirms
+// submitting the same seed asset to the same session twice derives the
+// same Event ID both times, so a restart re-submitting seeds can
+// recognize one as already dispatched instead of a duplicate.
+func TestNewSeedEventIsIdempotentForTheSameSessionAndAsset(t *testing.T) {
+	sched := NewScheduler(nil)
+	asset := types.FQDN{Name: "www.example.com"}
+
+	first := NewSeedEvent(sched, "session-1", asset, 0)
+	second := NewSeedEvent(sched, "session-1", asset, 0)
+
+	if first.ID != second.ID {
+		t.Fatalf("expected a stable ID for the same seed, got %q and %q", first.ID, second.ID)
+	}
+}
+
+// TestNewSeedEventDiffersAcrossSessionsAndAssets confirms the derived ID
+// is scoped to (sessionID, asset key), not just the asset alone.
+func TestNewSeedEventDiffersAcrossSessionsAndAssets(t *testing.T) {
+	sched := NewScheduler(nil)
+	asset := types.FQDN{Name: "www.example.com"}
+	other := types.FQDN{Name: "other.example.com"}
+
+	sameAssetOtherSession := NewSeedEvent(sched, "session-2", asset, 0)
+	sameSessionOtherAsset := NewSeedEvent(sched, "session-1", other, 0)
+	base := NewSeedEvent(sched, "session-1", asset, 0)
+
+	if base.ID == sameAssetOtherSession.ID {
+		t.Fatal("expected a different session to derive a different ID for the same asset")
+	}
+	if base.ID == sameSessionOtherAsset.ID {
+		t.Fatal("expected a different asset to derive a different ID within the same session")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DBWrite is a single write operation against a session's database, e.g. a
+// plugin's `session.DB().Write(func() error { return realDB.Create(&rec) })`
+// call. Wrapping it in a closure lets BufferedDB retry it later without
+// this package needing to know anything about the underlying driver.
+type DBWrite func() error
+
+// defaultDBWriteBufferSize bounds how many DBWrites BufferedDB queues
+// while the database is unreachable, so a long outage buffers recent
+// writes instead of growing without bound and exhausting memory.
+const defaultDBWriteBufferSize = 1000
+
+// defaultDBMinBackoff and defaultDBMaxBackoff bound BufferedDB's
+// reconnect retry interval: it starts at the min and doubles on every
+// failed Ping, capped at the max, instead of hammering a database that's
+// down or backing off so far that recovery goes unnoticed for minutes.
+const (
+	defaultDBMinBackoff = 100 * time.Millisecond
+	defaultDBMaxBackoff = 30 * time.Second
+)
+
+// defaultDBMaxWriteAttempts bounds how many times flush retries a single
+// buffered write before giving up on it. A write failing on every
+// reconnection isn't a connectivity problem the backoff loop can fix (the
+// connection is back; the write itself is bad), so retrying it forever
+// would wedge every write queued behind it. Once a write hits this limit
+// it's dropped and logged instead of requeued.
+const defaultDBMaxWriteAttempts = 5
+
+// bufferedWrite pairs a queued DBWrite with how many times flush has
+// already tried and failed to replay it, so a write that's simply bad
+// (rather than blocked by connectivity) can be given up on instead of
+// wedging the buffer behind it forever.
+type bufferedWrite struct {
+	write    DBWrite
+	attempts int
+}
+
+// ErrDBWriteBufferFull is returned by Write when the database is down and
+// the buffer is already at its configured limit, so a caller knows a
+// write was actually dropped instead of assuming it's merely delayed.
+var ErrDBWriteBufferFull = fmt.Errorf("sessions: database write buffer is full")
+
+// BufferedDB wraps a session's database connectivity check (ping) with
+// reconnect/backoff monitoring: while ping is failing, writes submitted
+// through Write are queued (up to a bound) instead of attempted and lost,
+// and flushed in order once ping succeeds again. It's the mechanism a
+// session installs via MonitorDatabase so a dropped connection degrades
+// writes into "buffered and delayed" instead of "silently discarded."
+type BufferedDB struct {
+	mu      sync.Mutex
+	ping    func() error
+	down    bool
+	buffer  []bufferedWrite
+	maxSize int
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	backoff    time.Duration
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewBufferedDB returns a BufferedDB that uses ping to test connectivity
+// and buffers up to maxSize writes while ping is failing. A maxSize <= 0
+// selects defaultDBWriteBufferSize. The returned BufferedDB starts
+// healthy; the first failed Write starts watching ping in the background.
+func NewBufferedDB(ping func() error, maxSize int) *BufferedDB {
+	if maxSize <= 0 {
+		maxSize = defaultDBWriteBufferSize
+	}
+	return &BufferedDB{
+		ping: ping, maxSize: maxSize,
+		minBackoff: defaultDBMinBackoff, maxBackoff: defaultDBMaxBackoff,
+		stop: make(chan struct{}), stopped: make(chan struct{}),
+	}
+}
+
+// Down reports whether the database is currently believed unreachable.
+func (b *BufferedDB) Down() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.down
+}
+
+// Buffered reports how many writes are currently queued waiting for the
+// database to recover.
+func (b *BufferedDB) Buffered() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buffer)
+}
+
+// Write runs w immediately if the database is healthy. If the database is
+// already down, or w itself fails, w is queued for replay once the
+// connection recovers and a background reconnect loop is started (if one
+// isn't already running). Queueing fails with ErrDBWriteBufferFull once
+// the buffer is at its configured limit, so a caller can surface that a
+// write was actually lost rather than merely delayed.
+func (b *BufferedDB) Write(w DBWrite) error {
+	b.mu.Lock()
+	down := b.down
+	b.mu.Unlock()
+
+	if !down {
+		if err := w(); err == nil {
+			return nil
+		}
+		b.markDownAndMonitor()
+	}
+	return b.enqueue(w)
+}
+
+func (b *BufferedDB) enqueue(w DBWrite) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.buffer) >= b.maxSize {
+		return ErrDBWriteBufferFull
+	}
+	b.buffer = append(b.buffer, bufferedWrite{write: w})
+	return nil
+}
+
+// markDownAndMonitor flags the database down and, if a reconnect loop
+// isn't already running, starts one.
+func (b *BufferedDB) markDownAndMonitor() {
+	b.mu.Lock()
+	alreadyDown := b.down
+	b.down = true
+	b.backoff = b.minBackoff
+	b.mu.Unlock()
+
+	if !alreadyDown {
+		go b.reconnectLoop()
+	}
+}
+
+// reconnectLoop retries ping on an exponential backoff until it succeeds,
+// then flushes whatever writes queued up in the meantime and marks the
+// database healthy again.
+func (b *BufferedDB) reconnectLoop() {
+	for {
+		b.mu.Lock()
+		wait := b.backoff
+		b.mu.Unlock()
+
+		select {
+		case <-time.After(wait):
+		case <-b.stop:
+			return
+		}
+
+		if b.ping() != nil {
+			b.mu.Lock()
+			b.backoff *= 2
+			if b.backoff > b.maxBackoff {
+				b.backoff = b.maxBackoff
+			}
+			b.mu.Unlock()
+			continue
+		}
+
+		b.flush()
+		return
+	}
+}
+
+// flush replays every buffered write in order, marking the database
+// healthy again once done. It loops rather than draining the buffer just
+// once, since a Write racing with an in-progress flush appends to the
+// same buffer; looping until it's observed empty avoids leaving that
+// write stranded, marked healthy but never actually flushed. A write that
+// fails during flush is re-queued (preserving order) and the database is
+// put back into monitored-down state, unless it has already failed
+// defaultDBMaxWriteAttempts times, in which case it's the write itself
+// that's bad rather than connectivity, and retrying it forever would
+// wedge everything queued behind it; it's dropped and logged instead.
+func (b *BufferedDB) flush() {
+	for {
+		b.mu.Lock()
+		pending := b.buffer
+		b.buffer = nil
+		b.mu.Unlock()
+
+		if len(pending) == 0 {
+			b.mu.Lock()
+			b.down = false
+			b.mu.Unlock()
+			return
+		}
+
+		for i, pw := range pending {
+			if err := pw.write(); err != nil {
+				pw.attempts++
+				if pw.attempts >= defaultDBMaxWriteAttempts {
+					slog.Default().Error("dropping database write after repeated failures",
+						"attempts", pw.attempts, "error", err)
+					continue
+				}
+				remaining := append([]bufferedWrite{pw}, pending[i+1:]...)
+				b.mu.Lock()
+				b.buffer = append(remaining, b.buffer...)
+				b.mu.Unlock()
+				go b.reconnectLoop()
+				return
+			}
+		}
+	}
+}
+
+// Stop halts any in-progress reconnect loop without flushing whatever is
+// still buffered, e.g. because the owning session is being torn down.
+func (b *BufferedDB) Stop() {
+	select {
+	case <-b.stopped:
+		return
+	default:
+		close(b.stopped)
+		close(b.stop)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestBufferedDBFlushesQueuedWritesOnRecovery simulates a transient outage:
+// the first write fails and is buffered, ping keeps failing for a couple
+// of attempts, and once it starts succeeding the buffered write (plus a
+// second one submitted while still down) both flush through in order.
+func TestBufferedDBFlushesQueuedWritesOnRecovery(t *testing.T) {
+	var pingAttempts int32
+	up := int32(0) // flipped to 1 once the simulated outage ends
+
+	ping := func() error {
+		atomic.AddInt32(&pingAttempts, 1)
+		if atomic.LoadInt32(&up) == 0 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	db := NewBufferedDB(ping, 10)
+	db.minBackoff = time.Millisecond
+	db.maxBackoff = 5 * time.Millisecond
+
+	var order []int
+	firstWrite := func() error {
+		if atomic.LoadInt32(&up) == 0 {
+			return errors.New("write: connection refused")
+		}
+		order = append(order, 1)
+		return nil
+	}
+	if err := db.Write(firstWrite); err != nil {
+		t.Fatalf("expected the first write to be buffered, not rejected: %v", err)
+	}
+	if !db.Down() {
+		t.Fatal("expected the database to be marked down after the first failed write")
+	}
+
+	if err := db.Write(func() error { order = append(order, 2); return nil }); err != nil {
+		t.Fatalf("expected the second write to be buffered while still down: %v", err)
+	}
+	if got := db.Buffered(); got != 2 {
+		t.Fatalf("expected 2 buffered writes, got %d", got)
+	}
+
+	// End the simulated outage and wait for the reconnect loop to notice.
+	atomic.StoreInt32(&up, 1)
+
+	deadline := time.After(2 * time.Second)
+	for db.Down() {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for BufferedDB to recover")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	if got := db.Buffered(); got != 0 {
+		t.Fatalf("expected the buffer to drain once recovered, got %d still queued", got)
+	}
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("expected both buffered writes to flush in order, got %v", order)
+	}
+
+	db.Stop()
+}
+
+// TestBufferedDBFlushDropsPermanentlyFailingWrite confirms a write that
+// keeps failing even after the database recovers is eventually dropped
+// instead of wedging the buffer (and every write behind it) in a
+// permanent reconnect loop.
+func TestBufferedDBFlushDropsPermanentlyFailingWrite(t *testing.T) {
+	ping := func() error { return nil }
+
+	db := NewBufferedDB(ping, 10)
+	db.minBackoff = time.Millisecond
+	db.maxBackoff = 5 * time.Millisecond
+
+	var goodRan int32
+	db.markDownAndMonitor()
+	if err := db.enqueue(func() error { return errors.New("always fails") }); err != nil {
+		t.Fatalf("expected the bad write to be buffered: %v", err)
+	}
+	if err := db.enqueue(func() error { atomic.AddInt32(&goodRan, 1); return nil }); err != nil {
+		t.Fatalf("expected the good write to be buffered: %v", err)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for db.Down() {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for BufferedDB to recover")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	if got := atomic.LoadInt32(&goodRan); got != 1 {
+		t.Fatalf("expected the write behind the bad one to still run, got %d runs", got)
+	}
+	if got := db.Buffered(); got != 0 {
+		t.Fatalf("expected the permanently-failing write to be dropped, not left queued, got %d", got)
+	}
+
+	db.Stop()
+}
+
+// TestBufferedDBWriteBufferFullReportsError confirms Write reports
+// ErrDBWriteBufferFull instead of silently dropping a write once the
+// buffer is at its configured limit.
+func TestBufferedDBWriteBufferFullReportsError(t *testing.T) {
+	ping := func() error { return errors.New("still down") }
+	db := NewBufferedDB(ping, 1)
+	db.minBackoff = time.Hour // never actually retries during this test
+
+	if err := db.Write(func() error { return errors.New("boom") }); err != nil {
+		t.Fatalf("expected the first write to be buffered, got %v", err)
+	}
+	if err := db.Write(func() error { return nil }); err != ErrDBWriteBufferFull {
+		t.Fatalf("expected ErrDBWriteBufferFull once the buffer is full, got %v", err)
+	}
+
+	db.Stop()
+}
+
+// TestSessionMonitorDatabaseSurfacesStatusInStats confirms a session's
+// StatsSnapshot reports DBDown/DBBuffered once MonitorDatabase is
+// installed and its underlying ping starts failing.
+func TestSessionMonitorDatabaseSurfacesStatusInStats(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	db := s.MonitorDatabase(func() error { return errors.New("down") }, 5)
+	db.minBackoff = time.Hour
+
+	if s.DBDown() {
+		t.Fatal("expected the session's database to start healthy")
+	}
+
+	if err := s.DB().Write(func() error { return errors.New("write failed") }); err != nil {
+		t.Fatalf("expected the write to be buffered, got %v", err)
+	}
+
+	snap := s.StatsSnapshot()
+	if !snap.DBDown {
+		t.Fatal("expected StatsSnapshot to report the database as down")
+	}
+	if snap.DBBuffered != 1 {
+		t.Fatalf("expected 1 buffered write reported, got %d", snap.DBBuffered)
+	}
+
+	db.Stop()
+}
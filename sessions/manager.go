@@ -0,0 +1,77 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrTooManySessions is returned by Manager.CreateSession when the
+// configured concurrent-session limit has already been reached.
+var ErrTooManySessions = errors.New("sessions: maximum concurrent sessions reached")
+
+// Manager owns the set of live sessions for a running engine and bounds
+// how many can exist at once. Nothing used to cap this, so a multi-tenant
+// engine could be driven into OOM by spawning sessions faster than they
+// finish, each with its own graph, guess queue, and scheduler state.
+type Manager struct {
+	mu   sync.Mutex
+	max  int
+	sess map[string]*Session
+}
+
+// NewManager returns a Manager that allows at most max concurrent
+// sessions. A max of zero or less means unlimited.
+func NewManager(max int) *Manager {
+	return &Manager{max: max, sess: make(map[string]*Session)}
+}
+
+// CreateSession allocates a new session owned by owner, unless doing so
+// would exceed the manager's concurrent-session limit, in which case it
+// returns ErrTooManySessions.
+func (m *Manager) CreateSession(owner string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.max > 0 && len(m.sess) >= m.max {
+		return nil, ErrTooManySessions
+	}
+
+	s := NewSession(uuid.New().String(), owner)
+	m.sess[s.Token] = s
+	return s, nil
+}
+
+// Get returns the session for token, if one is live.
+func (m *Manager) Get(token string) (*Session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sess[token]
+	return s, ok
+}
+
+// Kill stops the session for token, if one is live, and frees its slot so
+// a new CreateSession call can succeed even at the limit.
+func (m *Manager) Kill(token string) {
+	m.mu.Lock()
+	s, ok := m.sess[token]
+	if ok {
+		delete(m.sess, token)
+	}
+	m.mu.Unlock()
+
+	if ok {
+		s.Kill()
+	}
+}
+
+// Count returns the number of live sessions.
+func (m *Manager) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sess)
+}
@@ -0,0 +1,40 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import "testing"
+
+func TestManagerRejectsSessionsPastLimitThenAcceptsAfterKill(t *testing.T) {
+	m := NewManager(2)
+
+	first, err := m.CreateSession("owner")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := m.CreateSession("owner"); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	if _, err := m.CreateSession("owner"); err != ErrTooManySessions {
+		t.Fatalf("expected ErrTooManySessions at the limit, got %v", err)
+	}
+
+	m.Kill(first.Token)
+	if _, ok := m.Get(first.Token); ok {
+		t.Fatal("expected the killed session to be removed from the manager")
+	}
+
+	if _, err := m.CreateSession("owner"); err != nil {
+		t.Fatalf("expected CreateSession to succeed again after a session was killed: %v", err)
+	}
+}
+
+func TestManagerWithNoLimitNeverRejects(t *testing.T) {
+	m := NewManager(0)
+	for i := 0; i < 50; i++ {
+		if _, err := m.CreateSession("owner"); err != nil {
+			t.Fatalf("unexpected error at iteration %d: %v", i, err)
+		}
+	}
+}
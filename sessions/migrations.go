@@ -0,0 +1,67 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"fmt"
+	"sync"
+)
+
+// migrationState serializes schema migrations per DSN and remembers each
+// DSN's outcome, so concurrent sessions opening the same database file
+// don't race each other's schema changes, and a DSN that already migrated
+// (successfully or not) doesn't pay for a second attempt.
+type migrationState struct {
+	mu      sync.Mutex
+	locks   map[string]*sync.Mutex
+	results map[string]error
+}
+
+var migrations = &migrationState{
+	locks:   make(map[string]*sync.Mutex),
+	results: make(map[string]error),
+}
+
+func (m *migrationState) lockFor(dsn string) *sync.Mutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.locks[dsn]
+	if !ok {
+		l = &sync.Mutex{}
+		m.locks[dsn] = l
+	}
+	return l
+}
+
+func (m *migrationState) run(dsn string, apply func() error) error {
+	l := m.lockFor(dsn)
+	l.Lock()
+	defer l.Unlock()
+
+	m.mu.Lock()
+	if err, done := m.results[dsn]; done {
+		m.mu.Unlock()
+		return err
+	}
+	m.mu.Unlock()
+
+	err := apply()
+	if err != nil {
+		err = fmt.Errorf("sessions: failed to migrate database %q: %w (if another session is migrating this same file, wait for it to finish before retrying)", dsn, err)
+	}
+
+	m.mu.Lock()
+	m.results[dsn] = err
+	m.mu.Unlock()
+	return err
+}
+
+// migrate runs apply against dsn's schema exactly once. A second session
+// created against the same dsn while the first is still migrating blocks
+// here instead of racing it; once migration completes, every caller
+// (including ones that blocked) gets the same cached result.
+func migrate(dsn string, apply func() error) error {
+	return migrations.run(dsn, apply)
+}
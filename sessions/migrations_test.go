@@ -0,0 +1,89 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+)
+
+func TestTwoSessionsAgainstOneNewDBMigrateExactlyOnce(t *testing.T) {
+	dsn := fmt.Sprintf("test-dsn-%p.db", t)
+
+	var applied int32
+	apply := func() error {
+		atomic.AddInt32(&applied, 1)
+		time.Sleep(20 * time.Millisecond) // give the second caller a chance to race in
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := NewSessionWithDB("token", "owner", dsn, apply)
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected migration error: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&applied); got != 1 {
+		t.Fatalf("expected the migration to run exactly once, ran %d times", got)
+	}
+}
+
+// TestNewSessionWithDatabaseInMemorySkipsMigration confirms an ephemeral
+// InMemory session never runs the migration apply func against a DSN, so
+// no file is created for a quick one-off scan.
+func TestNewSessionWithDatabaseInMemorySkipsMigration(t *testing.T) {
+	var applied int32
+	apply := func() error {
+		atomic.AddInt32(&applied, 1)
+		return nil
+	}
+
+	s, err := NewSessionWithDatabase("token", "owner", config.DatabaseConfig{
+		Primary: fmt.Sprintf("should-never-be-created-%p.db", t), InMemory: true,
+	}, apply)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s == nil {
+		t.Fatal("expected a usable in-memory session")
+	}
+	if got := atomic.LoadInt32(&applied); got != 0 {
+		t.Fatalf("expected InMemory to skip migration entirely, ran %d times", got)
+	}
+}
+
+// TestNewSessionWithDatabaseDelegatesToDBMigration confirms a non-InMemory
+// config still runs the migration against Primary, same as NewSessionWithDB.
+func TestNewSessionWithDatabaseDelegatesToDBMigration(t *testing.T) {
+	dsn := fmt.Sprintf("test-dsn-delegate-%p.db", t)
+
+	var applied int32
+	apply := func() error {
+		atomic.AddInt32(&applied, 1)
+		return nil
+	}
+
+	if _, err := NewSessionWithDatabase("token", "owner", config.DatabaseConfig{Primary: dsn}, apply); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&applied); got != 1 {
+		t.Fatalf("expected the migration to run once, ran %d times", got)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestReadOnlySessionRejectsWrites(t *testing.T) {
+	populated := graph.NewGraph()
+	populated.UpsertAsset(types.FQDN{Name: "www.example.com"})
+
+	s := NewReadOnlySession("token", "owner", populated)
+
+	assets, _ := s.Results("")
+	if len(assets) != 1 {
+		t.Fatalf("expected the read-only session to see the populated data, got %d assets", len(assets))
+	}
+
+	if err := s.Graph().UpsertAsset(types.FQDN{Name: "new.example.com"}); err != graph.ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly from a write against a read-only session, got %v", err)
+	}
+	if assets, _ := s.Results(""); len(assets) != 1 {
+		t.Fatalf("expected the rejected write to leave the graph unchanged, got %d assets", len(assets))
+	}
+}
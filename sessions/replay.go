@@ -0,0 +1,29 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"github.com/owasp-amass/engine/et"
+	"github.com/owasp-amass/engine/registry"
+)
+
+// ReplaySession dispatches every asset already discovered by from through
+// reg, so a plugin under development can be exercised against real,
+// previously-captured data (typically loaded via NewReadOnlySession against
+// a stored session's database) instead of re-running network-heavy
+// discovery to get comparable input. Assets are dispatched in whatever
+// order from.Results returns them, one registry.Event per asset; replay
+// doesn't attempt to reconstruct discovery order or parent relationships,
+// so a handler relying on Meta's "parent" hint won't see one here.
+//
+// It returns the combined errors reg.Dispatch produced across every asset.
+func ReplaySession(from *Session, reg *registry.Registry) []error {
+	assets, _ := from.Results("")
+
+	var errs []error
+	for _, a := range assets {
+		errs = append(errs, reg.Dispatch(et.NewAssetEvent(a, nil))...)
+	}
+	return errs
+}
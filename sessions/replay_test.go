@@ -0,0 +1,54 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"testing"
+
+	"github.com/owasp-amass/engine/registry"
+	"github.com/owasp-amass/engine/types"
+)
+
+// TestReplaySessionDispatchesStoredAssetsToNewHandler confirms
+// ReplaySession feeds a session's already-discovered assets into a fresh
+// Registry, so a handler registered on it sees every one of them without
+// the session having re-run discovery.
+func TestReplaySessionDispatchesStoredAssetsToNewHandler(t *testing.T) {
+	s := NewSession("token", "owner")
+	s.Graph().UpsertAsset(types.FQDN{Name: "owasp.org"})
+	s.Graph().UpsertAsset(types.FQDN{Name: "www.owasp.org"})
+	s.Graph().UpsertAsset(types.IPAddress{Address: "1.2.3.4", Type: "IPv4"})
+
+	var seen []types.Asset
+	reg := registry.NewRegistry()
+	reg.RegisterObserver(func(e *registry.Event) {
+		seen = append(seen, e.Asset)
+	})
+
+	if errs := ReplaySession(s, reg); len(errs) != 0 {
+		t.Fatalf("expected no dispatch errors, got %v", errs)
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected the stub handler to observe 3 replayed assets, got %d", len(seen))
+	}
+}
+
+// TestReplaySessionOnEmptySessionDispatchesNothing confirms a session with
+// no discovered assets replays cleanly with no dispatches at all.
+func TestReplaySessionOnEmptySessionDispatchesNothing(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	var count int
+	reg := registry.NewRegistry()
+	reg.RegisterObserver(func(e *registry.Event) {
+		count++
+	})
+
+	if errs := ReplaySession(s, reg); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if count != 0 {
+		t.Fatalf("expected no dispatches, got %d", count)
+	}
+}
@@ -0,0 +1,698 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package sessions manages the lifetime of engine sessions: one per scan
+// initiated through the API, holding that scan's configuration, graph, and
+// runtime statistics.
+package sessions
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/graph"
+	"github.com/owasp-amass/engine/resolve"
+	"github.com/owasp-amass/engine/scheduler"
+	"github.com/owasp-amass/engine/support"
+	"github.com/owasp-amass/engine/types"
+)
+
+// Session represents a single scan and everything discovered during it.
+type Session struct {
+	Token string
+	// Owner identifies the caller that created the session, used to
+	// enforce per-session authorization at the API layer.
+	Owner string
+	Stats Stats
+
+	mu     sync.RWMutex
+	g      *graph.Graph
+	done   bool
+	ctx    context.Context
+	cancel context.CancelFunc
+	// guesses is this session's own brute-force guess queue. It used to
+	// be a single package-global queue shared by every session, so one
+	// session's guess volume competed with every other's, and killing a
+	// session couldn't stop guesses already queued on its behalf.
+	guesses *support.GuessQueue
+	// timedOut is set when MaxDuration elapsed and enforceTimeout killed
+	// the session itself, distinguishing that from an operator-initiated
+	// Kill in Stats.
+	timedOut bool
+	// sched tracks this session's own repeating events (e.g. a plugin's
+	// periodic re-check of a flaky lookup), so one can be canceled by ID
+	// without killing the whole session.
+	sched *scheduler.Scheduler
+	// cursors holds each paginating plugin's most recently saved
+	// pagination cursor, keyed by plugin name, so a session interrupted
+	// mid-pagination can resume from where it left off instead of
+	// restarting the source from its first page and re-consuming quota.
+	cursors map[string]string
+	// transforms is the authoritative from->to/source enablement rules
+	// for this session, consulted through TransformEnabled instead of
+	// each plugin hand-rolling its own all/exclude switch.
+	transforms *support.TransformConfig
+	// seen tracks asset keys this session (or, once warmed, a prior
+	// session sharing the same underlying database) has already
+	// processed, so a plugin can skip redundant lookups via AlreadySeen
+	// instead of every session re-discovering and re-reporting the same
+	// overlapping scope from scratch.
+	seen map[string]bool
+	// forceReprocess disables the seen-key check entirely: every key
+	// reports as unseen, e.g. because the caller explicitly wants a full
+	// refresh of previously scanned scope rather than a warm-started
+	// incremental one.
+	forceReprocess bool
+	// returnIfFound is the set of AssetType names (from
+	// scheduler.ProcessConfig.ReturnIfFound) that trigger an early Kill
+	// via CheckReturnIfFound. Nil/empty disables the mode, the historical
+	// run-to-completion behavior.
+	returnIfFound map[string]bool
+	// foundAsset is the asset that satisfied returnIfFound, if any, kept
+	// so a caller can report what stopped the session early.
+	foundAsset types.Asset
+	// resolutions is this session's shared DNS resolution cache, so a
+	// name one plugin resolves through resolve.PerformCachedQueries isn't
+	// re-queried by another plugin resolving the same name within the
+	// same session.
+	resolutions *resolve.ResultCache
+	// requestBudget, if set, is the session-level combined DNS+HTTP
+	// request rate limiter every plugin/handler should draw from, e.g.
+	// assigning it to both a resolve.Pool's Budget and an
+	// http.RequestConfig's Budget so the two protocols share one ceiling
+	// instead of each capping itself independently. Nil disables it.
+	requestBudget *support.RateLimiter
+	// pluginOptions holds each plugin's free-form settings, keyed by
+	// plugin name, so a plugin can read its own options via
+	// PluginOptions without the config schema needing a dedicated field
+	// for every plugin-specific knob.
+	pluginOptions config.PluginOptions
+	// startedAt records when the session was created, so Summary can
+	// report elapsed Duration without every caller threading a start
+	// time through by hand.
+	startedAt time.Time
+	// db is this session's database health/write-buffering wrapper,
+	// installed by MonitorDatabase. Nil unless a caller opted in, in
+	// which case DB() always returns it: plugins route writes through
+	// DB().Write instead of hitting the underlying connection directly,
+	// so a mid-session outage buffers and retries instead of losing
+	// results.
+	db *BufferedDB
+	// goroutineBudget, if set, is the session-level ceiling on
+	// concurrently running goroutines drawn from major goroutine-
+	// launching sites (guess processing, DNS sweeps, pipeline tasks),
+	// installed by NewSessionWithGoroutineBudget from
+	// config.WorkerConfig.MaxConcurrentGoroutines. Nil leaves goroutine
+	// count governed by each site's own concurrency setting alone.
+	goroutineBudget *support.WorkerBudget
+}
+
+// DedupConfig controls how a session deduplicates results against other
+// sessions sharing the same underlying database, so two scans of
+// overlapping scope don't repeat the same expensive lookups and re-report
+// identical results as if they were new.
+type DedupConfig struct {
+	// WarmKeys are the asset keys already known from the shared DB tier,
+	// e.g. another session's prior findings for the same scope, loaded
+	// once at session creation so AlreadySeen treats them as seen
+	// without this session having (re)discovered them itself.
+	WarmKeys []string
+	// ForceReprocess disables deduplication entirely: AlreadySeen always
+	// reports false, so every key is treated as unseen even if it was
+	// supplied via WarmKeys or seen earlier in this same session.
+	ForceReprocess bool
+}
+
+// Stats tracks coarse progress counters for a session, plus a per-plugin
+// breakdown of how many assets each source actually contributed. The
+// breakdown is what lets users judge whether a paid API's results are
+// worth its cost.
+type Stats struct {
+	NamesDiscovered    int
+	IPsDiscovered      int
+	WorkItemsTotal     int
+	WorkItemsCompleted int
+	// Plugins breaks the above totals down by the plugin that contributed
+	// them, so users can judge which sources are worth their API cost.
+	// Callers must go through Session.RecordPluginResult to mutate it;
+	// Stats itself holds no lock so it stays safe to copy by value (the
+	// API layer returns it directly from the sessionStats query).
+	Plugins map[string]PluginStats
+	// TimedOut reports whether the session was stopped by its own
+	// config.SessionConfig.MaxDuration rather than an explicit Kill or
+	// running to completion.
+	TimedOut bool
+	// RequestsConsumed is the number of tokens drawn from this session's
+	// RequestBudget so far, combined across DNS and HTTP traffic. It's
+	// zero for a session created without NewSessionWithRequestBudget.
+	RequestsConsumed int64
+	// DBDown reports whether MonitorDatabase's health check currently
+	// considers the session's database unreachable. Always false for a
+	// session that never called MonitorDatabase.
+	DBDown bool
+	// DBBuffered counts writes queued by DB().Write waiting for the
+	// database to recover, so an operator can judge outage severity
+	// (and buffer headroom) without reading logs.
+	DBBuffered int
+}
+
+// PluginStats counts the assets and errors a single plugin contributed to
+// a session.
+type PluginStats struct {
+	AssetsDiscovered int
+	Errors           int
+}
+
+// NewSession creates a session identified by token, owned by owner, with an
+// empty graph. The returned session's Context is canceled when Kill is
+// called, so plugin callers can pass it into calls like
+// http.RequestWebPage instead of context.TODO() and have in-flight I/O
+// unblock promptly when the session ends.
+func NewSession(token, owner string) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Session{
+		Token: token, Owner: owner, g: graph.NewGraph(),
+		ctx: ctx, cancel: cancel, guesses: support.NewGuessQueue(),
+		sched: scheduler.NewScheduler(nil), transforms: support.NewTransformConfig(),
+		resolutions: resolve.NewResultCache(0), startedAt: time.Now(),
+	}
+}
+
+// NewSessionWithDB creates a session like NewSession, but first runs apply
+// as dsn's schema migration. Migrations against the same dsn are
+// serialized and deduplicated across concurrent callers, so two sessions
+// racing to open the same new database file don't run conflicting schema
+// changes against each other.
+func NewSessionWithDB(token, owner, dsn string, apply func() error) (*Session, error) {
+	if err := migrate(dsn, apply); err != nil {
+		return nil, err
+	}
+	return NewSession(token, owner), nil
+}
+
+// NewSessionWithDatabase creates a session backed by cfg's database
+// settings: cfg.InMemory skips DSN file creation and schema migration
+// entirely, returning a plain in-memory NewSession for fast ephemeral
+// scans where persistence isn't wanted; otherwise it delegates to
+// NewSessionWithDB against cfg.Primary, migrated by apply as usual.
+func NewSessionWithDatabase(token, owner string, cfg config.DatabaseConfig, apply func() error) (*Session, error) {
+	if cfg.InMemory {
+		return NewSession(token, owner), nil
+	}
+	return NewSessionWithDB(token, owner, cfg.Primary, apply)
+}
+
+// NewReadOnlySession wraps from's data in a read-only Session for
+// reporting and analysis workflows: no migrations run, and every write
+// through the returned session's Graph is rejected with graph.ErrReadOnly
+// instead of risking a query workflow accidentally mutating a completed
+// scan's results.
+func NewReadOnlySession(token, owner string, from *graph.Graph) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Session{
+		Token: token, Owner: owner, g: graph.NewReadOnlyGraph(from),
+		ctx: ctx, cancel: cancel, guesses: support.NewGuessQueue(),
+		sched: scheduler.NewScheduler(nil), transforms: support.NewTransformConfig(),
+		startedAt: time.Now(),
+	}
+}
+
+// NewSessionWithTimeout creates a session like NewSession, but automatically
+// Kills it if it's still running after cfg.MaxDuration, preserving whatever
+// partial results it already has instead of running unbounded. A zero
+// MaxDuration disables the limit.
+func NewSessionWithTimeout(token, owner string, cfg config.SessionConfig) *Session {
+	s := NewSession(token, owner)
+	if cfg.MaxDuration > 0 {
+		go s.enforceTimeout(cfg.MaxDuration)
+	}
+	return s
+}
+
+// NewSessionWithDedup creates a session like NewSession, but warm-started
+// from cfg.WarmKeys so AlreadySeen reports true for asset keys another
+// session sharing the same database already discovered, instead of this
+// session repeating that work from a cold start. cfg.ForceReprocess
+// disables that warm start (and any dedup this session records itself)
+// when a full refresh is wanted instead of an incremental one.
+func NewSessionWithDedup(token, owner string, cfg DedupConfig) *Session {
+	s := NewSession(token, owner)
+	s.forceReprocess = cfg.ForceReprocess
+	if !cfg.ForceReprocess && len(cfg.WarmKeys) > 0 {
+		s.seen = make(map[string]bool, len(cfg.WarmKeys))
+		for _, key := range cfg.WarmKeys {
+			s.seen[key] = true
+		}
+	}
+	return s
+}
+
+// NewSessionWithReturnIfFound creates a session like NewSession, but Kills
+// itself the first time CheckReturnIfFound is called with an asset whose
+// type is in cfg.ReturnIfFound, instead of running to normal completion.
+// An empty cfg.ReturnIfFound disables the mode.
+func NewSessionWithReturnIfFound(token, owner string, cfg scheduler.ProcessConfig) *Session {
+	s := NewSession(token, owner)
+	if len(cfg.ReturnIfFound) > 0 {
+		s.returnIfFound = make(map[string]bool, len(cfg.ReturnIfFound))
+		for _, t := range cfg.ReturnIfFound {
+			s.returnIfFound[t] = true
+		}
+	}
+	return s
+}
+
+// NewSessionWithDNSCache creates a session like NewSession, but caps its
+// resolution cache entries' lifetime at cfg.MaxCacheTTL instead of trusting
+// each answer's own record TTL alone. A zero MaxCacheTTL leaves the
+// default uncapped behavior in place.
+func NewSessionWithDNSCache(token, owner string, cfg config.DNSConfig) *Session {
+	s := NewSession(token, owner)
+	if cfg.MaxCacheTTL > 0 {
+		s.resolutions = resolve.NewResultCache(cfg.MaxCacheTTL)
+	}
+	return s
+}
+
+// Resolutions returns this session's shared DNS resolution cache, meant to
+// be passed to resolve.PerformCachedQueries by every plugin/handler
+// resolving on this session's behalf so a name resolved once is reused
+// instead of re-queried.
+func (s *Session) Resolutions() *resolve.ResultCache {
+	return s.resolutions
+}
+
+// NewSessionWithRequestBudget creates a session like NewSession, but caps
+// its combined outbound DNS and HTTP request rate at
+// cfg.MaxRequestsPerSecond, satisfying rules of engagement that cap total
+// traffic to a target rather than capping each protocol separately. A
+// MaxRequestsPerSecond <= 0 leaves the request rate uncapped.
+func NewSessionWithRequestBudget(token, owner string, cfg config.SessionConfig) *Session {
+	s := NewSession(token, owner)
+	if cfg.MaxRequestsPerSecond > 0 {
+		s.requestBudget = support.NewRateLimiter(cfg.MaxRequestsPerSecond)
+	}
+	return s
+}
+
+// RequestBudget returns this session's shared combined DNS+HTTP rate
+// limiter, or nil if the session wasn't created with
+// NewSessionWithRequestBudget. Callers pass it into both a resolve.Pool's
+// Budget and an http.RequestConfig's Budget so the two protocols draw from
+// one combined ceiling.
+func (s *Session) RequestBudget() *support.RateLimiter {
+	return s.requestBudget
+}
+
+// NewSessionWithGoroutineBudget creates a session like NewSession, but
+// caps the total number of goroutines every opted-in site (e.g. guess
+// processing via ProcessGuesses) may run at once, combined, at
+// cfg.MaxConcurrentGoroutines. A MaxConcurrentGoroutines <= 0 leaves
+// goroutine count uncapped, matching the historical behavior.
+func NewSessionWithGoroutineBudget(token, owner string, cfg config.WorkerConfig) *Session {
+	s := NewSession(token, owner)
+	if cfg.MaxConcurrentGoroutines > 0 {
+		s.goroutineBudget = support.NewWorkerBudget(cfg.MaxConcurrentGoroutines)
+	}
+	return s
+}
+
+// GoroutineBudget returns this session's shared goroutine ceiling, or nil
+// if the session wasn't created with NewSessionWithGoroutineBudget.
+// Callers pass it into any site's own Budget field (e.g.
+// support.GuessConfig.Budget) so every opted-in site draws from one
+// combined ceiling instead of each capping itself independently.
+func (s *Session) GoroutineBudget() *support.WorkerBudget {
+	return s.goroutineBudget
+}
+
+// NewSessionWithPluginOptions creates a session like NewSession, but
+// carries opts for plugins to read back via PluginOptions instead of
+// every per-plugin setting needing a dedicated field on config.Config.
+func NewSessionWithPluginOptions(token, owner string, opts config.PluginOptions) *Session {
+	s := NewSession(token, owner)
+	s.pluginOptions = opts
+	return s
+}
+
+// PluginOptions returns the free-form options configured for the plugin
+// named name, or nil if none were configured for it. Callers shouldn't
+// mutate the returned map.
+func (s *Session) PluginOptions(name string) map[string]string {
+	return s.pluginOptions[name]
+}
+
+// CheckReturnIfFound reports whether asset satisfies this session's
+// configured ReturnIfFound types. The first time it does, CheckReturnIfFound
+// records asset (retrievable via SatisfyingAsset) and Kills the session so
+// it stops promptly instead of continuing to run; every call after that
+// first satisfying one is a no-op returning false, since the session is
+// already stopping. It always returns false when the session wasn't
+// created with NewSessionWithReturnIfFound or asset's type isn't one of
+// its configured types.
+func (s *Session) CheckReturnIfFound(asset types.Asset) bool {
+	s.mu.Lock()
+	if len(s.returnIfFound) == 0 || !s.returnIfFound[string(asset.AssetType())] || s.foundAsset != nil {
+		s.mu.Unlock()
+		return false
+	}
+	s.foundAsset = asset
+	s.mu.Unlock()
+
+	s.Kill()
+	return true
+}
+
+// SatisfyingAsset returns the asset that satisfied this session's
+// ReturnIfFound condition, if CheckReturnIfFound has recorded one yet.
+func (s *Session) SatisfyingAsset() (types.Asset, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.foundAsset, s.foundAsset != nil
+}
+
+// AlreadySeen reports whether key was already processed, either warm-
+// started from a shared database via NewSessionWithDedup or recorded
+// earlier in this session via MarkSeen. It always reports false when the
+// session was created with DedupConfig.ForceReprocess, so a caller wanting
+// a full refresh never has results suppressed as duplicates.
+func (s *Session) AlreadySeen(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.forceReprocess {
+		return false
+	}
+	return s.seen[key]
+}
+
+// MarkSeen records key as processed for future AlreadySeen checks within
+// this session. It's a no-op when the session was created with
+// DedupConfig.ForceReprocess, since that session has opted out of
+// deduplication entirely.
+func (s *Session) MarkSeen(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.forceReprocess {
+		return
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]bool)
+	}
+	s.seen[key] = true
+}
+
+// enforceTimeout kills s once d has elapsed, unless s finishes (its
+// Context is canceled by an explicit Kill) first.
+func (s *Session) enforceTimeout(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		s.mu.Lock()
+		s.timedOut = true
+		s.mu.Unlock()
+		s.Kill()
+	case <-s.ctx.Done():
+	}
+}
+
+// QueueGuess adds a brute-force candidate to this session's own guess
+// queue.
+func (s *Session) QueueGuess(g support.Guess) {
+	s.guesses.Queue(g)
+}
+
+// ProcessGuesses drains this session's guess queue using cfg and attempt.
+// It's bound to the session's own Context, so it stops launching new
+// attempts as soon as Kill is called instead of racing every other
+// session's guesses for a shared budget.
+//
+// If cfg.AlreadyResolved is left unset, it defaults to skipping any guess
+// whose name is already known-resolved in the session's DNS result cache
+// (Resolutions().IsResolved), so passive plugins don't spend a
+// concurrency slot and an untrusted-pool query re-confirming something
+// the cache already has a positive answer for. A caller that wants every
+// guess re-checked regardless can set cfg.AlreadyResolved to a func that
+// always returns false. Likewise, if cfg.Budget is left unset, it
+// defaults to the session's own GoroutineBudget (when the session was
+// created with NewSessionWithGoroutineBudget), so guess processing
+// automatically shares the session-wide goroutine ceiling instead of a
+// caller needing to remember to pass it explicitly.
+func (s *Session) ProcessGuesses(cfg support.GuessConfig, attempt func(ctx context.Context, g support.Guess) error) {
+	if cfg.AlreadyResolved == nil && s.resolutions != nil {
+		cfg.AlreadyResolved = s.resolutions.IsResolved
+	}
+	if cfg.Budget == nil && s.goroutineBudget != nil {
+		cfg.Budget = s.goroutineBudget
+	}
+	s.guesses.Process(s.ctx, cfg, attempt)
+}
+
+// ScheduleRepeating runs fn every interval for the lifetime of the session
+// (or until CancelEvent is called with the returned ID), and returns that
+// ID.
+func (s *Session) ScheduleRepeating(interval time.Duration, fn func()) string {
+	id := s.sched.ScheduleRepeating(interval, fn)
+	if id != "" {
+		// Kill cancels s.ctx; stop the repeats along with the session.
+		go func() {
+			<-s.ctx.Done()
+			s.sched.Cancel(id)
+		}()
+	}
+	return id
+}
+
+// CancelEvent cancels the repeating event identified by id, reporting
+// whether it existed. It's how an operator stops a single misbehaving
+// recurring lookup without killing the entire session.
+func (s *Session) CancelEvent(id string) bool {
+	return s.sched.Cancel(id)
+}
+
+// SetPaginationCursor records cursor as plugin's current pagination
+// position, so a resumed session can pick up from it instead of
+// restarting the source from its first page. Setting an empty cursor
+// clears any previously saved one, e.g. once a plugin finishes paginating.
+func (s *Session) SetPaginationCursor(plugin, cursor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cursor == "" {
+		delete(s.cursors, plugin)
+		return
+	}
+	if s.cursors == nil {
+		s.cursors = make(map[string]string)
+	}
+	s.cursors[plugin] = cursor
+}
+
+// PaginationCursor returns plugin's saved pagination cursor, or "" if
+// none is recorded.
+func (s *Session) PaginationCursor(plugin string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cursors[plugin]
+}
+
+// PaginationCursors returns a copy of every plugin's saved pagination
+// cursor, for inclusion in a session snapshot.
+func (s *Session) PaginationCursors() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string]string, len(s.cursors))
+	for plugin, cursor := range s.cursors {
+		out[plugin] = cursor
+	}
+	return out
+}
+
+// SetTransformRule installs rule as this session's enablement policy for
+// rule.From->rule.To, replacing any rule already set for that pair.
+func (s *Session) SetTransformRule(rule support.TransformRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transforms.AddRule(rule)
+}
+
+// TransformEnabled reports whether source is permitted to produce a
+// to-type asset from a from-type asset in this session. It's the single
+// authoritative check every plugin should call instead of hand-rolling its
+// own all/exclude switch, so enablement rules stay consistent across
+// sources.
+func (s *Session) TransformEnabled(from, to, source string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.transforms.Enabled(from, to, source)
+}
+
+// Context returns the session's cancellation context. It's canceled when
+// Kill is called.
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// Kill cancels the session's context, signaling every in-flight plugin
+// call using it to stop promptly, then marks the session done and logs
+// its Summary, so a session's outcome is captured at the moment it
+// actually ends rather than only on-demand if something later happens to
+// query it.
+func (s *Session) Kill() {
+	s.cancel()
+	s.Done()
+	if db := s.DB(); db != nil {
+		db.Stop()
+	}
+	slog.Default().Info("session ended", "token", s.Token, "summary", s.Summary().String())
+}
+
+// Graph returns the session's asset graph.
+func (s *Session) Graph() *graph.Graph {
+	return s.g
+}
+
+// ExpectWork records that count additional work items are now expected
+// before the session finishes, e.g. when a plugin discovers an asset and
+// schedules follow-on handlers for it. WorkItemsTotal only ever grows: a
+// plugin that later decides some of that follow-on work wasn't needed
+// after all should let CompleteWork catch up to it rather than have
+// ExpectWork shrink the total, since a total that can drop back down is
+// what made the client's progress bar untrustworthy in the first place.
+func (s *Session) ExpectWork(count int) {
+	if count <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.Stats.WorkItemsTotal += count
+	s.mu.Unlock()
+}
+
+// CompleteWork records that count work items finished. It never reports
+// more completed than total, so a race between ExpectWork and CompleteWork
+// can't make the progress bar briefly show more than 100%.
+func (s *Session) CompleteWork(count int) {
+	if count <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.Stats.WorkItemsCompleted += count
+	if s.Stats.WorkItemsCompleted > s.Stats.WorkItemsTotal {
+		s.Stats.WorkItemsCompleted = s.Stats.WorkItemsTotal
+	}
+	s.mu.Unlock()
+}
+
+// RecordPluginResult records that plugin either discovered assets (count)
+// or failed on this session, updating only its per-plugin breakdown. The
+// session's overall NamesDiscovered/IPsDiscovered-style totals are left
+// to callers, since only they know the asset type. Call it from wherever
+// handler execution is already tracked, once per handler invocation.
+func (s *Session) RecordPluginResult(plugin string, discovered int, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Stats.Plugins == nil {
+		s.Stats.Plugins = make(map[string]PluginStats)
+	}
+	p := s.Stats.Plugins[plugin]
+	p.AssetsDiscovered += discovered
+	if failed {
+		p.Errors++
+	}
+	s.Stats.Plugins[plugin] = p
+}
+
+// StatsSnapshot returns a copy of the session's Stats, including a copy of
+// the per-plugin breakdown map, safe to hand to a caller outside the
+// session's own locking.
+func (s *Session) StatsSnapshot() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap := s.Stats
+	snap.TimedOut = s.timedOut
+	snap.Plugins = make(map[string]PluginStats, len(s.Stats.Plugins))
+	for name, p := range s.Stats.Plugins {
+		snap.Plugins[name] = p
+	}
+	if s.requestBudget != nil {
+		snap.RequestsConsumed = s.requestBudget.Consumed()
+	}
+	if s.db != nil {
+		snap.DBDown = s.db.Down()
+		snap.DBBuffered = s.db.Buffered()
+	}
+	return snap
+}
+
+// MonitorDatabase installs database health monitoring on the session:
+// ping is used to test connectivity, and writes submitted through
+// DB().Write are buffered (up to bufferSize, defaulting via
+// NewBufferedDB) and replayed once ping starts succeeding again instead
+// of erroring and losing data for the rest of the session. It's opt-in,
+// since a session without a real backing database (NewSession's default
+// in-memory graph, a read-only session) has nothing to monitor.
+func (s *Session) MonitorDatabase(ping func() error, bufferSize int) *BufferedDB {
+	db := NewBufferedDB(ping, bufferSize)
+
+	s.mu.Lock()
+	s.db = db
+	s.mu.Unlock()
+
+	return db
+}
+
+// DB returns the session's BufferedDB installed by MonitorDatabase, or nil
+// if MonitorDatabase was never called.
+func (s *Session) DB() *BufferedDB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db
+}
+
+// DBDown reports whether the session's monitored database is currently
+// considered unreachable. It's the check a result-producing handler
+// should make before doing expensive work it can't yet persist, e.g.
+// skipping a paginated source's next page until the database recovers
+// instead of discovering results it has nowhere durable to put yet.
+// Always false for a session that never called MonitorDatabase.
+func (s *Session) DBDown() bool {
+	s.mu.RLock()
+	db := s.db
+	s.mu.RUnlock()
+	return db != nil && db.Down()
+}
+
+// Done marks the session as finished; no further writes are expected.
+func (s *Session) Done() {
+	s.mu.Lock()
+	s.done = true
+	s.mu.Unlock()
+}
+
+// IsDone reports whether the session has been marked finished.
+func (s *Session) IsDone() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.done
+}
+
+// Results returns every asset and relation discovered during the session,
+// optionally filtered to a single asset type. It's the single accessor the
+// API and CLI use to read a session's findings back out of its graph.
+func (s *Session) Results(assetType types.AssetType) ([]types.Asset, []types.Relation) {
+	return s.g.Assets(assetType), s.g.Relations()
+}
+
+// IncrementalResults returns only the assets and relations first seen at
+// or after baseline, so a repeated scan against the same DB can report
+// what's new instead of re-emitting everything it already knew about.
+func (s *Session) IncrementalResults(baseline time.Time) graph.Diff {
+	return s.g.SinceBaseline(baseline)
+}
@@ -0,0 +1,474 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/owasp-amass/engine/config"
+	"github.com/owasp-amass/engine/scheduler"
+	"github.com/owasp-amass/engine/support"
+	"github.com/owasp-amass/engine/types"
+)
+
+func TestKilledSessionStopsProcessingQueuedGuesses(t *testing.T) {
+	s := NewSession("token", "owner")
+	for i := 0; i < 1000; i++ {
+		s.QueueGuess(support.Guess{Name: "guess", Zone: "example.com"})
+	}
+	s.Kill()
+
+	var attempts int32
+	s.ProcessGuesses(support.GuessConfig{Concurrency: 5}, func(ctx context.Context, g support.Guess) error {
+		atomic.AddInt32(&attempts, 1)
+		return nil
+	})
+
+	if got := atomic.LoadInt32(&attempts); got != 0 {
+		t.Fatalf("expected a killed session's guesses to never be attempted, got %d", got)
+	}
+}
+
+// TestProcessGuessesSkipsNamesAlreadyResolvedInCache confirms
+// ProcessGuesses defaults to skipping a guess whose name already has a
+// positive answer in the session's DNS result cache, so a passive
+// plugin's already-known-resolved guesses never reach the untrusted pool.
+func TestProcessGuessesSkipsNamesAlreadyResolvedInCache(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	resp := new(dns.Msg)
+	resp.SetQuestion(dns.Fqdn("known.example.com"), dns.TypeA)
+	resp.Answer = []dns.RR{&dns.A{Hdr: dns.RR_Header{Name: dns.Fqdn("known.example.com"), Rrtype: dns.TypeA, Ttl: 300}}}
+	s.Resolutions().Set("known.example.com", dns.TypeA, resp, 300*time.Second)
+
+	s.QueueGuess(support.Guess{Name: "known.example.com", Zone: "example.com"})
+	s.QueueGuess(support.Guess{Name: "unknown.example.com", Zone: "example.com"})
+
+	var attempted []string
+	s.ProcessGuesses(support.GuessConfig{}, func(ctx context.Context, g support.Guess) error {
+		attempted = append(attempted, g.Name)
+		return nil
+	})
+
+	if len(attempted) != 1 || attempted[0] != "unknown.example.com" {
+		t.Fatalf("expected only the unresolved guess to be attempted, got %v", attempted)
+	}
+}
+
+// TestProcessGuessesAlreadyResolvedOverrideForcesRecheck confirms a caller
+// can override the default cache-skip behavior by setting its own
+// AlreadyResolved, e.g. to always return false when a full re-check is
+// wanted.
+func TestProcessGuessesAlreadyResolvedOverrideForcesRecheck(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	resp := new(dns.Msg)
+	resp.SetQuestion(dns.Fqdn("known.example.com"), dns.TypeA)
+	resp.Answer = []dns.RR{&dns.A{Hdr: dns.RR_Header{Name: dns.Fqdn("known.example.com"), Rrtype: dns.TypeA, Ttl: 300}}}
+	s.Resolutions().Set("known.example.com", dns.TypeA, resp, 300*time.Second)
+
+	s.QueueGuess(support.Guess{Name: "known.example.com", Zone: "example.com"})
+
+	var attempted int32
+	s.ProcessGuesses(support.GuessConfig{AlreadyResolved: func(name string) bool { return false }},
+		func(ctx context.Context, g support.Guess) error {
+			atomic.AddInt32(&attempted, 1)
+			return nil
+		})
+
+	if got := atomic.LoadInt32(&attempted); got != 1 {
+		t.Fatalf("expected the override to force re-checking the already-resolved guess, got %d attempts", got)
+	}
+}
+
+// TestProcessGuessesDefaultsToSessionGoroutineBudget confirms a session
+// created with NewSessionWithGoroutineBudget bounds ProcessGuesses'
+// concurrency by that shared budget even when cfg.Concurrency alone would
+// allow more.
+func TestProcessGuessesDefaultsToSessionGoroutineBudget(t *testing.T) {
+	s := NewSessionWithGoroutineBudget("token", "owner", config.WorkerConfig{MaxConcurrentGoroutines: 2})
+	for i := 0; i < 50; i++ {
+		s.QueueGuess(support.Guess{Name: "guess", Zone: "example.com"})
+	}
+
+	var current, max int32
+	s.ProcessGuesses(support.GuessConfig{Concurrency: 20}, func(ctx context.Context, g support.Guess) error {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		return nil
+	})
+
+	if got := atomic.LoadInt32(&max); got > 2 {
+		t.Fatalf("expected the session's goroutine budget of 2 to cap concurrency despite Concurrency:20, saw %d", got)
+	}
+}
+
+func TestRecordPluginResultTracksPerPluginBreakdown(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	s.RecordPluginResult("passivetotal", 3, false)
+	s.RecordPluginResult("passivetotal", 2, false)
+	s.RecordPluginResult("nsecwalk", 0, true)
+
+	snap := s.StatsSnapshot()
+	if got := snap.Plugins["passivetotal"]; got.AssetsDiscovered != 5 || got.Errors != 0 {
+		t.Fatalf("expected passivetotal to have 5 assets and 0 errors, got %+v", got)
+	}
+	if got := snap.Plugins["nsecwalk"]; got.AssetsDiscovered != 0 || got.Errors != 1 {
+		t.Fatalf("expected nsecwalk to have 0 assets and 1 error, got %+v", got)
+	}
+}
+
+func TestSessionWithTimeoutStopsAtDeadlineWithPartialResults(t *testing.T) {
+	s := NewSessionWithTimeout("token", "owner", config.SessionConfig{MaxDuration: 20 * time.Millisecond})
+
+	if err := s.Graph().UpsertAsset(types.FQDN{Name: "example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.After(500 * time.Millisecond)
+	for !s.IsDone() {
+		select {
+		case <-deadline:
+			t.Fatal("expected the session to be stopped by its MaxDuration")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	snap := s.StatsSnapshot()
+	if !snap.TimedOut {
+		t.Fatal("expected TimedOut to be set")
+	}
+
+	assets, _ := s.Results("")
+	if len(assets) != 1 {
+		t.Fatalf("expected the asset recorded before the deadline to survive, got %d", len(assets))
+	}
+}
+
+func TestSessionWithoutTimeoutIsUnaffected(t *testing.T) {
+	s := NewSessionWithTimeout("token", "owner", config.SessionConfig{})
+	time.Sleep(20 * time.Millisecond)
+
+	if s.IsDone() {
+		t.Fatal("expected a session with no MaxDuration to keep running")
+	}
+}
+
+// TestPaginationCursorSnapshotAndResume simulates a plugin interrupted
+// mid-pagination: it saves its cursor after each page via
+// SetPaginationCursor, and a "resumed" run reads PaginationCursor back to
+// continue from where it left off instead of restarting from page 1.
+func TestPaginationCursorSnapshotAndResume(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	pages := []string{"page-1", "page-2", "page-3"}
+	var fetched []string
+	for i, cursor := range pages {
+		fetched = append(fetched, cursor)
+		if i == 1 {
+			// Simulate the session being interrupted after the second
+			// page: its cursor is the last thing saved.
+			s.SetPaginationCursor("passivetotal", pages[i])
+			break
+		}
+	}
+	if len(fetched) != 2 {
+		t.Fatalf("expected the simulated run to stop after 2 pages, fetched %v", fetched)
+	}
+
+	saved := s.PaginationCursor("passivetotal")
+	if saved != "page-2" {
+		t.Fatalf("expected the saved cursor to be page-2, got %q", saved)
+	}
+
+	// Resume: a fresh run starts from the saved cursor rather than page 1.
+	var resumedFrom []string
+	for i := indexOf(pages, saved); i < len(pages); i++ {
+		resumedFrom = append(resumedFrom, pages[i])
+	}
+	if len(resumedFrom) != 2 || resumedFrom[0] != "page-2" {
+		t.Fatalf("expected resume to continue from page-2, got %v", resumedFrom)
+	}
+
+	snap := s.PaginationCursors()
+	if snap["passivetotal"] != "page-2" {
+		t.Fatalf("expected the snapshot to include the saved cursor, got %+v", snap)
+	}
+
+	s.SetPaginationCursor("passivetotal", "")
+	if got := s.PaginationCursor("passivetotal"); got != "" {
+		t.Fatalf("expected clearing the cursor to remove it, got %q", got)
+	}
+}
+
+// TestTransformEnabledAllWithPerSourceOverride exercises the combination a
+// plugin actually needs: allow every source by default, deny one noisy
+// source, but re-enable it explicitly for a single from->to pair.
+func TestTransformEnabledAllWithPerSourceOverride(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	s.SetTransformRule(support.TransformRule{
+		From: "FQDN", To: "IPAddress", All: true, Exclude: []string{"noisyscraper"},
+	})
+	s.SetTransformRule(support.TransformRule{
+		From: "FQDN", To: "IPAddress", All: true, Exclude: []string{"noisyscraper"},
+		Sources: map[string]bool{"noisyscraper": true},
+	})
+
+	if !s.TransformEnabled("FQDN", "IPAddress", "dnsresolve") {
+		t.Fatal("expected an unlisted source to be enabled under All")
+	}
+	if !s.TransformEnabled("FQDN", "IPAddress", "noisyscraper") {
+		t.Fatal("expected the per-source override to re-enable the excluded source")
+	}
+}
+
+// TestTransformEnabledUnconfiguredPairIsDenied confirms a fresh session
+// denies transformations nobody has configured, rather than defaulting to
+// allow.
+func TestTransformEnabledUnconfiguredPairIsDenied(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	if s.TransformEnabled("FQDN", "ASN", "hackertarget") {
+		t.Fatal("expected an unconfigured from->to pair to be denied")
+	}
+}
+
+// TestNewSessionWithDedupWarmStartsFromSharedKeys simulates two sessions
+// sharing a database: the second is warm-started with the first's keys and
+// should treat them as already seen without ever calling MarkSeen itself.
+func TestNewSessionWithDedupWarmStartsFromSharedKeys(t *testing.T) {
+	s := NewSessionWithDedup("token", "owner", DedupConfig{
+		WarmKeys: []string{"fqdn:example.com", "ipaddress:198.51.100.7"},
+	})
+
+	if !s.AlreadySeen("fqdn:example.com") {
+		t.Fatal("expected a warm-started key to be reported as already seen")
+	}
+	if s.AlreadySeen("fqdn:new.example.com") {
+		t.Fatal("expected a key outside the warm set to be reported as unseen")
+	}
+
+	s.MarkSeen("fqdn:new.example.com")
+	if !s.AlreadySeen("fqdn:new.example.com") {
+		t.Fatal("expected MarkSeen to make a key report as seen")
+	}
+}
+
+// TestNewSessionWithDedupForceReprocessDisablesDedup confirms
+// ForceReprocess overrides both the warm-started set and anything MarkSeen
+// records afterward.
+func TestNewSessionWithDedupForceReprocessDisablesDedup(t *testing.T) {
+	s := NewSessionWithDedup("token", "owner", DedupConfig{
+		WarmKeys:       []string{"fqdn:example.com"},
+		ForceReprocess: true,
+	})
+
+	if s.AlreadySeen("fqdn:example.com") {
+		t.Fatal("expected ForceReprocess to ignore the warm-started set")
+	}
+	s.MarkSeen("fqdn:example.com")
+	if s.AlreadySeen("fqdn:example.com") {
+		t.Fatal("expected ForceReprocess to make MarkSeen a no-op")
+	}
+}
+
+func indexOf(pages []string, cursor string) int {
+	for i, p := range pages {
+		if p == cursor {
+			return i
+		}
+	}
+	return 0
+}
+
+// TestWorkItemsTotalNeverDecreases interleaves ExpectWork and CompleteWork
+// the way plugins do in practice, discovering follow-on work while earlier
+// work is still finishing, and asserts WorkItemsTotal only ever grows.
+func TestWorkItemsTotalNeverDecreases(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	var lastTotal int
+	observe := func() {
+		snap := s.StatsSnapshot()
+		if snap.WorkItemsTotal < lastTotal {
+			t.Fatalf("WorkItemsTotal decreased from %d to %d", lastTotal, snap.WorkItemsTotal)
+		}
+		lastTotal = snap.WorkItemsTotal
+	}
+
+	s.ExpectWork(5)
+	observe()
+	s.CompleteWork(2)
+	observe()
+	s.ExpectWork(3)
+	observe()
+	s.CompleteWork(6)
+	observe()
+
+	snap := s.StatsSnapshot()
+	if snap.WorkItemsTotal != 8 {
+		t.Fatalf("expected WorkItemsTotal to be 8, got %d", snap.WorkItemsTotal)
+	}
+	if snap.WorkItemsCompleted != 8 {
+		t.Fatalf("expected WorkItemsCompleted to be clamped to total 8, got %d", snap.WorkItemsCompleted)
+	}
+}
+
+// TestCheckReturnIfFoundKillsSessionOnQualifyingAsset ensures a session
+// configured with ReturnIfFound stops promptly once a qualifying asset is
+// reported, and records it for SatisfyingAsset.
+func TestCheckReturnIfFoundKillsSessionOnQualifyingAsset(t *testing.T) {
+	s := NewSessionWithReturnIfFound("token", "owner", scheduler.ProcessConfig{ReturnIfFound: []string{"IPAddress"}})
+
+	if s.IsDone() {
+		t.Fatal("expected the session not to be done before a qualifying asset arrives")
+	}
+
+	fqdn := types.FQDN{Name: "www.example.com"}
+	if s.CheckReturnIfFound(fqdn) {
+		t.Fatal("expected an FQDN not to satisfy an IPAddress-only ReturnIfFound config")
+	}
+	if s.IsDone() {
+		t.Fatal("expected a non-qualifying asset not to stop the session")
+	}
+
+	ip := types.IPAddress{Address: "198.51.100.7", Type: types.IPTypeIPv4}
+	if !s.CheckReturnIfFound(ip) {
+		t.Fatal("expected the first qualifying IPAddress to report true")
+	}
+	if !s.IsDone() {
+		t.Fatal("expected the session to be done once a qualifying asset was found")
+	}
+
+	found, ok := s.SatisfyingAsset()
+	if !ok || found.Key() != ip.Key() {
+		t.Fatalf("expected SatisfyingAsset to report the qualifying IPAddress, got %+v (ok=%v)", found, ok)
+	}
+
+	// A second qualifying asset shouldn't overwrite the recorded one or
+	// report true again.
+	ip2 := types.IPAddress{Address: "203.0.113.5", Type: types.IPTypeIPv4}
+	if s.CheckReturnIfFound(ip2) {
+		t.Fatal("expected a subsequent qualifying asset not to report true again")
+	}
+	if found, _ := s.SatisfyingAsset(); found.Key() != ip.Key() {
+		t.Fatalf("expected the originally recorded asset to remain, got %+v", found)
+	}
+}
+
+// TestCheckReturnIfFoundDisabledByDefault ensures a plain NewSession never
+// stops early, since it has no configured ReturnIfFound types.
+func TestCheckReturnIfFoundDisabledByDefault(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	ip := types.IPAddress{Address: "198.51.100.7", Type: types.IPTypeIPv4}
+	if s.CheckReturnIfFound(ip) {
+		t.Fatal("expected a session with no ReturnIfFound config never to report true")
+	}
+	if s.IsDone() {
+		t.Fatal("expected the session to keep running")
+	}
+}
+
+// TestResolutionsCacheIsSharedAcrossHandlers simulates two plugins/handlers
+// resolving the same session: the second finds the first's cached answer
+// instead of resolving it again.
+func TestResolutionsCacheIsSharedAcrossHandlers(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	resp := new(dns.Msg)
+	resp.SetQuestion(dns.Fqdn("www.example.com"), dns.TypeA)
+	s.Resolutions().Set("www.example.com", dns.TypeA, resp, time.Minute)
+
+	if _, ok := s.Resolutions().Get("www.example.com", dns.TypeA); !ok {
+		t.Fatal("expected a second handler sharing the session to find the first handler's cached answer")
+	}
+}
+
+// TestPluginOptionsAppliesCustomSetting simulates a plugin (crtsh) reading
+// its own free-form option out of session config and applying it, without
+// the config schema needing a dedicated IncludeExpired field.
+func TestPluginOptionsAppliesCustomSetting(t *testing.T) {
+	s := NewSessionWithPluginOptions("token", "owner", config.PluginOptions{
+		"crtsh": {"include_expired": "true"},
+	})
+
+	includeExpired := s.PluginOptions("crtsh")["include_expired"] == "true"
+	if !includeExpired {
+		t.Fatal("expected the crtsh plugin to read its configured include_expired option")
+	}
+}
+
+// TestPluginOptionsUnconfiguredPluginGetsNil confirms a plugin with no
+// configured options gets nil rather than an error or a shared map other
+// plugins could see.
+func TestPluginOptionsUnconfiguredPluginGetsNil(t *testing.T) {
+	s := NewSessionWithPluginOptions("token", "owner", config.PluginOptions{
+		"crtsh": {"include_expired": "true"},
+	})
+
+	if got := s.PluginOptions("zetalytics"); got != nil {
+		t.Fatalf("expected an unconfigured plugin to get nil options, got %+v", got)
+	}
+}
+
+// TestNewSessionWithRequestBudgetSharesConsumptionAcrossCallers simulates
+// DNS and HTTP traffic drawing from the same session-level budget and
+// confirms StatsSnapshot reports their combined consumption.
+func TestNewSessionWithRequestBudgetSharesConsumptionAcrossCallers(t *testing.T) {
+	s := NewSessionWithRequestBudget("token", "owner", config.SessionConfig{MaxRequestsPerSecond: 1000})
+
+	budget := s.RequestBudget()
+	if budget == nil {
+		t.Fatal("expected a configured MaxRequestsPerSecond to produce a non-nil RequestBudget")
+	}
+
+	budget.Wait(context.Background()) // simulated DNS query
+	budget.Wait(context.Background()) // simulated HTTP fetch
+
+	if got := s.StatsSnapshot().RequestsConsumed; got != 2 {
+		t.Fatalf("expected the combined DNS+HTTP consumption to be 2, got %d", got)
+	}
+}
+
+// TestNewSessionWithoutRequestBudgetLeavesTrafficUncapped confirms a
+// plain NewSession has no RequestBudget, so callers see no cap.
+func TestNewSessionWithoutRequestBudgetLeavesTrafficUncapped(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	if s.RequestBudget() != nil {
+		t.Fatal("expected a plain session to have no RequestBudget")
+	}
+	if got := s.StatsSnapshot().RequestsConsumed; got != 0 {
+		t.Fatalf("expected RequestsConsumed to be 0 without a configured budget, got %d", got)
+	}
+}
+
+// TestNewSessionWithDNSCacheCapsEntryLifetime confirms MaxCacheTTL caps a
+// cached answer's lifetime below its own much longer record TTL.
+func TestNewSessionWithDNSCacheCapsEntryLifetime(t *testing.T) {
+	s := NewSessionWithDNSCache("token", "owner", config.DNSConfig{MaxCacheTTL: time.Millisecond})
+
+	resp := new(dns.Msg)
+	resp.SetQuestion(dns.Fqdn("www.example.com"), dns.TypeA)
+	s.Resolutions().Set("www.example.com", dns.TypeA, resp, time.Hour)
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := s.Resolutions().Get("www.example.com", dns.TypeA); ok {
+		t.Fatal("expected MaxCacheTTL to cap the entry's lifetime below the record's own TTL")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary is a structured, end-of-session report combining stats, asset
+// counts, and timing into one snapshot, so an operator (or the API) gets
+// a concise picture of what a session did without reassembling it from
+// Stats and Graph by hand.
+type Summary struct {
+	// Duration is how long the session has been running, from creation
+	// until Summary was called. The end of a killed session is not
+	// recorded, so a later Summary call still counts up to that call.
+	Duration time.Duration
+	TimedOut bool
+	// AssetCounts breaks down the session's current graph contents by
+	// AssetType name.
+	AssetCounts map[string]int
+	// Plugins is the same per-plugin breakdown as Stats.Plugins.
+	Plugins            map[string]PluginStats
+	WorkItemsTotal     int
+	WorkItemsCompleted int
+	// Errors is the sum of every plugin's Errors count, the total number
+	// of rate-limit hits and failures across every source combined.
+	Errors           int
+	RequestsConsumed int64
+}
+
+// Summary assembles a Summary from the session's current Stats and Graph.
+// It's safe to call on a still-running session (Duration reflects elapsed
+// time so far) as well as one that's already Done.
+func (s *Session) Summary() Summary {
+	stats := s.StatsSnapshot()
+
+	counts := make(map[string]int)
+	for _, a := range s.Graph().Assets("") {
+		counts[string(a.AssetType())]++
+	}
+
+	var errs int
+	for _, p := range stats.Plugins {
+		errs += p.Errors
+	}
+
+	s.mu.RLock()
+	duration := time.Since(s.startedAt)
+	s.mu.RUnlock()
+
+	return Summary{
+		Duration:           duration,
+		TimedOut:           stats.TimedOut,
+		AssetCounts:        counts,
+		Plugins:            stats.Plugins,
+		WorkItemsTotal:     stats.WorkItemsTotal,
+		WorkItemsCompleted: stats.WorkItemsCompleted,
+		Errors:             errs,
+		RequestsConsumed:   stats.RequestsConsumed,
+	}
+}
+
+// String renders sum as a short, human-readable multi-line report, the
+// text form logged on graceful shutdown and available for a CLI or report
+// output to print directly.
+func (sum Summary) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "session summary: duration=%s timed_out=%t work=%d/%d errors=%d requests_consumed=%d\n",
+		sum.Duration.Round(time.Second), sum.TimedOut, sum.WorkItemsCompleted, sum.WorkItemsTotal, sum.Errors, sum.RequestsConsumed)
+
+	assetTypes := make([]string, 0, len(sum.AssetCounts))
+	for t := range sum.AssetCounts {
+		assetTypes = append(assetTypes, t)
+	}
+	sort.Strings(assetTypes)
+	for _, t := range assetTypes {
+		fmt.Fprintf(&b, "  %s: %d\n", t, sum.AssetCounts[t])
+	}
+
+	plugins := make([]string, 0, len(sum.Plugins))
+	for name := range sum.Plugins {
+		plugins = append(plugins, name)
+	}
+	sort.Strings(plugins)
+	for _, name := range plugins {
+		p := sum.Plugins[name]
+		fmt.Fprintf(&b, "  %s: %d assets, %d errors\n", name, p.AssetsDiscovered, p.Errors)
+	}
+
+	return b.String()
+}
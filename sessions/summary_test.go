@@ -0,0 +1,69 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owasp-amass/engine/types"
+)
+
+// TestSummaryReflectsPopulatedSessionActivity confirms Summary combines
+// graph contents, per-plugin stats, and elapsed duration into one report.
+func TestSummaryReflectsPopulatedSessionActivity(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	s.Graph().UpsertAsset(types.FQDN{Name: "owasp.org"})
+	s.Graph().UpsertAsset(types.FQDN{Name: "www.owasp.org"})
+	s.Graph().UpsertAsset(types.IPAddress{Address: "1.2.3.4", Type: "IPv4"})
+
+	s.RecordPluginResult("dns", 2, false)
+	s.RecordPluginResult("crtsh", 1, true)
+
+	time.Sleep(time.Millisecond)
+	summary := s.Summary()
+
+	if summary.AssetCounts["FQDN"] != 2 {
+		t.Fatalf("expected 2 FQDNs, got %d", summary.AssetCounts["FQDN"])
+	}
+	if summary.AssetCounts["IPAddress"] != 1 {
+		t.Fatalf("expected 1 IPAddress, got %d", summary.AssetCounts["IPAddress"])
+	}
+	if summary.Plugins["dns"].AssetsDiscovered != 2 {
+		t.Fatalf("expected dns to have discovered 2 assets, got %+v", summary.Plugins["dns"])
+	}
+	if summary.Errors != 1 {
+		t.Fatalf("expected 1 total error (from crtsh), got %d", summary.Errors)
+	}
+	if summary.Duration <= 0 {
+		t.Fatal("expected a positive elapsed duration")
+	}
+
+	rendered := summary.String()
+	if !strings.Contains(rendered, "FQDN: 2") {
+		t.Fatalf("expected rendered summary to mention FQDN count, got %q", rendered)
+	}
+	if !strings.Contains(rendered, "dns: 2 assets, 0 errors") {
+		t.Fatalf("expected rendered summary to mention dns plugin stats, got %q", rendered)
+	}
+	if !strings.Contains(rendered, "crtsh: 1 assets, 1 errors") {
+		t.Fatalf("expected rendered summary to mention crtsh plugin stats, got %q", rendered)
+	}
+}
+
+// TestSummaryOnEmptySessionHasNoAssetsOrErrors confirms a freshly-created
+// session's Summary is well-formed with nothing recorded yet.
+func TestSummaryOnEmptySessionHasNoAssetsOrErrors(t *testing.T) {
+	s := NewSession("token", "owner")
+
+	summary := s.Summary()
+	if len(summary.AssetCounts) != 0 {
+		t.Fatalf("expected no asset counts, got %v", summary.AssetCounts)
+	}
+	if summary.Errors != 0 {
+		t.Fatalf("expected no errors, got %d", summary.Errors)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+// defaultMaxEditDistance and defaultMaxCandidates bound fuzzyLabelSearches
+// when AlterationConfig leaves them unset.
+const (
+	defaultMaxEditDistance = 1
+	defaultMaxCandidates   = 2500
+)
+
+// alterationAlphabet is the character set fuzzyLabelSearches draws
+// insertions and substitutions from: DNS labels only ever contain
+// letters, digits, and hyphens.
+const alterationAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789-"
+
+// AlterationConfig caps how aggressively fuzzyLabelSearches generates
+// candidates from a single base label. Without a cap, wiring the
+// alteration feature into an active handler could generate an enormous
+// candidate set and DoS the resolver pool with brute-force guesses.
+type AlterationConfig struct {
+	// MaxEditDistance bounds how many single-character edits (insertion,
+	// deletion, substitution) a generated candidate may differ from the
+	// base label by. Zero selects defaultMaxEditDistance.
+	MaxEditDistance int
+	// MaxCandidates caps the total number of candidates
+	// fuzzyLabelSearches returns, regardless of how many the edit
+	// distance would otherwise allow. Zero selects defaultMaxCandidates.
+	MaxCandidates int
+}
+
+// fuzzyLabelSearches generates alteration candidates for label (a single
+// DNS label, e.g. "www") by inserting, deleting, and substituting
+// characters up to cfg.MaxEditDistance edits away, stopping as soon as
+// cfg.MaxCandidates have been produced even if further edits remain
+// possible.
+func fuzzyLabelSearches(label string, cfg AlterationConfig) []string {
+	maxDist := cfg.MaxEditDistance
+	if maxDist <= 0 {
+		maxDist = defaultMaxEditDistance
+	}
+	maxCand := cfg.MaxCandidates
+	if maxCand <= 0 {
+		maxCand = defaultMaxCandidates
+	}
+
+	seen := map[string]bool{label: true}
+	frontier := []string{label}
+	var out []string
+
+	for dist := 0; dist < maxDist; dist++ {
+		var next []string
+		for _, s := range frontier {
+			for _, cand := range editsOnce(s) {
+				if seen[cand] {
+					continue
+				}
+				seen[cand] = true
+				next = append(next, cand)
+				out = append(out, cand)
+				if len(out) >= maxCand {
+					return out
+				}
+			}
+		}
+		frontier = next
+	}
+	return out
+}
+
+// editsOnce returns every string one insertion, deletion, or substitution
+// away from s.
+func editsOnce(s string) []string {
+	var out []string
+	for i := 0; i <= len(s); i++ {
+		for _, c := range alterationAlphabet {
+			out = append(out, s[:i]+string(c)+s[i:])
+		}
+		if i < len(s) {
+			out = append(out, s[:i]+s[i+1:])
+			for _, c := range alterationAlphabet {
+				if byte(c) == s[i] {
+					continue
+				}
+				out = append(out, s[:i]+string(c)+s[i+1:])
+			}
+		}
+	}
+	return out
+}
+
+// SubmitFQDNGuess generates alteration candidates for label (capped by
+// cfg) and queues each one, joined with zone, onto q for later brute-force
+// resolution. It returns the number of candidates queued.
+func SubmitFQDNGuess(q *GuessQueue, zone, label string, cfg AlterationConfig) int {
+	candidates := fuzzyLabelSearches(label, cfg)
+	for _, c := range candidates {
+		q.Queue(Guess{Name: c, Zone: zone})
+	}
+	return len(candidates)
+}
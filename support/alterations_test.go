@@ -0,0 +1,50 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFuzzyLabelSearchesStopsAtConfiguredCap(t *testing.T) {
+	got := fuzzyLabelSearches("www", AlterationConfig{MaxEditDistance: 2, MaxCandidates: 10})
+	if len(got) != 10 {
+		t.Fatalf("expected generation to stop at the configured cap of 10, got %d", len(got))
+	}
+}
+
+func TestFuzzyLabelSearchesRespectsEditDistance(t *testing.T) {
+	// A single-character label has no possible edit-distance-1 deletion
+	// result other than the empty string, and no candidate can be more
+	// than one character longer or shorter than the base label.
+	got := fuzzyLabelSearches("a", AlterationConfig{MaxEditDistance: 1, MaxCandidates: 10000})
+	if len(got) == 0 {
+		t.Fatal("expected at least one candidate")
+	}
+	for _, c := range got {
+		if len(c) > 2 {
+			t.Fatalf("candidate %q looks farther than one edit from \"a\"", c)
+		}
+	}
+}
+
+func TestSubmitFQDNGuessQueuesCappedCandidates(t *testing.T) {
+	q := NewGuessQueue()
+	n := SubmitFQDNGuess(q, "example.com", "www", AlterationConfig{MaxEditDistance: 1, MaxCandidates: 5})
+
+	if n != 5 {
+		t.Fatalf("expected 5 candidates queued, got %d", n)
+	}
+
+	var processed int32
+	q.Process(context.Background(), GuessConfig{Concurrency: 1}, func(ctx context.Context, g Guess) error {
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	if got := atomic.LoadInt32(&processed); got != 5 {
+		t.Fatalf("expected all 5 queued guesses to be processed, got %d", got)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ASNResult is what an IP→ASN provider found for a single address.
+type ASNResult struct {
+	ASN int
+	// Prefix is the announced CIDR containing ip, when the provider knows
+	// it. Not every provider can supply one (e.g. a name-only lookup),
+	// so it may be empty even on success.
+	Prefix string
+	// Name is the ASN's registered name/description, when the provider
+	// supplies one.
+	Name string
+	// Source names the provider that answered, e.g. "bgptools" or
+	// "cymru", so a caller (or a test) can tell which one in the chain
+	// actually produced the result.
+	Source string
+}
+
+// ASNProvider resolves ip to the ASN announcing it. A provider that can't
+// answer must return an error rather than a zero-value ASNResult, since
+// IPToASN uses the error to decide whether to try the next provider in
+// the chain.
+type ASNProvider func(ctx context.Context, ip string) (ASNResult, error)
+
+// IPToASN tries each of providers in order against ip, returning the
+// first successful ASNResult. bgptools is the historical sole IP→ASN
+// path; if bgp.tools is unreachable the whole ASN/netblock/RIR enrichment
+// used to fail outright. Callers now build a chain (e.g. bgptools, then
+// RDAP/whois, then a Team Cymru DNS lookup) and pass it here instead, so
+// one unreachable provider degrades to the next rather than failing the
+// lookup entirely.
+func IPToASN(ctx context.Context, ip string, providers []ASNProvider) (ASNResult, error) {
+	if len(providers) == 0 {
+		return ASNResult{}, errors.New("support: no ASN providers configured")
+	}
+
+	var errs []error
+	for _, provider := range providers {
+		res, err := provider(ctx, ip)
+		if err == nil {
+			return res, nil
+		}
+		errs = append(errs, err)
+	}
+	return ASNResult{}, fmt.Errorf("support: every ASN provider failed for %s: %w", ip, errors.Join(errs...))
+}
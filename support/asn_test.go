@@ -0,0 +1,71 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIPToASNFallsBackToSecondaryWhenPrimaryFails(t *testing.T) {
+	var tried []string
+	primary := func(ctx context.Context, ip string) (ASNResult, error) {
+		tried = append(tried, "primary")
+		return ASNResult{}, errors.New("bgp.tools unreachable")
+	}
+	secondary := func(ctx context.Context, ip string) (ASNResult, error) {
+		tried = append(tried, "secondary")
+		return ASNResult{ASN: 64500, Prefix: "198.51.100.0/24", Source: "secondary"}, nil
+	}
+
+	res, err := IPToASN(context.Background(), "198.51.100.42", []ASNProvider{primary, secondary})
+	if err != nil {
+		t.Fatalf("IPToASN failed: %v", err)
+	}
+	if res.ASN != 64500 || res.Source != "secondary" {
+		t.Fatalf("expected the secondary provider's result, got %+v", res)
+	}
+	if len(tried) != 2 || tried[0] != "primary" || tried[1] != "secondary" {
+		t.Fatalf("expected primary tried before secondary, got %v", tried)
+	}
+}
+
+func TestIPToASNReturnsFirstSuccessWithoutTryingLaterProviders(t *testing.T) {
+	var secondaryCalled bool
+	primary := func(ctx context.Context, ip string) (ASNResult, error) {
+		return ASNResult{ASN: 13335, Source: "primary"}, nil
+	}
+	secondary := func(ctx context.Context, ip string) (ASNResult, error) {
+		secondaryCalled = true
+		return ASNResult{}, errors.New("should not be called")
+	}
+
+	res, err := IPToASN(context.Background(), "1.1.1.1", []ASNProvider{primary, secondary})
+	if err != nil {
+		t.Fatalf("IPToASN failed: %v", err)
+	}
+	if res.ASN != 13335 {
+		t.Fatalf("expected the primary provider's result, got %+v", res)
+	}
+	if secondaryCalled {
+		t.Fatal("expected the secondary provider not to be tried after the primary succeeded")
+	}
+}
+
+func TestIPToASNReportsErrorWhenEveryProviderFails(t *testing.T) {
+	failing := func(ctx context.Context, ip string) (ASNResult, error) {
+		return ASNResult{}, errors.New("no answer")
+	}
+
+	if _, err := IPToASN(context.Background(), "203.0.113.7", []ASNProvider{failing, failing}); err == nil {
+		t.Fatal("expected an error when every provider fails")
+	}
+}
+
+func TestIPToASNReportsErrorWithNoProvidersConfigured(t *testing.T) {
+	if _, err := IPToASN(context.Background(), "203.0.113.7", nil); err == nil {
+		t.Fatal("expected an error with no providers configured")
+	}
+}
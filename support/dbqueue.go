@@ -0,0 +1,169 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package support provides shared infrastructure used by plugins and the
+// engine runtime, such as the database callback processor and other
+// utilities that don't belong to a single plugin.
+package support
+
+import (
+	"context"
+	"sync"
+)
+
+// DBCallback is a unit of work submitted to the DB callback processor. Key
+// identifies the logical entity the write belongs to; callbacks that share
+// the same Key are guaranteed to execute in submission order relative to
+// each other, since they land on the same worker shard. Callbacks with
+// different keys have no ordering guarantee and may run concurrently.
+type DBCallback struct {
+	Key uint64
+	Fn  func(ctx context.Context) error
+}
+
+// DBQueueConfig controls the concurrency and pacing of the DB callback
+// processor.
+type DBQueueConfig struct {
+	// Workers is the number of goroutines draining the queue. Callbacks
+	// with the same Key always land on the same worker shard, so raising
+	// Workers adds parallelism across keys without breaking the ordering
+	// guarantee for dependent writes. Values <= 1 reproduce the original
+	// single-goroutine behavior.
+	Workers int
+	// RatePerSecond caps the aggregate number of callbacks executed per
+	// second across every worker. Zero or negative disables the limit,
+	// which is the historical, unlimited behavior.
+	RatePerSecond int
+	// QueueSize bounds the number of pending callbacks buffered per
+	// shard before Submit blocks. Zero selects a small default.
+	QueueSize int
+}
+
+// DBQueue drains submitted DBCallbacks, honoring per-key ordering while
+// letting independent keys execute concurrently across a worker pool, and
+// optionally throttling the aggregate write rate.
+type DBQueue struct {
+	cfg     DBQueueConfig
+	shards  []chan DBCallback
+	limiter *RateLimiter
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// NewDBQueue creates a DBQueue using cfg. Call Start to begin processing.
+func NewDBQueue(cfg DBQueueConfig) *DBQueue {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = 1000
+	}
+
+	q := &DBQueue{
+		cfg:    cfg,
+		shards: make([]chan DBCallback, cfg.Workers),
+	}
+	for i := range q.shards {
+		q.shards[i] = make(chan DBCallback, cfg.QueueSize)
+	}
+	if cfg.RatePerSecond > 0 {
+		q.limiter = NewRateLimiter(cfg.RatePerSecond)
+	}
+	return q
+}
+
+// Start launches the worker goroutines that drain the queue. It returns
+// immediately; call Shutdown to stop the workers and wait for them to
+// finish any in-flight callback.
+func (q *DBQueue) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	q.cancel = cancel
+
+	for i := range q.shards {
+		q.wg.Add(1)
+		go q.processDBCallbacks(ctx, q.shards[i])
+	}
+}
+
+// Submit enqueues cb on the shard determined by cb.Key, preserving order
+// relative to any other callback previously submitted with the same Key.
+// It blocks if that shard's queue is full.
+func (q *DBQueue) Submit(cb DBCallback) {
+	idx := cb.Key % uint64(len(q.shards))
+	q.shards[idx] <- cb
+}
+
+// Shutdown stops accepting new work on the current shards, waits for
+// in-flight and already-queued callbacks to drain, then returns.
+func (q *DBQueue) Shutdown() {
+	for _, s := range q.shards {
+		close(s)
+	}
+	if q.cancel != nil {
+		defer q.cancel()
+	}
+	q.wg.Wait()
+}
+
+// Transaction groups a sequence of DB operations that must run together,
+// in order, as a single unit. DBQueue only guarantees ordering between
+// callbacks that share a Key; a plugin creating a parent asset and then a
+// relation to a child submitted as two separate Submit calls has no such
+// guarantee unless both happen to hash to the same shard, risking a
+// child write reaching the database before its parent is committed. A
+// Transaction avoids that by running as one DBCallback, so every op in it
+// executes on the same worker, in Add order, without another shard's work
+// interleaving partway through.
+type Transaction struct {
+	key uint64
+	ops []func(ctx context.Context) error
+}
+
+// NewTransaction starts an empty Transaction. key selects the DBQueue
+// shard the whole transaction lands on, same as DBCallback.Key.
+func NewTransaction(key uint64) *Transaction {
+	return &Transaction{key: key}
+}
+
+// Add appends fn as the transaction's next operation, to run only after
+// every previously added operation has succeeded.
+func (t *Transaction) Add(fn func(ctx context.Context) error) {
+	t.ops = append(t.ops, fn)
+}
+
+// Submit enqueues the whole transaction on q as a single DBCallback. Its
+// operations run in Add order on whichever worker owns t's shard; the
+// first operation to return an error stops the rest from running against
+// what would otherwise be an inconsistent parent.
+func (t *Transaction) Submit(q *DBQueue) {
+	ops := append([]func(ctx context.Context) error(nil), t.ops...)
+	q.Submit(DBCallback{
+		Key: t.key,
+		Fn: func(ctx context.Context) error {
+			for _, op := range ops {
+				if err := op(ctx); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// processDBCallbacks is the per-shard worker loop. Each shard is drained by
+// exactly one goroutine, which is what preserves per-key ordering: every
+// callback for a given Key always lands in the same shard channel and is
+// therefore executed by the same goroutine in FIFO order.
+func (q *DBQueue) processDBCallbacks(ctx context.Context, ch chan DBCallback) {
+	defer q.wg.Done()
+
+	for cb := range ch {
+		if q.limiter != nil {
+			q.limiter.Wait(ctx)
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+		_ = cb.Fn(ctx)
+	}
+}
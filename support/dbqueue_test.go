@@ -0,0 +1,150 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+var errFailedParentWrite = errors.New("parent write failed")
+
+// TestDBQueueOrderingPreserved ensures that callbacks sharing the same Key
+// are executed in submission order even when the queue is configured with
+// multiple workers.
+func TestDBQueueOrderingPreserved(t *testing.T) {
+	const key = uint64(42)
+	const n = 500
+
+	q := NewDBQueue(DBQueueConfig{Workers: 8})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	var mu sync.Mutex
+	var got []int
+	for i := 0; i < n; i++ {
+		i := i
+		q.Submit(DBCallback{
+			Key: key,
+			Fn: func(context.Context) error {
+				mu.Lock()
+				got = append(got, i)
+				mu.Unlock()
+				return nil
+			},
+		})
+	}
+	q.Shutdown()
+
+	if len(got) != n {
+		t.Fatalf("expected %d callbacks executed, got %d", n, len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("ordering violated at index %d: got %d, want %d", i, v, i)
+		}
+	}
+}
+
+// TestTransactionSubmitRunsOperationsInOrderUnderTheAsyncQueue ensures a
+// Transaction's operations execute in Add order, even against a
+// multi-worker queue and even when other, unrelated keys have work
+// enqueued concurrently, and stops before its remaining operations once
+// one fails.
+func TestTransactionSubmitRunsOperationsInOrderUnderTheAsyncQueue(t *testing.T) {
+	q := NewDBQueue(DBQueueConfig{Workers: 8})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	// Flood other shards with unrelated work so the transaction's shard
+	// isn't the only thing the queue is doing.
+	for i := 0; i < 200; i++ {
+		q.Submit(DBCallback{Key: uint64(i), Fn: func(context.Context) error { return nil }})
+	}
+
+	var mu sync.Mutex
+	var order []string
+
+	tx := NewTransaction(7)
+	tx.Add(func(context.Context) error {
+		mu.Lock()
+		order = append(order, "parent")
+		mu.Unlock()
+		return nil
+	})
+	tx.Add(func(context.Context) error {
+		mu.Lock()
+		order = append(order, "child")
+		mu.Unlock()
+		return nil
+	})
+	tx.Submit(q)
+	q.Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "parent" || order[1] != "child" {
+		t.Fatalf("expected parent then child, got %v", order)
+	}
+}
+
+// TestTransactionSubmitStopsAfterAFailedOperation ensures a failing
+// operation prevents the transaction's remaining operations from running.
+func TestTransactionSubmitStopsAfterAFailedOperation(t *testing.T) {
+	q := NewDBQueue(DBQueueConfig{Workers: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	var ranChild bool
+	tx := NewTransaction(1)
+	tx.Add(func(context.Context) error { return errFailedParentWrite })
+	tx.Add(func(context.Context) error { ranChild = true; return nil })
+	tx.Submit(q)
+	q.Shutdown()
+
+	if ranChild {
+		t.Fatal("expected the child operation not to run after the parent operation failed")
+	}
+}
+
+// BenchmarkDBQueueSingleWorker measures throughput with the historical
+// single-goroutine behavior.
+func BenchmarkDBQueueSingleWorker(b *testing.B) {
+	benchmarkDBQueue(b, 1)
+}
+
+// BenchmarkDBQueuePooled measures throughput with a pooled configuration to
+// compare against the single-worker baseline.
+func BenchmarkDBQueuePooled(b *testing.B) {
+	benchmarkDBQueue(b, 8)
+}
+
+func benchmarkDBQueue(b *testing.B, workers int) {
+	q := NewDBQueue(DBQueueConfig{Workers: workers, QueueSize: 10000})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Submit(DBCallback{
+			Key: uint64(i % workers),
+			Fn: func(context.Context) error {
+				wg.Done()
+				return nil
+			},
+		})
+	}
+	wg.Wait()
+	b.StopTimer()
+	q.Shutdown()
+}
@@ -0,0 +1,153 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"strings"
+	"sync"
+)
+
+// DefaultPassiveDNSPruneThreshold is the parent-child count at or above
+// which NewPassiveDNSFilter collapses a parent into a wildcard marker,
+// unless overridden with NewPassiveDNSFilterWithThreshold.
+const DefaultPassiveDNSPruneThreshold = 100
+
+// PassiveDNSFilter collects names discovered from a passive-DNS-style
+// source, grouped by their immediate parent domain, and can collapse a
+// parent with a suspiciously large number of children into a single
+// wildcard marker. It's used by plugins like passivetotal and zetalytics,
+// whose handlers fan out across goroutines per page or per source, so
+// every method locks internally instead of leaving callers to coordinate
+// access themselves.
+type PassiveDNSFilter struct {
+	mu        sync.Mutex
+	children  map[string]map[string]bool // parent name -> set of child names inserted under it
+	threshold int
+}
+
+// NewPassiveDNSFilter returns an empty PassiveDNSFilter that collapses a
+// parent once it reaches DefaultPassiveDNSPruneThreshold children.
+func NewPassiveDNSFilter() *PassiveDNSFilter {
+	return NewPassiveDNSFilterWithThreshold(DefaultPassiveDNSPruneThreshold)
+}
+
+// NewPassiveDNSFilterWithThreshold returns an empty PassiveDNSFilter that
+// collapses a parent once it reaches threshold children. Scans against a
+// sprawling org can lose real subdomains to the default threshold; a
+// larger one trades that risk for keeping more genuine wildcard noise
+// around.
+func NewPassiveDNSFilterWithThreshold(threshold int) *PassiveDNSFilter {
+	return &PassiveDNSFilter{children: make(map[string]map[string]bool), threshold: threshold}
+}
+
+// Insert records that name was seen under its immediate parent domain.
+func (f *PassiveDNSFilter) Insert(name string) {
+	parent := parentOf(name)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.children[parent] == nil {
+		f.children[parent] = make(map[string]bool)
+	}
+	f.children[parent][name] = true
+}
+
+// Prune collapses any parent with at least the filter's configured
+// threshold of distinct children into a single wildcard marker, discarding
+// the individual children: a source reporting that many subdomains under
+// one parent is far more likely echoing DNS wildcard noise than that many
+// distinct real hosts.
+func (f *PassiveDNSFilter) Prune() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for parent, kids := range f.children {
+		if len(kids) >= f.threshold {
+			f.children[parent] = map[string]bool{"*." + parent: true}
+		}
+	}
+}
+
+// Slice returns every name currently retained by the filter.
+func (f *PassiveDNSFilter) Slice() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var out []string
+	for _, kids := range f.children {
+		for name := range kids {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func parentOf(name string) string {
+	if idx := strings.Index(name, "."); idx != -1 {
+		return name[idx+1:]
+	}
+	return name
+}
+
+// DefaultFQDNPruneMax is the name count NewFQDNFilter caps itself at,
+// unless overridden with NewFQDNFilterWithMax.
+const DefaultFQDNPruneMax = 1000
+
+// FQDNFilter is a concurrency-safe set of discovered names with an
+// enforceable cap on how many it retains.
+type FQDNFilter struct {
+	mu    sync.Mutex
+	names map[string]bool
+	max   int
+}
+
+// NewFQDNFilter returns an empty FQDNFilter capped at DefaultFQDNPruneMax
+// names.
+func NewFQDNFilter() *FQDNFilter {
+	return NewFQDNFilterWithMax(DefaultFQDNPruneMax)
+}
+
+// NewFQDNFilterWithMax returns an empty FQDNFilter capped at max names.
+func NewFQDNFilterWithMax(max int) *FQDNFilter {
+	return &FQDNFilter{names: make(map[string]bool), max: max}
+}
+
+// Insert adds name to the filter.
+func (f *FQDNFilter) Insert(name string) {
+	f.mu.Lock()
+	f.names[name] = true
+	f.mu.Unlock()
+}
+
+// Prune caps the filter at its configured max names, dropping entries
+// beyond that so a wildly oversized result set from one source can't flood
+// the graph.
+func (f *FQDNFilter) Prune() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.names) <= f.max {
+		return
+	}
+	kept := make(map[string]bool, f.max)
+	for name := range f.names {
+		if len(kept) >= f.max {
+			break
+		}
+		kept[name] = true
+	}
+	f.names = kept
+}
+
+// Slice returns every name currently retained by the filter.
+func (f *FQDNFilter) Slice() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]string, 0, len(f.names))
+	for name := range f.names {
+		out = append(out, name)
+	}
+	return out
+}
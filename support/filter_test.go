@@ -0,0 +1,88 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPassiveDNSFilterConcurrentInsert(t *testing.T) {
+	f := NewPassiveDNSFilter()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				f.Insert(fmt.Sprintf("host%d-%d.example.com", g, i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if got := len(f.Slice()); got != 1000 {
+		t.Fatalf("expected 1000 distinct names, got %d", got)
+	}
+}
+
+func TestPassiveDNSFilterPruneCollapsesLargeParent(t *testing.T) {
+	f := NewPassiveDNSFilter()
+	for i := 0; i < 150; i++ {
+		f.Insert(fmt.Sprintf("host%d.example.com", i))
+	}
+	f.Prune()
+
+	names := f.Slice()
+	if len(names) != 1 || names[0] != "*.example.com" {
+		t.Fatalf("expected the parent to collapse to a single wildcard, got %v", names)
+	}
+}
+
+func TestPassiveDNSFilterCustomThresholdKeepsSmallerGroupsUncollapsed(t *testing.T) {
+	f := NewPassiveDNSFilterWithThreshold(200)
+	for i := 0; i < 150; i++ {
+		f.Insert(fmt.Sprintf("host%d.example.com", i))
+	}
+	f.Prune()
+
+	if got := len(f.Slice()); got != 150 {
+		t.Fatalf("expected all 150 names to survive under a threshold of 200, got %d", got)
+	}
+}
+
+func TestFQDNFilterConcurrentInsertAndPrune(t *testing.T) {
+	f := NewFQDNFilter()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				f.Insert(fmt.Sprintf("host%d-%d.example.com", g, i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	f.Prune()
+	if got := len(f.Slice()); got != DefaultFQDNPruneMax {
+		t.Fatalf("expected exactly %d names after pruning, got %d", DefaultFQDNPruneMax, got)
+	}
+}
+
+func TestFQDNFilterCustomMax(t *testing.T) {
+	f := NewFQDNFilterWithMax(50)
+	for i := 0; i < 200; i++ {
+		f.Insert(fmt.Sprintf("host%d.example.com", i))
+	}
+	f.Prune()
+
+	if got := len(f.Slice()); got != 50 {
+		t.Fatalf("expected exactly 50 names after pruning to a custom max, got %d", got)
+	}
+}
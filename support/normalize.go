@@ -0,0 +1,43 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Answer is a normalized view of a single DNS answer, independent of the
+// underlying resolver library's own record types, so answers can be
+// compared, deduplicated, or cached by value.
+type Answer struct {
+	Name string
+	Data string
+	Type uint16
+}
+
+// hostnameDataTypes are the record types whose Data is itself a hostname
+// (a CNAME/NS/MX/PTR target) and so should be case-folded the same way
+// Name is. Every other type's Data is left exactly as the resolver
+// returned it: TXT data and SRV target:port strings can be legitimately
+// case-sensitive, and blanket-lowercasing them silently corrupts values
+// that depend on their original casing.
+var hostnameDataTypes = map[uint16]bool{
+	dns.TypeCNAME: true,
+	dns.TypeNS:    true,
+	dns.TypeMX:    true,
+	dns.TypePTR:   true,
+}
+
+// NormalizeAnswer lowercases a's Name, since hostnames are always
+// case-insensitive, and lowercases Data only when a.Type identifies Data
+// as a hostname too, leaving every other type's Data untouched.
+func NormalizeAnswer(a Answer) Answer {
+	a.Name = strings.ToLower(a.Name)
+	if hostnameDataTypes[a.Type] {
+		a.Data = strings.ToLower(a.Data)
+	}
+	return a
+}
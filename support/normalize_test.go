@@ -0,0 +1,34 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestNormalizeAnswerLowercasesHostnameTargets(t *testing.T) {
+	a := NormalizeAnswer(Answer{Name: "WWW.Example.COM", Data: "Target.Example.COM", Type: dns.TypeCNAME})
+	if a.Name != "www.example.com" || a.Data != "target.example.com" {
+		t.Fatalf("expected both Name and Data to be lowercased for a CNAME, got %+v", a)
+	}
+}
+
+func TestNormalizeAnswerPreservesTXTDataCasing(t *testing.T) {
+	a := NormalizeAnswer(Answer{Name: "Example.COM", Data: "v=spf1 include:MailProvider.Example ~all", Type: dns.TypeTXT})
+	if a.Name != "example.com" {
+		t.Fatalf("expected Name to be lowercased, got %q", a.Name)
+	}
+	if a.Data != "v=spf1 include:MailProvider.Example ~all" {
+		t.Fatalf("expected TXT Data casing to be preserved, got %q", a.Data)
+	}
+}
+
+func TestNormalizeAnswerPreservesSRVDataCasing(t *testing.T) {
+	a := NormalizeAnswer(Answer{Name: "_sip._tcp.Example.COM", Data: "5 0 5060 SIPServer.Example.COM", Type: dns.TypeSRV})
+	if a.Data != "5 0 5060 SIPServer.Example.COM" {
+		t.Fatalf("expected SRV Data casing to be preserved, got %q", a.Data)
+	}
+}
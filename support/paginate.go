@@ -0,0 +1,71 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import "context"
+
+// PaginateConfig drives a generic fetch -> parse -> next-cursor loop shared
+// by API-backed plugins, so each new source doesn't reimplement its own
+// paging logic.
+type PaginateConfig struct {
+	// Fetch retrieves one page given the current cursor (empty string
+	// for the first page) and returns the raw page body.
+	Fetch func(ctx context.Context, cursor string) ([]byte, error)
+	// Parse extracts the caller's items and the cursor for the next
+	// page from a page body. An empty next cursor ends pagination.
+	Parse func(page []byte) (items interface{}, next string, err error)
+	// OnPage is invoked once per successfully parsed page.
+	OnPage func(items interface{}) error
+	// MaxPages caps the number of pages fetched, guarding against a
+	// misbehaving API returning a cursor that never terminates. Zero
+	// selects a conservative default.
+	MaxPages int
+	// StartCursor resumes pagination from a previously saved cursor
+	// instead of starting from the first page, so a plugin interrupted
+	// mid-pagination doesn't re-consume API quota re-fetching pages it
+	// already processed.
+	StartCursor string
+	// OnCursor, if set, is invoked after each successfully fetched page
+	// with the cursor for the next page ("" once pagination ends), so a
+	// caller can persist it (e.g. into the session snapshot) for a
+	// future resume.
+	OnCursor func(cursor string)
+}
+
+// Paginate drives cfg's fetch/parse/callback loop, starting from
+// cfg.StartCursor, until the source stops returning a next cursor or
+// MaxPages is reached.
+func Paginate(ctx context.Context, cfg PaginateConfig) error {
+	maxPages := cfg.MaxPages
+	if maxPages <= 0 {
+		maxPages = 1000
+	}
+
+	cursor := cfg.StartCursor
+	for page := 0; page < maxPages; page++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		raw, err := cfg.Fetch(ctx, cursor)
+		if err != nil {
+			return err
+		}
+		items, next, err := cfg.Parse(raw)
+		if err != nil {
+			return err
+		}
+		if err := cfg.OnPage(items); err != nil {
+			return err
+		}
+		if cfg.OnCursor != nil {
+			cfg.OnCursor(next)
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPaginateDrivesMultiplePagesToTermination(t *testing.T) {
+	pages := [][]string{{"a", "b"}, {"c"}, {"d", "e"}}
+	var seen []string
+	fetchCount := 0
+
+	err := Paginate(context.Background(), PaginateConfig{
+		Fetch: func(ctx context.Context, cursor string) ([]byte, error) {
+			idx := 0
+			if cursor != "" {
+				idx = int(cursor[0] - '0')
+			}
+			fetchCount++
+			return []byte{byte('0' + idx)}, nil
+		},
+		Parse: func(page []byte) (interface{}, string, error) {
+			idx := int(page[0] - '0')
+			next := ""
+			if idx+1 < len(pages) {
+				next = string(rune('0' + idx + 1))
+			}
+			return pages[idx], next, nil
+		},
+		OnPage: func(items interface{}) error {
+			seen = append(seen, items.([]string)...)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+	if fetchCount != 3 {
+		t.Fatalf("expected 3 fetches, got %d", fetchCount)
+	}
+	if len(seen) != 5 {
+		t.Fatalf("expected 5 items collected across pages, got %d: %v", len(seen), seen)
+	}
+}
+
+func TestPaginateResumesFromStartCursor(t *testing.T) {
+	pages := [][]string{{"a", "b"}, {"c"}, {"d", "e"}}
+	var seen []string
+	var fetchedCursors []string
+
+	err := Paginate(context.Background(), PaginateConfig{
+		StartCursor: "1",
+		Fetch: func(ctx context.Context, cursor string) ([]byte, error) {
+			fetchedCursors = append(fetchedCursors, cursor)
+			idx := int(cursor[0] - '0')
+			return []byte{byte('0' + idx)}, nil
+		},
+		Parse: func(page []byte) (interface{}, string, error) {
+			idx := int(page[0] - '0')
+			next := ""
+			if idx+1 < len(pages) {
+				next = string(rune('0' + idx + 1))
+			}
+			return pages[idx], next, nil
+		},
+		OnPage: func(items interface{}) error {
+			seen = append(seen, items.([]string)...)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+	if len(fetchedCursors) != 2 || fetchedCursors[0] != "1" {
+		t.Fatalf("expected resume to skip page 0 and start at cursor \"1\", fetched %v", fetchedCursors)
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected only pages 1 and 2's items, got %d: %v", len(seen), seen)
+	}
+}
+
+func TestPaginateReportsCursorAfterEachPage(t *testing.T) {
+	pages := [][]string{{"a"}, {"b"}}
+	var reported []string
+
+	err := Paginate(context.Background(), PaginateConfig{
+		Fetch: func(ctx context.Context, cursor string) ([]byte, error) {
+			idx := 0
+			if cursor != "" {
+				idx = int(cursor[0] - '0')
+			}
+			return []byte{byte('0' + idx)}, nil
+		},
+		Parse: func(page []byte) (interface{}, string, error) {
+			idx := int(page[0] - '0')
+			next := ""
+			if idx+1 < len(pages) {
+				next = string(rune('0' + idx + 1))
+			}
+			return pages[idx], next, nil
+		},
+		OnPage: func(items interface{}) error { return nil },
+		OnCursor: func(cursor string) {
+			reported = append(reported, cursor)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Paginate failed: %v", err)
+	}
+	if len(reported) != 2 || reported[0] != "1" || reported[1] != "" {
+		t.Fatalf("expected cursor progression [\"1\" \"\"], got %v", reported)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package plugintest collects the assertions and test doubles almost every
+// plugin test in this repository re-implements on its own: comparing a
+// discovered-names slice against an expected set regardless of order, and
+// building a simple suffix-based InScope filter. Centralizing them here
+// means a plugin's test can focus on the behavior specific to that plugin
+// instead of re-deriving "are these two string slices the same set" every
+// time.
+package plugintest
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// AssertNames fails t unless got and want contain exactly the same
+// strings, ignoring order and duplicate ordering differences. Plugin
+// results commonly come back in map-iteration order, so exact-order
+// comparison would make tests flaky for no reason related to the behavior
+// under test.
+func AssertNames(t *testing.T, got, want []string) {
+	t.Helper()
+
+	gotSorted := append([]string(nil), got...)
+	wantSorted := append([]string(nil), want...)
+	sort.Strings(gotSorted)
+	sort.Strings(wantSorted)
+
+	if len(gotSorted) != len(wantSorted) {
+		t.Fatalf("expected %d names %v, got %d names %v", len(wantSorted), wantSorted, len(gotSorted), gotSorted)
+	}
+	for i := range gotSorted {
+		if gotSorted[i] != wantSorted[i] {
+			t.Fatalf("expected names %v, got %v", wantSorted, gotSorted)
+		}
+	}
+}
+
+// NoError fails t with context if err is non-nil.
+func NoError(t *testing.T, err error, context string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", context, err)
+	}
+}
+
+// InScopeSuffix returns an InScope-shaped filter (matching the
+// `func(name string) bool` field every scoped plugin exposes) that accepts
+// a name if it equals, or is a subdomain of, any of domains.
+func InScopeSuffix(domains ...string) func(name string) bool {
+	return func(name string) bool {
+		for _, domain := range domains {
+			if name == domain || strings.HasSuffix(name, "."+domain) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package plugintest
+
+import "testing"
+
+func TestInScopeSuffixAcceptsExactAndSubdomainMatches(t *testing.T) {
+	inScope := InScopeSuffix("example.com")
+
+	if !inScope("example.com") {
+		t.Fatal("expected the exact domain to be in scope")
+	}
+	if !inScope("www.example.com") {
+		t.Fatal("expected a subdomain to be in scope")
+	}
+	if inScope("notexample.com") {
+		t.Fatal("expected a look-alike suffix without the dot separator to be rejected")
+	}
+	if inScope("evil.com") {
+		t.Fatal("expected an unrelated domain to be rejected")
+	}
+}
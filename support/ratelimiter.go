@@ -0,0 +1,74 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter is a minimal token-bucket limiter used to cap the aggregate
+// rate of some activity (DB writes, outbound API calls) without pulling in
+// an external dependency. It started as a private helper inside DBQueue;
+// it's exported here so other rate-limited consumers, like the API
+// plugins, don't reimplement the same token bucket.
+type RateLimiter struct {
+	mu       sync.Mutex
+	tokens   float64
+	max      float64
+	perToken time.Duration
+	last     time.Time
+	// consumed counts every Wait call that has returned a token, so a
+	// limiter shared across multiple protocols (DNS and HTTP drawing from
+	// one session-level budget) can report its total combined usage.
+	consumed int64
+}
+
+// NewRateLimiter returns a RateLimiter allowing up to perSecond operations
+// per second, bursting up to that many tokens.
+func NewRateLimiter(perSecond int) *RateLimiter {
+	return &RateLimiter{
+		tokens:   float64(perSecond),
+		max:      float64(perSecond),
+		perToken: time.Second / time.Duration(perSecond),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is canceled.
+func (r *RateLimiter) Wait(ctx context.Context) {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		elapsed := now.Sub(r.last)
+		r.last = now
+		r.tokens += elapsed.Seconds() * float64(time.Second/r.perToken)
+		if r.tokens > r.max {
+			r.tokens = r.max
+		}
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			atomic.AddInt64(&r.consumed, 1)
+			return
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.perToken):
+		}
+	}
+}
+
+// Consumed reports how many tokens this limiter has handed out over its
+// lifetime, so a caller sharing one limiter across multiple call sites
+// (e.g. both DNS and HTTP traffic drawing from one session-level budget)
+// can report their combined usage.
+func (r *RateLimiter) Consumed() int64 {
+	return atomic.LoadInt64(&r.consumed)
+}
@@ -0,0 +1,35 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRateLimiterConsumedCountsSuccessfulWaits(t *testing.T) {
+	r := NewRateLimiter(1000)
+
+	for i := 0; i < 5; i++ {
+		r.Wait(context.Background())
+	}
+
+	if got := r.Consumed(); got != 5 {
+		t.Fatalf("expected 5 consumed tokens, got %d", got)
+	}
+}
+
+func TestRateLimiterConsumedIsSharedAcrossCallers(t *testing.T) {
+	r := NewRateLimiter(1000)
+
+	// Simulate two independent protocols (DNS and HTTP) drawing from the
+	// same combined budget.
+	r.Wait(context.Background())
+	r.Wait(context.Background())
+	r.Wait(context.Background())
+
+	if got := r.Consumed(); got != 3 {
+		t.Fatalf("expected the combined consumption to reflect both callers, got %d", got)
+	}
+}
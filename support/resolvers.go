@@ -0,0 +1,132 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultGuessConcurrency is used when GuessConfig.Concurrency isn't set.
+// It's small enough not to overwhelm a typical host or network link even
+// against a large untrusted resolver pool.
+const defaultGuessConcurrency = 20
+
+// MaxHandlerInstances is the default cap on concurrent invocations of a
+// single registry.Registration's handler when it doesn't set its own
+// MaxInstances. It lives here rather than in the registry package so
+// plugins configuring their own Registration can reference the same
+// default the registry falls back to, instead of a plugin guessing at a
+// number that happens to match.
+const MaxHandlerInstances = 500
+
+// Guess is a single FQDN candidate queued for brute-force resolution.
+type Guess struct {
+	Name string
+	Zone string
+}
+
+// GuessConfig controls how aggressively Process works.
+type GuessConfig struct {
+	// Concurrency caps how many guessAttempt calls run at once. It used
+	// to be derived from the untrusted pool's resolver count (one
+	// goroutine per resolver), which could launch hundreds of concurrent
+	// attempts against a large pool; it's now an explicit, independently
+	// tunable knob. Zero selects defaultGuessConcurrency.
+	Concurrency int
+	// Budget, if set, is drawn from before launching each guessAttempt
+	// goroutine, bounding this queue's contribution to the engine-wide
+	// goroutine ceiling alongside every other site sharing the same
+	// WorkerBudget. Nil leaves goroutine count governed by Concurrency
+	// alone.
+	Budget *WorkerBudget
+	// AlreadyResolved, when set, lets Process skip a queued guess whose
+	// name it reports as already known-resolved (e.g. a hit in the
+	// session's DNS result cache via resolve.ResultCache.IsResolved),
+	// before spending this queue's concurrency or an untrusted-pool
+	// query re-confirming something already known. Nil disables the
+	// check, the historical always-attempt behavior, since a caller
+	// deliberately wanting everything re-verified can leave it unset.
+	AlreadyResolved func(name string) bool
+}
+
+// GuessQueue holds the brute-force guesses pending for a single session. A
+// package-global queue and processor meant every session's guesses shared
+// one queue and one concurrency budget, and killing one session couldn't
+// stop its guesses without also starving every other session; a GuessQueue
+// belongs to exactly one session instead.
+type GuessQueue struct {
+	mu      sync.Mutex
+	guesses []Guess
+}
+
+// NewGuessQueue returns an empty GuessQueue.
+func NewGuessQueue() *GuessQueue {
+	return &GuessQueue{}
+}
+
+// Queue appends g to the pending guess queue for later processing by
+// Process.
+func (q *GuessQueue) Queue(g Guess) {
+	q.mu.Lock()
+	q.guesses = append(q.guesses, g)
+	q.mu.Unlock()
+}
+
+// Process drains the pending guess queue, invoking attempt for each one
+// with at most cfg.Concurrency calls in flight at a time. A guess whose
+// name cfg.AlreadyResolved reports as already known-resolved is skipped
+// before it ever reaches attempt. It stops launching new attempts as soon
+// as ctx is canceled, e.g. because the owning session was killed, and
+// waits for whatever is already in flight to finish before returning.
+func (q *GuessQueue) Process(ctx context.Context, cfg GuessConfig, attempt func(ctx context.Context, g Guess) error) {
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultGuessConcurrency
+	}
+
+	q.mu.Lock()
+	pending := q.guesses
+	q.guesses = nil
+	q.mu.Unlock()
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, g := range pending {
+		if cfg.AlreadyResolved != nil && cfg.AlreadyResolved(g.Name) {
+			continue
+		}
+		if ctx.Err() != nil {
+			wg.Wait()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		release, err := cfg.Budget.Acquire(ctx)
+		if err != nil {
+			<-sem
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(g Guess) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer release()
+			guessAttempt(ctx, attempt, g)
+		}(g)
+	}
+	wg.Wait()
+}
+
+// guessAttempt runs a single queued guess through attempt, discarding its
+// error: a failed brute-force lookup for one candidate name shouldn't
+// interrupt processing of the rest of the queue.
+func guessAttempt(ctx context.Context, attempt func(ctx context.Context, g Guess) error, g Guess) {
+	_ = attempt(ctx, g)
+}
@@ -0,0 +1,132 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGuessQueueProcessRespectsConfiguredConcurrency(t *testing.T) {
+	q := NewGuessQueue()
+	for i := 0; i < 50; i++ {
+		q.Queue(Guess{Name: "guess", Zone: "example.com"})
+	}
+
+	var current, max int32
+	attempt := func(ctx context.Context, g Guess) error {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		return nil
+	}
+
+	q.Process(context.Background(), GuessConfig{Concurrency: 5}, attempt)
+
+	if got := atomic.LoadInt32(&max); got > 5 {
+		t.Fatalf("expected at most 5 concurrent guess attempts, saw %d", got)
+	}
+}
+
+func TestGuessQueueProcessStopsOnCanceledContext(t *testing.T) {
+	q := NewGuessQueue()
+	for i := 0; i < 1000; i++ {
+		q.Queue(Guess{Name: "guess", Zone: "example.com"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // already canceled, as if the owning session had been killed
+
+	var attempts int32
+	q.Process(ctx, GuessConfig{Concurrency: 5}, func(ctx context.Context, g Guess) error {
+		atomic.AddInt32(&attempts, 1)
+		return nil
+	})
+
+	if got := atomic.LoadInt32(&attempts); got != 0 {
+		t.Fatalf("expected no attempts against an already-canceled context, got %d", got)
+	}
+}
+
+// TestGuessQueueProcessHonorsSharedWorkerBudget ensures a WorkerBudget
+// shared with another goroutine-launching source bounds this queue's
+// combined contribution, even when its own Concurrency setting alone
+// would allow more.
+func TestGuessQueueProcessHonorsSharedWorkerBudget(t *testing.T) {
+	budget := NewWorkerBudget(2)
+
+	q := NewGuessQueue()
+	for i := 0; i < 50; i++ {
+		q.Queue(Guess{Name: "guess", Zone: "example.com"})
+	}
+
+	var current, max int32
+	attempt := func(ctx context.Context, g Guess) error {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		return nil
+	}
+
+	q.Process(context.Background(), GuessConfig{Concurrency: 20, Budget: budget}, attempt)
+
+	if got := atomic.LoadInt32(&max); got > 2 {
+		t.Fatalf("expected the shared budget of 2 to cap concurrency despite Concurrency:20, saw %d", got)
+	}
+}
+
+// TestGuessQueueProcessSkipsAlreadyResolvedNames ensures a guess whose
+// name AlreadyResolved reports as known-resolved never reaches attempt,
+// while one it doesn't recognize still does.
+func TestGuessQueueProcessSkipsAlreadyResolvedNames(t *testing.T) {
+	q := NewGuessQueue()
+	q.Queue(Guess{Name: "known.example.com", Zone: "example.com"})
+	q.Queue(Guess{Name: "unknown.example.com", Zone: "example.com"})
+
+	var attempted []string
+	cfg := GuessConfig{
+		AlreadyResolved: func(name string) bool { return name == "known.example.com" },
+	}
+	q.Process(context.Background(), cfg, func(ctx context.Context, g Guess) error {
+		attempted = append(attempted, g.Name)
+		return nil
+	})
+
+	if len(attempted) != 1 || attempted[0] != "unknown.example.com" {
+		t.Fatalf("expected only the unresolved guess to reach attempt, got %v", attempted)
+	}
+}
+
+// TestGuessQueueProcessWithoutAlreadyResolvedAttemptsEverything confirms
+// leaving AlreadyResolved unset (the default) preserves the historical
+// always-attempt behavior.
+func TestGuessQueueProcessWithoutAlreadyResolvedAttemptsEverything(t *testing.T) {
+	q := NewGuessQueue()
+	q.Queue(Guess{Name: "a.example.com", Zone: "example.com"})
+	q.Queue(Guess{Name: "b.example.com", Zone: "example.com"})
+
+	var attempted int32
+	q.Process(context.Background(), GuessConfig{}, func(ctx context.Context, g Guess) error {
+		atomic.AddInt32(&attempted, 1)
+		return nil
+	})
+
+	if got := atomic.LoadInt32(&attempted); got != 2 {
+		t.Fatalf("expected both guesses to be attempted, got %d", got)
+	}
+}
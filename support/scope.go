@@ -0,0 +1,39 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import "strings"
+
+// canonicalizeName lowercases name, trims surrounding whitespace, and
+// strips a single trailing dot, so "Example.COM.", " example.com ", and
+// "example.com" all compare equal. Full Unicode IDN normalization isn't
+// applied here; names are expected to already be in their ASCII/punycode
+// form by the time they reach scope checking.
+func canonicalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.TrimSuffix(name, ".")
+}
+
+// WhichDomain returns whichever entry in domains name falls within (name
+// itself or a subdomain of it), and whether one was found. Both name and
+// every entry in domains are canonicalized before comparison, so callers
+// don't need to lowercase, trim, or strip a trailing dot themselves before
+// calling in, and inconsistent normalization between callers can't cause
+// a scope miss.
+func WhichDomain(name string, domains []string) (string, bool) {
+	name = canonicalizeName(name)
+	for _, d := range domains {
+		d = canonicalizeName(d)
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// IsDomainInScope reports whether name falls within any entry of domains.
+func IsDomainInScope(name string, domains []string) bool {
+	_, ok := WhichDomain(name, domains)
+	return ok
+}
@@ -0,0 +1,38 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import "testing"
+
+func TestIsDomainInScopeCanonicalizesMixedCaseAndTrailingDot(t *testing.T) {
+	domains := []string{"example.com"}
+
+	cases := []string{"Example.COM.", " example.com ", "www.Example.COM.", "WWW.EXAMPLE.COM"}
+	for _, name := range cases {
+		if !IsDomainInScope(name, domains) {
+			t.Fatalf("expected %q to be in scope", name)
+		}
+	}
+}
+
+func TestIsDomainInScopeCanonicalizesConfiguredDomainToo(t *testing.T) {
+	domains := []string{"Example.COM."}
+
+	if !IsDomainInScope("www.example.com", domains) {
+		t.Fatal("expected a mixed-case, trailing-dot configured domain to still match")
+	}
+}
+
+func TestWhichDomainReportsFalseForUnrelatedName(t *testing.T) {
+	if _, ok := WhichDomain("example.org", []string{"example.com"}); ok {
+		t.Fatal("expected an unrelated name to report no match")
+	}
+}
+
+func TestWhichDomainReturnsTheMatchedDomain(t *testing.T) {
+	domain, ok := WhichDomain(" WWW.Example.COM. ", []string{"other.com", "example.com"})
+	if !ok || domain != "example.com" {
+		t.Fatalf("expected a match against example.com, got %q (ok=%v)", domain, ok)
+	}
+}
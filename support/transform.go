@@ -0,0 +1,75 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+// TransformRule configures whether a from->to asset transformation is
+// permitted, and by which sources. All plugins that can produce more than
+// one kind of follow-on asset (e.g. an FQDN resolving to both an IPAddress
+// and a CNAME) should consult one shared TransformConfig instead of each
+// hand-rolling its own all/exclude switch, since two plugins that drift on
+// the same from->to pair produce results a user can't reason about.
+type TransformRule struct {
+	From string
+	To   string
+	// All allows every source when true, subject to Exclude and Sources
+	// overriding it. Leaving both All and Sources unset denies every
+	// source, matching the rule not existing at all.
+	All bool
+	// Exclude lists sources denied despite All, e.g. "allow every source
+	// to produce IPAddress from FQDN except a noisy scraper."
+	Exclude []string
+	// Sources, when non-nil, is consulted before All/Exclude and takes
+	// precedence over them: true enables a source, false disables it,
+	// and a source absent from the map falls through to All/Exclude.
+	Sources map[string]bool
+}
+
+// TransformConfig is the authoritative source of from->to transformation
+// enablement, keyed by asset type pair. Plugins call TransformConfig.Enabled
+// (usually through Session.TransformEnabled) instead of embedding their own
+// switch/exclude logic, so enablement rules live in one place and behave
+// identically across every source.
+type TransformConfig struct {
+	rules map[string]TransformRule
+}
+
+// NewTransformConfig returns an empty TransformConfig. With no rules added,
+// Enabled denies every from->to pair, since an unconfigured transformation
+// deserves an explicit decision rather than a silent default of "on".
+func NewTransformConfig() *TransformConfig {
+	return &TransformConfig{rules: make(map[string]TransformRule)}
+}
+
+// AddRule installs rule, replacing any existing rule for the same From/To
+// pair.
+func (c *TransformConfig) AddRule(rule TransformRule) {
+	c.rules[transformKey(rule.From, rule.To)] = rule
+}
+
+// Enabled reports whether source is permitted to produce a to-type asset
+// from a from-type asset, applying the per-source override first, then the
+// All/Exclude shortcut, and finally denying if no rule covers the pair at
+// all.
+func (c *TransformConfig) Enabled(from, to, source string) bool {
+	rule, found := c.rules[transformKey(from, to)]
+	if !found {
+		return false
+	}
+	if enabled, ok := rule.Sources[source]; ok {
+		return enabled
+	}
+	if !rule.All {
+		return false
+	}
+	for _, excluded := range rule.Exclude {
+		if excluded == source {
+			return false
+		}
+	}
+	return true
+}
+
+func transformKey(from, to string) string {
+	return from + "->" + to
+}
@@ -0,0 +1,52 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import "testing"
+
+func TestTransformConfigAllWithExclude(t *testing.T) {
+	c := NewTransformConfig()
+	c.AddRule(TransformRule{From: "FQDN", To: "IPAddress", All: true, Exclude: []string{"noisyscraper"}})
+
+	if !c.Enabled("FQDN", "IPAddress", "dnsresolve") {
+		t.Fatal("expected an unlisted source to be enabled under All")
+	}
+	if c.Enabled("FQDN", "IPAddress", "noisyscraper") {
+		t.Fatal("expected the excluded source to be denied")
+	}
+}
+
+func TestTransformConfigPerSourceOverridesAll(t *testing.T) {
+	c := NewTransformConfig()
+	c.AddRule(TransformRule{
+		From:    "FQDN",
+		To:      "FQDN",
+		All:     false,
+		Sources: map[string]bool{"passivetotal": true},
+	})
+
+	if !c.Enabled("FQDN", "FQDN", "passivetotal") {
+		t.Fatal("expected the explicitly enabled source to be permitted despite All being false")
+	}
+	if c.Enabled("FQDN", "FQDN", "hackertarget") {
+		t.Fatal("expected a source with no override and All false to be denied")
+	}
+}
+
+func TestTransformConfigUnknownPairIsDenied(t *testing.T) {
+	c := NewTransformConfig()
+	if c.Enabled("FQDN", "ASN", "anysource") {
+		t.Fatal("expected a from->to pair with no rule to be denied")
+	}
+}
+
+func TestTransformConfigAddRuleReplacesExisting(t *testing.T) {
+	c := NewTransformConfig()
+	c.AddRule(TransformRule{From: "FQDN", To: "IPAddress", All: true})
+	c.AddRule(TransformRule{From: "FQDN", To: "IPAddress", All: false})
+
+	if c.Enabled("FQDN", "IPAddress", "dnsresolve") {
+		t.Fatal("expected the second AddRule to replace the first rule entirely")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import "context"
+
+// WorkerBudget bounds the total number of concurrently running goroutines
+// drawn from every goroutine-launching site that opts in (guess attempts,
+// DNS sweep routines, pipeline tasks, and so on), independent of each
+// site's own per-call concurrency limit. Between pipeline parallelism,
+// DNS sweep goroutines, and guess attempts, the engine could otherwise
+// spawn thousands of goroutines at once with no global ceiling; sharing
+// one WorkerBudget across those sites gives an operator a single,
+// predictable knob instead of tuning each site's limit separately and
+// hoping the sum stays reasonable.
+type WorkerBudget struct {
+	sem chan struct{}
+}
+
+// NewWorkerBudget returns a WorkerBudget allowing at most limit
+// goroutines across every site sharing it. limit <= 0 disables the
+// budget, matching the historical behavior of no global ceiling.
+func NewWorkerBudget(limit int) *WorkerBudget {
+	if limit <= 0 {
+		return &WorkerBudget{}
+	}
+	return &WorkerBudget{sem: make(chan struct{}, limit)}
+}
+
+// Acquire blocks until a slot is available or ctx is canceled. On
+// success it returns a release func the caller must call exactly once,
+// typically via defer, when its goroutine finishes. A nil or disabled
+// WorkerBudget never blocks, so call sites can hold an unconfigured
+// *WorkerBudget without a nil check.
+func (b *WorkerBudget) Acquire(ctx context.Context) (release func(), err error) {
+	if b == nil || b.sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case b.sem <- struct{}{}:
+		return func() { <-b.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
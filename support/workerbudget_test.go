@@ -0,0 +1,88 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package support
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWorkerBudgetCapsConcurrentGoroutinesAcrossMultipleSources ensures a
+// single WorkerBudget shared by several independent goroutine-launching
+// sources still bounds their combined concurrency at its configured
+// limit, not just each source's own local limit.
+func TestWorkerBudgetCapsConcurrentGoroutinesAcrossMultipleSources(t *testing.T) {
+	const limit = 5
+	budget := NewWorkerBudget(limit)
+
+	var current, max int32
+	var wg sync.WaitGroup
+	launch := func() {
+		defer wg.Done()
+		release, err := budget.Acquire(context.Background())
+		if err != nil {
+			t.Errorf("Acquire failed: %v", err)
+			return
+		}
+		defer release()
+
+		n := atomic.AddInt32(&current, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+	}
+
+	// Three "sources" (guess attempts, DNS sweep routines, pipeline
+	// tasks) each launching well beyond limit goroutines of their own.
+	for source := 0; source < 3; source++ {
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go launch()
+		}
+	}
+	wg.Wait()
+
+	if max > limit {
+		t.Fatalf("expected combined concurrency to stay at or under %d, observed %d", limit, max)
+	}
+}
+
+// TestWorkerBudgetAcquireReturnsImmediatelyWhenDisabled ensures a
+// non-positive limit disables the budget rather than blocking forever.
+func TestWorkerBudgetAcquireReturnsImmediatelyWhenDisabled(t *testing.T) {
+	budget := NewWorkerBudget(0)
+
+	release, err := budget.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	release()
+}
+
+// TestWorkerBudgetAcquireRespectsContextCancellation ensures a caller
+// blocked waiting for a slot is released once ctx is canceled.
+func TestWorkerBudgetAcquireRespectsContextCancellation(t *testing.T) {
+	budget := NewWorkerBudget(1)
+
+	release, err := budget.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := budget.Acquire(ctx); err == nil {
+		t.Fatal("expected Acquire to report an error for an already-canceled context")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package tlscerts generates the TLS material the engine's API server uses
+// when no operator-supplied certificate is configured.
+package tlscerts
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"time"
+)
+
+// Options controls the identity and validity period of a generated
+// self-signed certificate. The zero value reproduces the historical
+// hardcoded "localhost"/127.0.0.1, one-year certificate.
+type Options struct {
+	// DNSNames and IPAddresses become the certificate's Subject
+	// Alternative Names. Both empty default to localhost/127.0.0.1.
+	DNSNames    []string
+	IPAddresses []string
+	// Organization sets the certificate subject. Empty defaults to
+	// "OWASP Amass Engine".
+	Organization string
+	// ValidFor sets the certificate lifetime. Zero defaults to one year.
+	ValidFor time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if len(o.DNSNames) == 0 && len(o.IPAddresses) == 0 {
+		o.DNSNames = []string{"localhost"}
+		o.IPAddresses = []string{"127.0.0.1"}
+	}
+	if o.Organization == "" {
+		o.Organization = "OWASP Amass Engine"
+	}
+	if o.ValidFor <= 0 {
+		o.ValidFor = 365 * 24 * time.Hour
+	}
+	return o
+}
+
+// GenerateSelfSigned creates a self-signed ECDSA certificate/key pair with
+// the default SANs (localhost/127.0.0.1) and a one-year lifetime,
+// suitable for a tls.Config's Certificates field. It's used when the
+// engine is started without an operator-provided cert, e.g. for local
+// development.
+func GenerateSelfSigned() (tls.Certificate, error) {
+	return GenerateSelfSignedWithOptions(Options{})
+}
+
+// GenerateSelfSignedWithOptions is like GenerateSelfSigned but lets the
+// caller supply the SANs, subject, and validity period, typically sourced
+// from the engine's configuration so operators can bind the server cert to
+// their own hostnames/IPs instead of the localhost default.
+func GenerateSelfSignedWithOptions(opts Options) (tls.Certificate, error) {
+	opts = opts.withDefaults()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	ips := make([]net.IP, 0, len(opts.IPAddresses))
+	for _, s := range opts.IPAddresses {
+		if ip := net.ParseIP(s); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{opts.Organization}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(opts.ValidFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+		DNSNames:     opts.DNSNames,
+		IPAddresses:  ips,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+	}, nil
+}
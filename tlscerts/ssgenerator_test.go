@@ -0,0 +1,31 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package tlscerts
+
+import (
+	"crypto/x509"
+	"testing"
+)
+
+func TestGenerateSelfSignedWithOptionsUsesConfiguredSANs(t *testing.T) {
+	cert, err := GenerateSelfSignedWithOptions(Options{
+		DNSNames:    []string{"scan.example.com"},
+		IPAddresses: []string{"10.0.0.5"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateSelfSignedWithOptions failed: %v", err)
+	}
+
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse generated certificate: %v", err)
+	}
+
+	if len(parsed.DNSNames) != 1 || parsed.DNSNames[0] != "scan.example.com" {
+		t.Fatalf("expected DNSNames [scan.example.com], got %v", parsed.DNSNames)
+	}
+	if len(parsed.IPAddresses) != 1 || parsed.IPAddresses[0].String() != "10.0.0.5" {
+		t.Fatalf("expected IPAddresses [10.0.0.5], got %v", parsed.IPAddresses)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package types defines the open asset model types shared across the
+// engine: the assets and relations that plugins discover and the events
+// used to move work through the pipelines.
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// AssetType identifies the kind of an Asset.
+type AssetType string
+
+// The asset types the engine currently understands. The graph layer does
+// not check an asset's type against this list.
+const (
+	AssetFQDN         AssetType = "FQDN"
+	AssetIPAddress    AssetType = "IPAddress"
+	AssetASN          AssetType = "ASN"
+	AssetNetblock     AssetType = "Netblock"
+	AssetEmailAddress AssetType = "EmailAddress"
+	AssetPerson       AssetType = "Person"
+	AssetService      AssetType = "Service"
+	AssetOrganization AssetType = "Organization"
+	AssetPhone        AssetType = "Phone"
+)
+
+// Asset is a single discovered entity in the open asset model graph.
+type Asset interface {
+	// Key returns a value stable for a given asset identity, used for
+	// deduplication and as the graph node key.
+	Key() string
+	// AssetType reports which kind of asset this is.
+	AssetType() AssetType
+}
+
+// Relation connects two assets discovered during a session, e.g. an FQDN
+// resolving to an IPAddress.
+type Relation struct {
+	Type      string    `json:"type"`
+	FromAsset Asset     `json:"-"`
+	ToAsset   Asset     `json:"-"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FQDN is a fully-qualified domain name asset.
+type FQDN struct {
+	Name string `json:"name"`
+}
+
+// Key implements Asset.
+func (f FQDN) Key() string { return "FQDN:" + f.Name }
+
+// AssetType implements Asset.
+func (f FQDN) AssetType() AssetType { return AssetFQDN }
+
+// The canonical casing for IPAddress.Type. Every producer of an IPAddress
+// asset must use one of these instead of hand-writing the string, so
+// "ipv4"/"IPv4" don't split what should be the same asset.
+const (
+	IPTypeIPv4 = "IPv4"
+	IPTypeIPv6 = "IPv6"
+)
+
+// IPAddress is an IPv4 or IPv6 address asset.
+type IPAddress struct {
+	Address string `json:"address"`
+	Type    string `json:"type"` // IPTypeIPv4 or IPTypeIPv6
+}
+
+// Key implements Asset. It's derived from Address alone: Address is already
+// canonicalized by NewIPAddress, and Type is a description of that address
+// rather than part of its identity, so two IPAddress values built with
+// differently-cased Type strings still key identically.
+func (ip IPAddress) Key() string { return "IPAddress:" + ip.Address }
+
+// AssetType implements Asset.
+func (ip IPAddress) AssetType() AssetType { return AssetIPAddress }
+
+// Service is a network service discovered on a specific address and
+// port, e.g. by port scanning or TLS grabbing. Banner carries whatever
+// raw banner or handshake data was captured identifying it; it's the
+// minimal schema those scanning plugins need for a consistent sink, and
+// is expected to grow (TLS certificate details, detected product/version)
+// as those plugins land.
+type Service struct {
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"` // e.g. "tcp" or "udp"
+	Banner   string `json:"banner,omitempty"`
+}
+
+// Key implements Asset. Banner is deliberately excluded: two captures of
+// the same address/port/protocol are the same service even if a re-scan's
+// banner text drifted (a version bump, a restart), so a later capture
+// should update the existing asset rather than create a duplicate.
+func (s Service) Key() string {
+	return fmt.Sprintf("Service:%s:%d/%s", s.Address, s.Port, s.Protocol)
+}
+
+// AssetType implements Asset.
+func (s Service) AssetType() AssetType { return AssetService }
+
+// ASN is an autonomous system number asset.
+type ASN struct {
+	Number      int    `json:"number"`
+	Description string `json:"description,omitempty"`
+}
+
+// Key implements Asset.
+func (a ASN) Key() string { return fmt.Sprintf("ASN:%d", a.Number) }
+
+// AssetType implements Asset.
+func (a ASN) AssetType() AssetType { return AssetASN }
@@ -0,0 +1,20 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package contact defines assets representing ways of reaching an entity
+// discovered during a session.
+package contact
+
+import "github.com/owasp-amass/engine/types"
+
+// EmailAddress is an email address discovered as WHOIS/RDAP registrant
+// contact info, or mined from a page or breach-data source.
+type EmailAddress struct {
+	Address string `json:"address"`
+}
+
+// Key implements types.Asset.
+func (e EmailAddress) Key() string { return "EmailAddress:" + e.Address }
+
+// AssetType implements types.Asset.
+func (e EmailAddress) AssetType() types.AssetType { return types.AssetEmailAddress }
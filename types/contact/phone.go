@@ -0,0 +1,18 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package contact
+
+import "github.com/owasp-amass/engine/types"
+
+// Phone is a phone number discovered as WHOIS/RDAP registrant, admin, or
+// abuse contact info.
+type Phone struct {
+	Number string `json:"number"`
+}
+
+// Key implements types.Asset.
+func (p Phone) Key() string { return "Phone:" + p.Number }
+
+// AssetType implements types.Asset.
+func (p Phone) AssetType() types.AssetType { return types.AssetPhone }
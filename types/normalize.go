@@ -0,0 +1,34 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package types
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// NewIPAddress parses s and returns the canonical IPAddress asset for it.
+// netip.Addr already renders a canonical string for most forms, but inputs
+// built upstream from different sources can still disagree on casing or on
+// whether an IPv4 address is expressed as bare IPv4 or as an IPv4-in-IPv6
+// mapped address; both must collapse to the same asset key so the same
+// host never yields two assets in the graph.
+func NewIPAddress(s string) (IPAddress, bool) {
+	addr, err := netip.ParseAddr(strings.ToLower(strings.TrimSpace(s)))
+	if err != nil {
+		return IPAddress{}, false
+	}
+
+	// Flatten 4-in-6 mapped addresses (::ffff:1.2.3.4) down to plain IPv4
+	// so they match assets created directly from the IPv4 form.
+	if addr.Is4In6() {
+		addr = addr.Unmap()
+	}
+
+	typ := IPTypeIPv6
+	if addr.Is4() {
+		typ = IPTypeIPv4
+	}
+	return IPAddress{Address: addr.String(), Type: typ}, true
+}
@@ -0,0 +1,54 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package types
+
+import "testing"
+
+func TestNewIPAddressCanonicalizesEquivalentForms(t *testing.T) {
+	cases := []string{
+		"1.2.3.4",
+		"::ffff:1.2.3.4",
+		"::FFFF:1.2.3.4",
+	}
+
+	var keys []string
+	for _, c := range cases {
+		ip, ok := NewIPAddress(c)
+		if !ok {
+			t.Fatalf("failed to parse %q", c)
+		}
+		keys = append(keys, ip.Key())
+	}
+	for _, k := range keys[1:] {
+		if k != keys[0] {
+			t.Fatalf("expected all equivalent forms to canonicalize to %q, got %q", keys[0], k)
+		}
+	}
+}
+
+func TestNewIPAddressNormalizesMixedCaseIPv6(t *testing.T) {
+	a, ok := NewIPAddress("2001:DB8::1")
+	if !ok {
+		t.Fatal("failed to parse mixed-case IPv6 address")
+	}
+	b, ok := NewIPAddress("2001:db8::1")
+	if !ok {
+		t.Fatal("failed to parse lowercase IPv6 address")
+	}
+	if a.Key() != b.Key() {
+		t.Fatalf("expected mixed-case and lowercase IPv6 to match: %q vs %q", a.Key(), b.Key())
+	}
+}
+
+func TestIPAddressKeyIgnoresTypeCasing(t *testing.T) {
+	canonical, _ := NewIPAddress("198.51.100.7")
+
+	lowercased := IPAddress{Address: canonical.Address, Type: "ipv4"}
+	if canonical.Key() != lowercased.Key() {
+		t.Fatalf("expected IP assets differing only in Type casing to share a key: %q vs %q", canonical.Key(), lowercased.Key())
+	}
+	if canonical.Type != IPTypeIPv4 {
+		t.Fatalf("expected NewIPAddress to produce the canonical %q casing, got %q", IPTypeIPv4, canonical.Type)
+	}
+}
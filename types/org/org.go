@@ -0,0 +1,22 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package org defines the Organization asset, split out from the base types
+// package like the engine's other real-world-entity assets (contact,
+// people) so plugins that only care about infrastructure assets don't need
+// to import them.
+package org
+
+import "github.com/owasp-amass/engine/types"
+
+// Organization is a company or other legal entity discovered as the
+// registrant of a domain, netblock, or certificate.
+type Organization struct {
+	Name string `json:"name"`
+}
+
+// Key implements types.Asset.
+func (o Organization) Key() string { return "Organization:" + o.Name }
+
+// AssetType implements types.Asset.
+func (o Organization) AssetType() types.AssetType { return types.AssetOrganization }
@@ -0,0 +1,20 @@
+// Copyright © by Jeff Foley 2017-2024. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package people defines the Person asset, discovered via WHOIS/RDAP
+// registrant records or sources like breach data and contact scraping.
+package people
+
+import "github.com/owasp-amass/engine/types"
+
+// Person is a named individual associated with a discovered domain or
+// organization.
+type Person struct {
+	FullName string `json:"full_name"`
+}
+
+// Key implements types.Asset.
+func (p Person) Key() string { return "Person:" + p.FullName }
+
+// AssetType implements types.Asset.
+func (p Person) AssetType() types.AssetType { return types.AssetPerson }